@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/gerrit-ai-review/gerrit-tools/internal/cli"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
@@ -17,8 +18,8 @@ var (
 )
 
 func main() {
-	// Check if we're being called with subcommands
-	if len(os.Args) > 1 && os.Args[1] == "serve" {
+	// Check if we're being called with subcommands (serve, deadletter, ...)
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
 		if err := cli.ExecuteReviewer(Version); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
@@ -35,7 +36,7 @@ func runOneShot() {
 	changeNum := flag.Int("change-number", 0, "Change number (required)")
 	patchsetNum := flag.Int("patchset-number", 0, "Patchset number (required)")
 	skipPermissions := flag.Bool("dangerously-skip-permissions", false, "Bypass permission/sandbox checks in the selected review CLI (unsafe)")
-	reviewCLI := flag.String("review-cli", "", "AI CLI backend: claude or codex")
+	reviewCLI := flag.String("review-cli", "", "AI CLI backend: claude, codex or command")
 	version := flag.Bool("version", false, "Show version")
 
 	flag.Parse()
@@ -52,6 +53,10 @@ func runOneShot() {
 		fmt.Fprintf(os.Stderr, "    gerrit-reviewer --project <project> --change-number <num> --patchset-number <num>\n\n")
 		fmt.Fprintf(os.Stderr, "  Serve mode:\n")
 		fmt.Fprintf(os.Stderr, "    gerrit-reviewer serve\n\n")
+		fmt.Fprintf(os.Stderr, "  Failed review tasks:\n")
+		fmt.Fprintf(os.Stderr, "    gerrit-reviewer deadletter list|show|requeue\n\n")
+		fmt.Fprintf(os.Stderr, "  Review prompt of a change:\n")
+		fmt.Fprintf(os.Stderr, "    gerrit-reviewer skill render <change-number> [patchset]\n\n")
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -80,9 +85,10 @@ func runOneShot() {
 		os.Exit(1)
 	}
 
+	ctx := context.Background()
+
 	rev := reviewer.NewReviewer(cfg)
 
-	ctx := context.Background()
 	req := reviewer.ReviewRequest{
 		Project:        *project,
 		ChangeNumber:   *changeNum,
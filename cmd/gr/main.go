@@ -15,6 +15,6 @@ var (
 func main() {
 	if err := cli.ExecuteGerritCLI(Version); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }
@@ -0,0 +1,33 @@
+// Package backoff computes retry delays shared by the Gerrit client and the
+// worker pool.
+package backoff
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Exponential returns the delay before retrying after the given number of
+// failed attempts: base doubled per attempt, capped at max (no cap when
+// max <= 0), with the upper half randomized so that callers failing
+// together do not retry together.
+func Exponential(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && (max <= 0 || delay < max); i++ {
+		if delay > math.MaxInt64/2 {
+			delay = math.MaxInt64 // doubling would overflow
+			break
+		}
+		delay *= 2
+	}
+	if max > 0 && delay > max {
+		delay = max
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
@@ -0,0 +1,40 @@
+package backoff
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 4 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			got := Exponential(tt.attempt, time.Second, 4*time.Second)
+			if got < tt.want/2 || got > tt.want {
+				t.Fatalf("Exponential(%d) = %v, want between %v and %v", tt.attempt, got, tt.want/2, tt.want)
+			}
+		}
+	}
+
+	if got := Exponential(3, 0, time.Minute); got != 0 {
+		t.Errorf("Expected no delay without a base, got %v", got)
+	}
+
+	// max 0 leaves the delay uncapped
+	if got := Exponential(3, time.Second, 0); got < 2*time.Second || got > 4*time.Second {
+		t.Errorf("Expected an uncapped delay between 2s and 4s, got %v", got)
+	}
+	if got := Exponential(200, time.Second, 0); got < math.MaxInt64/2 {
+		t.Errorf("Expected a saturated delay instead of an overflow, got %v", got)
+	}
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/spf13/cobra"
+)
+
+// defaultAttentionReason is the reason shown for an account added to the
+// attention set without --reason or --attention-reason
+const defaultAttentionReason = "Your input is needed on this change"
+
+// attentionCmd represents the attention command group
+var attentionCmd = &cobra.Command{
+	Use:   "attention",
+	Short: "Manage the attention set of changes",
+	Long: `Add accounts to, or remove them from, the attention set of Gerrit changes.
+
+The attention set holds the accounts expected to act on a change next. Gerrit
+shows the reason next to each account. Accounts can be given by account ID,
+email or username.`,
+}
+
+// attentionAddCmd adds an account to the attention set
+var attentionAddCmd = &cobra.Command{
+	Use:   "add <change-id> <account>",
+	Short: "Add an account to the attention set of a change",
+	Long: `Add an account to the attention set of a change.
+
+Examples:
+  gerrit-cli attention add 12345 storage-owner@example.com --reason "Please check the locking in store.go"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runAttentionAdd,
+}
+
+// attentionRemoveCmd removes an account from the attention set
+var attentionRemoveCmd = &cobra.Command{
+	Use:   "remove <change-id> <account>",
+	Short: "Remove an account from the attention set of a change",
+	Long: `Remove an account from the attention set of a change.
+
+Examples:
+  gerrit-cli attention remove 12345 jdoe --reason "Question answered in the comments"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runAttentionRemove,
+}
+
+func init() {
+	attentionAddCmd.Flags().String("reason", defaultAttentionReason, "Reason shown in Gerrit")
+	attentionRemoveCmd.Flags().String("reason", "No action needed", "Reason shown in Gerrit")
+
+	// Add subcommands to attentionCmd
+	attentionCmd.AddCommand(attentionAddCmd)
+	attentionCmd.AddCommand(attentionRemoveCmd)
+}
+
+// runAttentionAdd executes the attention add command
+func runAttentionAdd(cmd *cobra.Command, args []string) error {
+	reason, _ := cmd.Flags().GetString("reason")
+
+	return runChangeAction("attention add", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		account, err := client.ResolveAccount(ctx, args[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve account %s: %w", args[1], err)
+		}
+
+		if _, err := client.AddToAttentionSet(ctx, changeID, fmt.Sprint(account.AccountID), reason); err != nil {
+			return nil, fmt.Errorf("failed to add to attention set: %w", err)
+		}
+
+		return map[string]interface{}{
+			"change":  changeID,
+			"account": account,
+			"reason":  reason,
+		}, nil
+	})
+}
+
+// runAttentionRemove executes the attention remove command
+func runAttentionRemove(cmd *cobra.Command, args []string) error {
+	reason, _ := cmd.Flags().GetString("reason")
+
+	return runChangeAction("attention remove", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		account, err := client.ResolveAccount(ctx, args[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve account %s: %w", args[1], err)
+		}
+
+		if err := client.RemoveFromAttentionSet(ctx, changeID, fmt.Sprint(account.AccountID), reason); err != nil {
+			return nil, fmt.Errorf("failed to remove from attention set: %w", err)
+		}
+
+		return map[string]interface{}{
+			"change":  changeID,
+			"account": account,
+			"reason":  reason,
+		}, nil
+	})
+}
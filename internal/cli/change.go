@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
 	"github.com/spf13/cobra"
@@ -14,11 +13,12 @@ import (
 var changeCmd = &cobra.Command{
 	Use:   "change",
 	Short: "Manage Gerrit changes",
-	Long: `Query and retrieve information about Gerrit changes.
+	Long: `Query, retrieve and act on Gerrit changes.
 
 Changes represent code reviews in Gerrit. Use this command group to
-list changes matching a query or get detailed information about a
-specific change.`,
+list changes matching a query, get detailed information about a
+specific change, or abandon, restore, rebase, submit, revert and change
+the work-in-progress and private state of a change.`,
 }
 
 // changeListCmd lists changes matching a query
@@ -40,6 +40,14 @@ Examples:
   # Combine multiple criteria
   gerrit-cli change list "status:open project:myproject branch:main"
 
+  # Stream every matching change as one JSON object per line
+  gerrit-cli change list "status:merged project:myproject" --all --format ndjson | jq -r ._number
+
+Results are fetched in pages of --page-size changes until --limit changes
+were returned, or every matching change with --all. With --format ndjson
+each change is printed as soon as its page arrives, without the response
+envelope; a failure is reported on stderr.
+
 Query Operators:
   status:open/merged/abandoned
   project:<project-name>
@@ -81,7 +89,9 @@ Examples:
 
 func init() {
 	// Add flags for changeListCmd
-	changeListCmd.Flags().IntP("limit", "n", 25, "Maximum number of results")
+	changeListCmd.Flags().IntP("limit", "n", 25, "Maximum number of results (0 = all)")
+	changeListCmd.Flags().Bool("all", false, "Return every matching change, ignoring --limit")
+	changeListCmd.Flags().Int("page-size", 100, "Number of changes fetched per request")
 	changeListCmd.Flags().StringSliceP("options", "o", []string{"LABELS"}, "Additional options (e.g., CURRENT_REVISION, DETAILED_ACCOUNTS)")
 
 	// Add flags for changeGetCmd
@@ -96,26 +106,47 @@ func init() {
 func runChangeList(cmd *cobra.Command, args []string) error {
 	query := args[0]
 	limit, _ := cmd.Flags().GetInt("limit")
+	all, _ := cmd.Flags().GetBool("all")
+	pageSize, _ := cmd.Flags().GetInt("page-size")
 	options, _ := cmd.Flags().GetStringSlice("options")
 	format := viper.GetString("output.format")
 
+	if limit < 0 || pageSize < 0 {
+		return reportError(format, ErrCodeInvalidInput, fmt.Errorf("--limit and --page-size must not be negative"))
+	}
+	if all {
+		limit = 0
+	}
+
 	// Get Gerrit configuration
 	httpURL := viper.GetString("gerrit.http_url")
 	httpUser := viper.GetString("gerrit.http_user")
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
 	}
 
 	// Create Gerrit client
-	client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+	client := newGerritClient()
+
+	// Stream changes page by page
+	if format == "ndjson" {
+		return StreamCommand("change list", version, func(emit func(interface{}) error) error {
+			return client.ForEachChange(context.Background(), query, options, pageSize, limit, func(change gerrit.ChangeInfo) error {
+				return emit(change)
+			})
+		})
+	}
 
 	// Execute command with standard formatting
 	return ExecuteCommand(format, "change list", version, func() (interface{}, error) {
 		ctx := context.Background()
-		changes, err := client.ListChanges(ctx, query, options, limit)
+		changes := []gerrit.ChangeInfo{}
+		err := client.ForEachChange(ctx, query, options, pageSize, limit, func(change gerrit.ChangeInfo) error {
+			changes = append(changes, change)
+			return nil
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -136,12 +167,11 @@ func runChangeGet(cmd *cobra.Command, args []string) error {
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
 	}
 
 	// Create Gerrit client
-	client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+	client := newGerritClient()
 
 	// Execute command with standard formatting
 	return ExecuteCommand(format, "change get", version, func() (interface{}, error) {
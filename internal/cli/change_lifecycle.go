@@ -0,0 +1,337 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// changeAbandonCmd abandons a change
+var changeAbandonCmd = &cobra.Command{
+	Use:   "abandon <change-id>",
+	Short: "Abandon a change",
+	Long: `Abandon a change, optionally explaining why.
+
+Examples:
+  gerrit-cli change abandon 12345 --message "Superseded by 12400"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangeAbandon,
+}
+
+// changeRestoreCmd restores an abandoned change
+var changeRestoreCmd = &cobra.Command{
+	Use:   "restore <change-id>",
+	Short: "Restore an abandoned change",
+	Long: `Restore an abandoned change, optionally explaining why.
+
+Examples:
+  gerrit-cli change restore 12345 --message "Still needed for 2.0"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangeRestore,
+}
+
+// changeRebaseCmd rebases a change
+var changeRebaseCmd = &cobra.Command{
+	Use:   "rebase <change-id>",
+	Short: "Rebase the current patchset of a change",
+	Long: `Rebase the current patchset of a change, creating a new patchset.
+
+Without --base the change is rebased onto the tip of its target branch, or
+onto the current patchset of the change it depends on. The base can be a
+change number, a change number with patchset (12300~2) or a commit SHA.
+
+Examples:
+  # Rebase onto the branch tip
+  gerrit-cli change rebase 12345
+
+  # Rebase onto another change
+  gerrit-cli change rebase 12345 --base 12300`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangeRebase,
+}
+
+// changeSubmitCmd submits a change
+var changeSubmitCmd = &cobra.Command{
+	Use:   "submit <change-id>",
+	Short: "Submit a change",
+	Long: `Submit a change for merging.
+
+With --dry-run nothing is submitted: the command reports whether the change
+is submittable and the status of each submit requirement.
+
+Examples:
+  # Check what blocks the submit
+  gerrit-cli change submit 12345 --dry-run
+
+  # Submit
+  gerrit-cli change submit 12345`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangeSubmit,
+}
+
+// changeRevertCmd reverts a merged change
+var changeRevertCmd = &cobra.Command{
+	Use:   "revert <change-id>",
+	Short: "Create a change reverting a merged change",
+	Long: `Create a new change reverting a merged change. The data of the
+response is the revert change.
+
+Examples:
+  gerrit-cli change revert 12345 --message "Revert \"Add cache\"
+
+Breaks the nightly build."`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangeRevert,
+}
+
+// changeWIPCmd marks a change as work in progress
+var changeWIPCmd = &cobra.Command{
+	Use:   "wip <change-id>",
+	Short: "Mark a change as work in progress",
+	Long: `Mark a change as work in progress. Reviewers are not notified of
+updates until the change is marked ready for review.
+
+Examples:
+  gerrit-cli change wip 12345 --message "Waiting for the API freeze"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangeWIP,
+}
+
+// changeReadyCmd marks a change as ready for review
+var changeReadyCmd = &cobra.Command{
+	Use:   "ready <change-id>",
+	Short: "Mark a work-in-progress change as ready for review",
+	Long: `Mark a work-in-progress change as ready for review.
+
+Examples:
+  gerrit-cli change ready 12345`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangeReady,
+}
+
+// changePrivateCmd marks a change as private or public
+var changePrivateCmd = &cobra.Command{
+	Use:   "private <change-id>",
+	Short: "Mark a change as private, or public with --unset",
+	Long: `Mark a change as private, visible only to its owner and reviewers,
+or make it public again with --unset.
+
+Examples:
+  gerrit-cli change private 12345
+  gerrit-cli change private 12345 --unset`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangePrivate,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{changeAbandonCmd, changeRestoreCmd, changeRevertCmd, changeWIPCmd, changeReadyCmd, changePrivateCmd} {
+		cmd.Flags().StringP("message", "m", "", "Message posted on the change")
+	}
+	changeRebaseCmd.Flags().String("base", "", "Change, change~patchset or commit to rebase onto (default: branch tip or parent change)")
+	changeSubmitCmd.Flags().Bool("dry-run", false, "Report submit requirements without submitting")
+	changePrivateCmd.Flags().Bool("unset", false, "Make the change public")
+
+	changeCmd.AddCommand(changeAbandonCmd)
+	changeCmd.AddCommand(changeRestoreCmd)
+	changeCmd.AddCommand(changeRebaseCmd)
+	changeCmd.AddCommand(changeSubmitCmd)
+	changeCmd.AddCommand(changeRevertCmd)
+	changeCmd.AddCommand(changeWIPCmd)
+	changeCmd.AddCommand(changeReadyCmd)
+	changeCmd.AddCommand(changePrivateCmd)
+}
+
+// refuseForAgent fails command when gerrit-cli runs on behalf of
+// gerrit-reviewer (config.AgentEnvVar is set): the AI reviewer must never
+// submit a change or change its state
+func refuseForAgent(command string) error {
+	if os.Getenv(config.AgentEnvVar) == "" {
+		return nil
+	}
+	err := fmt.Errorf("%s is not allowed when running under gerrit-reviewer", command)
+	return reportError(viper.GetString("output.format"), ErrCodePermission, err)
+}
+
+// runChangeAction checks the Gerrit configuration and runs fn on the change
+// of args with standard formatting
+func runChangeAction(command string, args []string, fn func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error)) error {
+	changeID := args[0]
+	format := viper.GetString("output.format")
+
+	// Get Gerrit configuration
+	httpURL := viper.GetString("gerrit.http_url")
+	httpUser := viper.GetString("gerrit.http_user")
+	httpPassword := viper.GetString("gerrit.http_password")
+
+	if httpURL == "" || httpUser == "" || httpPassword == "" {
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
+	}
+
+	// Create Gerrit client
+	client := newGerritClient()
+
+	// Execute command with standard formatting
+	return ExecuteCommand(format, command, version, func() (interface{}, error) {
+		return fn(context.Background(), client, changeID)
+	})
+}
+
+// runChangeAbandon executes the change abandon command
+func runChangeAbandon(cmd *cobra.Command, args []string) error {
+	if err := refuseForAgent("change abandon"); err != nil {
+		return err
+	}
+
+	message, _ := cmd.Flags().GetString("message")
+
+	return runChangeAction("change abandon", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		change, err := client.Abandon(ctx, changeID, message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to abandon change: %w", err)
+		}
+		return change, nil
+	})
+}
+
+// runChangeRestore executes the change restore command
+func runChangeRestore(cmd *cobra.Command, args []string) error {
+	if err := refuseForAgent("change restore"); err != nil {
+		return err
+	}
+
+	message, _ := cmd.Flags().GetString("message")
+
+	return runChangeAction("change restore", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		change, err := client.Restore(ctx, changeID, message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore change: %w", err)
+		}
+		return change, nil
+	})
+}
+
+// runChangeRebase executes the change rebase command
+func runChangeRebase(cmd *cobra.Command, args []string) error {
+	if err := refuseForAgent("change rebase"); err != nil {
+		return err
+	}
+
+	base, _ := cmd.Flags().GetString("base")
+
+	return runChangeAction("change rebase", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		change, err := client.Rebase(ctx, changeID, base)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebase change: %w", err)
+		}
+		return change, nil
+	})
+}
+
+// runChangeSubmit executes the change submit command
+func runChangeSubmit(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if !dryRun {
+		if err := refuseForAgent("change submit"); err != nil {
+			return err
+		}
+	}
+
+	return runChangeAction("change submit", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		if !dryRun {
+			change, err := client.Submit(ctx, changeID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to submit change: %w", err)
+			}
+			return change, nil
+		}
+
+		change, err := client.GetChangeDetail(ctx, changeID, []string{"SUBMITTABLE", "SUBMIT_REQUIREMENTS"})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get submit requirements: %w", err)
+		}
+
+		requirements := change.SubmitRequirements
+		if requirements == nil {
+			requirements = []gerrit.SubmitRequirementResult{}
+		}
+		return map[string]interface{}{
+			"change":              change.Number,
+			"status":              change.Status,
+			"dry_run":             true,
+			"submittable":         change.Submittable,
+			"submit_requirements": requirements,
+		}, nil
+	})
+}
+
+// runChangeRevert executes the change revert command
+func runChangeRevert(cmd *cobra.Command, args []string) error {
+	if err := refuseForAgent("change revert"); err != nil {
+		return err
+	}
+
+	message, _ := cmd.Flags().GetString("message")
+
+	return runChangeAction("change revert", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		change, err := client.Revert(ctx, changeID, message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to revert change: %w", err)
+		}
+		return change, nil
+	})
+}
+
+// runChangeWIP executes the change wip command
+func runChangeWIP(cmd *cobra.Command, args []string) error {
+	message, _ := cmd.Flags().GetString("message")
+
+	return runChangeAction("change wip", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		if err := client.SetWorkInProgress(ctx, changeID, message); err != nil {
+			return nil, fmt.Errorf("failed to mark change as work in progress: %w", err)
+		}
+		return map[string]interface{}{
+			"change":           changeID,
+			"work_in_progress": true,
+		}, nil
+	})
+}
+
+// runChangeReady executes the change ready command
+func runChangeReady(cmd *cobra.Command, args []string) error {
+	message, _ := cmd.Flags().GetString("message")
+
+	return runChangeAction("change ready", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		if err := client.SetReadyForReview(ctx, changeID, message); err != nil {
+			return nil, fmt.Errorf("failed to mark change as ready for review: %w", err)
+		}
+		return map[string]interface{}{
+			"change":           changeID,
+			"work_in_progress": false,
+		}, nil
+	})
+}
+
+// runChangePrivate executes the change private command
+func runChangePrivate(cmd *cobra.Command, args []string) error {
+	if err := refuseForAgent("change private"); err != nil {
+		return err
+	}
+
+	message, _ := cmd.Flags().GetString("message")
+	unset, _ := cmd.Flags().GetBool("unset")
+
+	return runChangeAction("change private", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		if err := client.SetPrivate(ctx, changeID, !unset, message); err != nil {
+			return nil, fmt.Errorf("failed to set private state: %w", err)
+		}
+		return map[string]interface{}{
+			"change":     changeID,
+			"is_private": !unset,
+		}, nil
+	})
+}
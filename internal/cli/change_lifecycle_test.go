@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/spf13/viper"
+)
+
+func TestLifecycleCommandsRefusedForAgent(t *testing.T) {
+	viper.Reset()
+	t.Setenv(config.AgentEnvVar, "1")
+
+	runs := map[string]func() error{
+		"abandon": func() error { return runChangeAbandon(changeAbandonCmd, []string{"12345"}) },
+		"restore": func() error { return runChangeRestore(changeRestoreCmd, []string{"12345"}) },
+		"rebase":  func() error { return runChangeRebase(changeRebaseCmd, []string{"12345"}) },
+		"submit":  func() error { return runChangeSubmit(changeSubmitCmd, []string{"12345"}) },
+		"revert":  func() error { return runChangeRevert(changeRevertCmd, []string{"12345"}) },
+		"private": func() error { return runChangePrivate(changePrivateCmd, []string{"12345"}) },
+	}
+	for name, run := range runs {
+		var cmdErr *CommandError
+		if err := run(); !errors.As(err, &cmdErr) || cmdErr.Code != ErrCodePermission {
+			t.Errorf("%s: expected a %s error, got %v", name, ErrCodePermission, err)
+		}
+	}
+
+	// A dry run only reads the change; without Gerrit configured it fails
+	// on the configuration instead
+	if err := changeSubmitCmd.Flags().Set("dry-run", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer changeSubmitCmd.Flags().Set("dry-run", "false")
+
+	var cmdErr *CommandError
+	if err := runChangeSubmit(changeSubmitCmd, []string{"12345"}); !errors.As(err, &cmdErr) || cmdErr.Code != ErrCodeConfig {
+		t.Errorf("Expected a dry run to pass the guard, got %v", err)
+	}
+}
@@ -2,8 +2,6 @@ package cli
 
 import (
 	"context"
-	"fmt"
-	"os"
 	"sort"
 
 	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
@@ -102,12 +100,11 @@ func runCommentList(cmd *cobra.Command, args []string) error {
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
 	}
 
 	// Create Gerrit client
-	client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+	client := newGerritClient()
 
 	// Execute command with standard formatting
 	return ExecuteCommand(format, "comment list", version, func() (interface{}, error) {
@@ -194,12 +191,11 @@ func runCommentThreads(cmd *cobra.Command, args []string) error {
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
 	}
 
 	// Create Gerrit client
-	client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+	client := newGerritClient()
 
 	// Execute command with standard formatting
 	return ExecuteCommand(format, "comment threads", version, func() (interface{}, error) {
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/queue"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// deadletterCmd represents the deadletter command group
+var deadletterCmd = &cobra.Command{
+	Use:     "deadletter",
+	Aliases: []string{"dlq"},
+	Short:   "Inspect and requeue review tasks that failed every attempt",
+	Long: `Serve mode retries failed reviews according to serve.retry. Tasks that
+still fail after their last attempt are dead-lettered under
+<repo_base_path>/.queue/dead.
+
+Requeued tasks are picked up by a running "gerrit-reviewer serve" within a few
+seconds, or on its next start.`,
+}
+
+// deadletterListCmd lists dead-lettered tasks
+var deadletterListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List dead-lettered tasks",
+	Long: `List dead-lettered tasks, oldest failure first.
+
+Examples:
+  gerrit-reviewer deadletter list`,
+	Args: cobra.NoArgs,
+	RunE: runDeadletterList,
+}
+
+// deadletterShowCmd shows one dead-lettered task with its error history
+var deadletterShowCmd = &cobra.Command{
+	Use:   "show <task-id>",
+	Short: "Show a dead-lettered task and the error of every attempt",
+	Long: `Show a dead-lettered task and the error of every attempt.
+
+Examples:
+  gerrit-reviewer deadletter show platform/app-12345-3`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDeadletterShow,
+}
+
+// deadletterRequeueCmd hands dead-lettered tasks back to serve mode
+var deadletterRequeueCmd = &cobra.Command{
+	Use:   "requeue [task-id...]",
+	Short: "Requeue dead-lettered tasks",
+	Long: `Hand dead-lettered tasks back to serve mode with a fresh attempt count.
+
+Examples:
+  # Requeue one task
+  gerrit-reviewer deadletter requeue platform/app-12345-3
+
+  # Requeue everything
+  gerrit-reviewer deadletter requeue --all`,
+	RunE: runDeadletterRequeue,
+}
+
+func init() {
+	deadletterCmd.AddCommand(deadletterListCmd)
+	deadletterCmd.AddCommand(deadletterShowCmd)
+	deadletterCmd.AddCommand(deadletterRequeueCmd)
+
+	deadletterRequeueCmd.Flags().Bool("all", false, "Requeue every dead-lettered task")
+}
+
+func deadLetterStore() *queue.DeadLetterStore {
+	repoBasePath := viper.GetString("git.repo_base_path")
+	if repoBasePath == "" {
+		// Default to /tmp/ai-review-repos if not specified
+		repoBasePath = "/tmp/ai-review-repos"
+	}
+	return queue.NewDeadLetterStore(filepath.Join(repoBasePath, ".queue"))
+}
+
+// runDeadletterList executes the deadletter list command
+func runDeadletterList(cmd *cobra.Command, args []string) error {
+	format := viper.GetString("output.format")
+
+	return ExecuteCommand(format, "deadletter list", version, func() (interface{}, error) {
+		return deadLetterStore().List()
+	})
+}
+
+// runDeadletterShow executes the deadletter show command
+func runDeadletterShow(cmd *cobra.Command, args []string) error {
+	format := viper.GetString("output.format")
+
+	return ExecuteCommand(format, "deadletter show", version, func() (interface{}, error) {
+		return deadLetterStore().Get(args[0])
+	})
+}
+
+// runDeadletterRequeue executes the deadletter requeue command
+func runDeadletterRequeue(cmd *cobra.Command, args []string) error {
+	format := viper.GetString("output.format")
+	all, _ := cmd.Flags().GetBool("all")
+
+	if all == (len(args) > 0) {
+		return fmt.Errorf("specify task IDs or --all")
+	}
+
+	return ExecuteCommand(format, "deadletter requeue", version, func() (interface{}, error) {
+		store := deadLetterStore()
+
+		taskIDs := args
+		if all {
+			entries, err := store.List()
+			if err != nil {
+				return nil, err
+			}
+			taskIDs = make([]string, 0, len(entries))
+			for _, entry := range entries {
+				taskIDs = append(taskIDs, entry.Task.ID)
+			}
+		}
+
+		requeued := make([]queue.Task, 0, len(taskIDs))
+		for _, id := range taskIDs {
+			entry, err := store.Requeue(id)
+			if err != nil {
+				return nil, err
+			}
+			requeued = append(requeued, entry.Task)
+		}
+
+		return map[string]interface{}{
+			"requeued": requeued,
+		}, nil
+	})
+}
@@ -3,11 +3,10 @@ package cli
 import (
 	"context"
 	"fmt"
-	"os"
-	"strconv"
-	"strings"
 
 	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/policy"
+	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -29,6 +28,10 @@ var draftCreateCmd = &cobra.Command{
 	Short: "Create a new draft comment",
 	Long: `Create a new draft comment on a specific file and line.
 
+The line can also be a character range "startLine:startChar-endLine:endChar",
+or 0 for a comment on the whole file. --parent places the comment on the
+parent of the revision, where deleted lines are shown.
+
 The revision-id can be:
   - "current" (default) - the latest patchset
   - Numeric patchset number (e.g., 1, 2, 3)
@@ -51,6 +54,11 @@ Examples:
   # Create on specific patchset
   gerrit-cli draft create 10661 src/main.go 42 "[P1] 👎 Missing error handling" 3
 
+  # Comment on a character range, on the whole file, and on a deleted line
+  gerrit-cli draft create 10661 src/main.go 10:4-12:1 "[P2] 👎 This block can be simplified"
+  gerrit-cli draft create 10661 src/util.go 0 "[P2] 👎 Consider splitting this file"
+  gerrit-cli draft create 10661 src/main.go 30 "[P1] 👎 This check should be kept" --parent
+
   # Override auto-resolved (mark P3 as unresolved)
   gerrit-cli draft create 10661 src/main.go 50 "[P3] 👎 Minor issue" --unresolved`,
 	Args: cobra.RangeArgs(4, 5),
@@ -137,6 +145,7 @@ func init() {
 	draftCreateCmd.Flags().Bool("resolved", false, "Mark as resolved (override auto-detection)")
 	draftCreateCmd.Flags().Bool("unresolved", false, "Mark as unresolved (override auto-detection)")
 	draftCreateCmd.Flags().String("in-reply-to", "", "Reply to another comment ID")
+	draftCreateCmd.Flags().Bool("parent", false, "Comment on the parent of the revision (deleted lines)")
 
 	// Flags for draftListCmd
 	draftListCmd.Flags().StringP("file", "f", "", "Filter drafts for specific file")
@@ -155,27 +164,22 @@ func init() {
 
 // determineUnresolved determines if a comment should be unresolved based on priority prefix
 func determineUnresolved(message string) *bool {
-	// Check for P0 or P1 prefix (unresolved)
-	if strings.HasPrefix(message, "[P0]") || strings.HasPrefix(message, "[P1]") {
-		unresolved := true
-		return &unresolved
+	severity := policy.ParseSeverity(message)
+	if severity == policy.SeverityNone {
+		// No priority prefix, don't set unresolved field (let Gerrit use default)
+		return nil
 	}
 
-	// Check for P2 or P3 prefix (resolved)
-	if strings.HasPrefix(message, "[P2]") || strings.HasPrefix(message, "[P3]") {
-		unresolved := false
-		return &unresolved
-	}
-
-	// No priority prefix, don't set unresolved field (let Gerrit use default)
-	return nil
+	// P0 and P1 block the merge (unresolved), P2 and P3 are informational
+	unresolved := severity.Blocking()
+	return &unresolved
 }
 
 // runDraftCreate executes the draft create command
 func runDraftCreate(cmd *cobra.Command, args []string) error {
 	changeID := args[0]
 	filePath := args[1]
-	lineStr := args[2]
+	position := args[2]
 	message := args[3]
 
 	revisionID := "current"
@@ -183,17 +187,19 @@ func runDraftCreate(cmd *cobra.Command, args []string) error {
 		revisionID = args[4]
 	}
 
-	// Parse line number
-	line, err := strconv.Atoi(lineStr)
+	format := viper.GetString("output.format")
+
+	// Parse line number or range
+	line, rng, err := parseCommentPosition(position)
 	if err != nil {
-		return fmt.Errorf("invalid line number: %s", lineStr)
+		return reportError(format, ErrCodeInvalidInput, err)
 	}
 
 	// Get flags
 	resolvedFlag, _ := cmd.Flags().GetBool("resolved")
 	unresolvedFlag, _ := cmd.Flags().GetBool("unresolved")
 	inReplyTo, _ := cmd.Flags().GetString("in-reply-to")
-	format := viper.GetString("output.format")
+	parent, _ := cmd.Flags().GetBool("parent")
 
 	// Determine unresolved status
 	var unresolved *bool
@@ -214,24 +220,28 @@ func runDraftCreate(cmd *cobra.Command, args []string) error {
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
 	}
 
 	// Create Gerrit client
-	client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+	client := newGerritClient()
 
 	// Execute command with standard formatting
 	return ExecuteCommand(format, "draft create", version, func() (interface{}, error) {
 		ctx := context.Background()
 
 		// Build draft input
-		input := &gerrit.DraftInput{
-			Path:       filePath,
+		comment := types.Comment{
+			File:       filePath,
 			Line:       line,
+			Range:      rng,
 			Message:    message,
 			Unresolved: unresolved,
 		}
+		if parent {
+			comment.Side = types.SideParent
+		}
+		input := gerrit.NewDraftInput(comment)
 
 		if inReplyTo != "" {
 			input.InReplyTo = inReplyTo
@@ -265,12 +275,11 @@ func runDraftList(cmd *cobra.Command, args []string) error {
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
 	}
 
 	// Create Gerrit client
-	client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+	client := newGerritClient()
 
 	// Execute command with standard formatting
 	return ExecuteCommand(format, "draft list", version, func() (interface{}, error) {
@@ -333,12 +342,11 @@ func runDraftUpdate(cmd *cobra.Command, args []string) error {
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
 	}
 
 	// Create Gerrit client
-	client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+	client := newGerritClient()
 
 	// Execute command with standard formatting
 	return ExecuteCommand(format, "draft update", version, func() (interface{}, error) {
@@ -352,9 +360,12 @@ func runDraftUpdate(cmd *cobra.Command, args []string) error {
 
 		// Build updated draft input
 		input := &gerrit.DraftInput{
-			Path:    existingDraft.Path,
-			Line:    existingDraft.Line,
-			Message: message,
+			Path:      existingDraft.Path,
+			Line:      existingDraft.Line,
+			Range:     existingDraft.Range,
+			Side:      existingDraft.Side,
+			Message:   message,
+			InReplyTo: existingDraft.InReplyTo,
 		}
 
 		// Set unresolved status
@@ -397,12 +408,11 @@ func runDraftDelete(cmd *cobra.Command, args []string) error {
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
 	}
 
 	// Create Gerrit client
-	client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+	client := newGerritClient()
 
 	// Execute command with standard formatting
 	return ExecuteCommand(format, "draft delete", version, func() (interface{}, error) {
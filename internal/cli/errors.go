@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/git"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/policy"
+)
+
+// Error codes reported in ErrorInfo.Code. Each code exits gerrit-cli with
+// its own status, see ExitCode.
+const (
+	ErrCodeCommand      = "COMMAND_ERROR" // Any failure not covered below
+	ErrCodeInvalidInput = "INVALID_INPUT" // Bad arguments or flags
+	ErrCodeConfig       = "CONFIG_ERROR"  // Missing or invalid configuration
+	ErrCodeNotFound     = "NOT_FOUND"     // Change, revision or other resource does not exist
+	ErrCodeAuth         = "AUTH"          // Gerrit rejected the credentials
+	ErrCodePermission   = "PERMISSION"    // Authenticated but not allowed
+	ErrCodeConflict     = "CONFLICT"      // The change is in a state that prevents the operation
+	ErrCodeRateLimit    = "RATE_LIMIT"    // Gerrit throttled the requests
+	ErrCodeNetwork      = "NETWORK"       // Gerrit unreachable, timed out or unavailable
+	ErrCodeGitFailure   = "GIT_FAILURE"   // A git command failed
+)
+
+// errGerritHTTPConfig is reported when the REST API credentials are missing
+var errGerritHTTPConfig = errors.New("Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.")
+
+// exitCodes maps error codes to process exit codes
+var exitCodes = map[string]int{
+	ErrCodeCommand:      1,
+	ErrCodeInvalidInput: 2,
+	ErrCodeConfig:       3,
+	ErrCodeNotFound:     4,
+	ErrCodeAuth:         5,
+	ErrCodePermission:   6,
+	ErrCodeConflict:     7,
+	ErrCodeRateLimit:    8,
+	ErrCodeNetwork:      9,
+	ErrCodeGitFailure:   10,
+}
+
+// CommandError is an error with its gerrit-cli error code. Commands return
+// it once the error response has been printed.
+type CommandError struct {
+	Code string
+	Err  error
+}
+
+func (e *CommandError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// withCode marks err with an error code, overriding the code classifyError
+// would derive from it
+func withCode(code string, err error) error {
+	return &CommandError{Code: code, Err: err}
+}
+
+// reportError prints the error response for err to stderr and returns it
+// with its code. It is used for failures before ExecuteCommand runs.
+func reportError(format, code string, err error) error {
+	fmt.Fprintln(os.Stderr, FormatErrorResponse(format, err.Error(), code))
+	return withCode(code, err)
+}
+
+// ExitCode returns the process exit code for an error returned by a
+// gerrit-cli command: 0 for nil, 1 for errors without a code
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		if code, ok := exitCodes[cmdErr.Code]; ok {
+			return code
+		}
+	}
+	return 1
+}
+
+// classifyError returns the error code of err and details for ErrorInfo
+func classifyError(err error) (code, details string) {
+	details = errorDetails(err)
+
+	var cmdErr *CommandError
+	var gitErr *git.CommandError
+	var apiErr *gerrit.APIError
+	switch {
+	case errors.As(err, &cmdErr):
+		return cmdErr.Code, details
+	case errors.Is(err, gerrit.ErrUnauthorized):
+		return ErrCodeAuth, details
+	case errors.Is(err, gerrit.ErrForbidden), errors.Is(err, gerrit.ErrLabelNotPermitted):
+		return ErrCodePermission, details
+	case errors.Is(err, gerrit.ErrNotFound):
+		return ErrCodeNotFound, details
+	case errors.Is(err, gerrit.ErrConflict):
+		return ErrCodeConflict, details
+	case errors.Is(err, gerrit.ErrRateLimited):
+		return ErrCodeRateLimit, details
+	case errors.Is(err, policy.ErrVoteRejected), errors.Is(err, gerrit.ErrReviewerNotAdded):
+		return ErrCodeInvalidInput, details
+	case errors.As(err, &gitErr):
+		return ErrCodeGitFailure, details
+	case errors.As(err, &apiErr) && apiErr.StatusCode >= 502 && apiErr.StatusCode <= 504:
+		return ErrCodeNetwork, details
+	case isNetworkError(err):
+		return ErrCodeNetwork, details
+	default:
+		return ErrCodeCommand, details
+	}
+}
+
+// errorDetails returns what err carries besides its message: the status of
+// a Gerrit response, the output of a git command or the failed request
+func errorDetails(err error) string {
+	var apiErr *gerrit.APIError
+	var gitErr *git.CommandError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &apiErr):
+		return fmt.Sprintf("HTTP status %d", apiErr.StatusCode)
+	case errors.As(err, &gitErr):
+		return strings.TrimSpace(gitErr.Output)
+	case errors.As(err, &urlErr):
+		return fmt.Sprintf("%s %s", urlErr.Op, urlErr.URL)
+	default:
+		return ""
+	}
+}
+
+// isNetworkError reports whether err is a failure to reach Gerrit
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"testing"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/git"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/policy"
+)
+
+func TestClassifyError(t *testing.T) {
+	apiError := func(status int) error {
+		return fmt.Errorf("failed to get change details: %w", &gerrit.APIError{StatusCode: status, Body: "body"})
+	}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    string
+		wantDetails string
+	}{
+		{"not found", apiError(http.StatusNotFound), ErrCodeNotFound, "HTTP status 404"},
+		{"unauthorized", apiError(http.StatusUnauthorized), ErrCodeAuth, "HTTP status 401"},
+		{"forbidden", apiError(http.StatusForbidden), ErrCodePermission, "HTTP status 403"},
+		{"label not permitted", fmt.Errorf("%w: Verified +1 is not permitted", gerrit.ErrLabelNotPermitted), ErrCodePermission, ""},
+		{"conflict", apiError(http.StatusConflict), ErrCodeConflict, "HTTP status 409"},
+		{"rate limited", apiError(http.StatusTooManyRequests), ErrCodeRateLimit, "HTTP status 429"},
+		{"unavailable", apiError(http.StatusServiceUnavailable), ErrCodeNetwork, "HTTP status 503"},
+		{"server error", apiError(http.StatusInternalServerError), ErrCodeCommand, "HTTP status 500"},
+		{"network", &url.Error{Op: "Get", URL: "https://gerrit.example.com/a/changes/1", Err: errors.New("connection refused")},
+			ErrCodeNetwork, "Get https://gerrit.example.com/a/changes/1"},
+		{"timeout", fmt.Errorf("request failed: %w", context.DeadlineExceeded), ErrCodeNetwork, ""},
+		{"git", fmt.Errorf("failed to fetch patchset: %w", &git.CommandError{Op: "fetch patchset", Err: &exec.ExitError{}, Output: "fatal: couldn't find remote ref\n"}),
+			ErrCodeGitFailure, "fatal: couldn't find remote ref"},
+		{"vote rejected", fmt.Errorf("%w: P1 comment", policy.ErrVoteRejected), ErrCodeInvalidInput, ""},
+		{"reviewer not added", fmt.Errorf("failed to add nobody: %w: not a registered user", gerrit.ErrReviewerNotAdded), ErrCodeInvalidInput, ""},
+		{"explicit code", withCode(ErrCodeNotFound, errors.New("patchset 3 not found in change 1")), ErrCodeNotFound, ""},
+		{"explicit code wins", withCode(ErrCodeInvalidInput, apiError(http.StatusNotFound)), ErrCodeInvalidInput, "HTTP status 404"},
+		{"other", errors.New("boom"), ErrCodeCommand, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, details := classifyError(tt.err)
+			if code != tt.wantCode {
+				t.Errorf("code = %s, want %s", code, tt.wantCode)
+			}
+			if details != tt.wantDetails {
+				t.Errorf("details = %q, want %q", details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := ExitCode(nil); got != 0 {
+		t.Errorf("ExitCode(nil) = %d, want 0", got)
+	}
+	if got := ExitCode(errors.New("unknown flag")); got != 1 {
+		t.Errorf("ExitCode() of an error without code = %d, want 1", got)
+	}
+
+	seen := make(map[int]string)
+	for code := range exitCodes {
+		exit := ExitCode(withCode(code, errors.New("failed")))
+		if exit == 0 {
+			t.Errorf("%s exits with 0", code)
+		}
+		if other, ok := seen[exit]; ok {
+			t.Errorf("%s and %s share exit code %d", code, other, exit)
+		}
+		seen[exit] = code
+	}
+}
+
+func TestExecuteCommandErrorCode(t *testing.T) {
+	err := ExecuteCommand("json", "test", "dev", func() (interface{}, error) {
+		return nil, fmt.Errorf("failed to get change details: %w", &gerrit.APIError{StatusCode: http.StatusNotFound})
+	})
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Code != ErrCodeNotFound {
+		t.Fatalf("Expected a %s CommandError, got %v", ErrCodeNotFound, err)
+	}
+	if !errors.Is(err, gerrit.ErrNotFound) {
+		t.Errorf("Expected the cause to be kept, got %v", err)
+	}
+	if got := ExitCode(err); got != exitCodes[ErrCodeNotFound] {
+		t.Errorf("ExitCode() = %d, want %d", got, exitCodes[ErrCodeNotFound])
+	}
+
+	if err := ExecuteCommand("json", "test", "dev", func() (interface{}, error) { return "ok", nil }); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
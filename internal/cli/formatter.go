@@ -3,6 +3,7 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"time"
 )
@@ -109,6 +110,9 @@ func NewFormatter(format string, pretty bool) Formatter {
 		return &JSONFormatter{Pretty: pretty}
 	case "text":
 		return &TextFormatter{}
+	case "ndjson":
+		// One response per line
+		return &JSONFormatter{Pretty: false}
 	default:
 		// Default to JSON
 		return &JSONFormatter{Pretty: pretty}
@@ -116,7 +120,9 @@ func NewFormatter(format string, pretty bool) Formatter {
 }
 
 // ExecuteCommand is a helper function that wraps command execution with
-// standard response formatting and error handling
+// standard response formatting and error handling. A failure is reported
+// with the error code classifyError derives from it and returned as a
+// *CommandError, see ExitCode.
 func ExecuteCommand(format string, command string, version string, fn func() (interface{}, error)) error {
 	startTime := time.Now()
 
@@ -133,17 +139,20 @@ func ExecuteCommand(format string, command string, version string, fn func() (in
 	}
 
 	// Execute the command function
-	data, err := fn()
+	data, cmdErr := fn()
 
 	// Calculate duration
 	response.Metadata.DurationMs = time.Since(startTime).Milliseconds()
 
-	if err != nil {
+	if cmdErr != nil {
+		code, details := classifyError(cmdErr)
 		response.Success = false
 		response.Error = &ErrorInfo{
-			Message: err.Error(),
-			Code:    "COMMAND_ERROR",
+			Message: cmdErr.Error(),
+			Code:    code,
+			Details: details,
 		}
+		cmdErr = withCode(code, cmdErr)
 	} else {
 		response.Data = data
 	}
@@ -161,12 +170,60 @@ func ExecuteCommand(format string, command string, version string, fn func() (in
 
 	fmt.Println(output)
 
-	// Return error if command failed (for proper exit code)
-	if !response.Success {
-		return fmt.Errorf("command failed")
+	// Return the error with its code if command failed (for proper exit code)
+	return cmdErr
+}
+
+// StreamCommand is the NDJSON counterpart of ExecuteCommand for commands
+// returning many items: fn passes each item to emit, which writes it to
+// stdout as one JSON line right away. A failure is reported on stderr as a
+// one-line error response and returned as a *CommandError; the items
+// emitted before it stay on stdout.
+func StreamCommand(command string, version string, fn func(emit func(interface{}) error) error) error {
+	return streamCommand(os.Stdout, command, version, fn)
+}
+
+// streamCommand implements StreamCommand, writing the items to w
+func streamCommand(w io.Writer, command string, version string, fn func(emit func(interface{}) error) error) error {
+	startTime := time.Now()
+
+	// Log command execution start to stderr (captured by Bash tool)
+	fmt.Fprintf(os.Stderr, "[gerrit-cli] Executing: %s\n", command)
+
+	encoder := json.NewEncoder(w)
+	items := 0
+	err := fn(func(item interface{}) error {
+		items++
+		return encoder.Encode(item)
+	})
+
+	durationMs := time.Since(startTime).Milliseconds()
+	fmt.Fprintf(os.Stderr, "[gerrit-cli] %s completed in %dms (success=%v, items=%d)\n",
+		command, durationMs, err == nil, items)
+	if err == nil {
+		return nil
+	}
+
+	code, details := classifyError(err)
+	response := &Response{
+		Success: false,
+		Error: &ErrorInfo{
+			Message: err.Error(),
+			Code:    code,
+			Details: details,
+		},
+		Metadata: ResponseMetadata{
+			Timestamp:  startTime,
+			DurationMs: durationMs,
+			Command:    command,
+			Version:    version,
+		},
+	}
+	if output, ferr := NewFormatter("ndjson", false).Format(response); ferr == nil {
+		fmt.Fprintln(os.Stderr, output)
 	}
 
-	return nil
+	return withCode(code, err)
 }
 
 // FormatErrorResponse creates and formats an error response
@@ -1,9 +1,16 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
 )
 
 func TestJSONFormatter(t *testing.T) {
@@ -130,6 +137,11 @@ func TestNewFormatter(t *testing.T) {
 			format:   "text",
 			wantType: "*cli.TextFormatter",
 		},
+		{
+			name:     "ndjson formatter",
+			format:   "ndjson",
+			wantType: "*cli.JSONFormatter",
+		},
 		{
 			name:     "default to json",
 			format:   "unknown",
@@ -146,3 +158,25 @@ func TestNewFormatter(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamCommand(t *testing.T) {
+	var out bytes.Buffer
+	err := streamCommand(&out, "test", "dev", func(emit func(interface{}) error) error {
+		for i := 1; i <= 2; i++ {
+			if err := emit(map[string]int{"_number": i}); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("page 2: %w", &gerrit.APIError{StatusCode: http.StatusTooManyRequests})
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || lines[0] != `{"_number":1}` || lines[1] != `{"_number":2}` {
+		t.Errorf("Expected one JSON object per line, got %q", out.String())
+	}
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Code != ErrCodeRateLimit {
+		t.Errorf("Expected a %s CommandError, got %v", ErrCodeRateLimit, err)
+	}
+}
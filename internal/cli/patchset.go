@@ -79,12 +79,11 @@ func runPatchsetDiff(cmd *cobra.Command, args []string) error {
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
 	}
 
 	// Create Gerrit client
-	client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+	client := newGerritClient()
 
 	// Execute command with standard formatting
 	return ExecuteCommand(format, "patchset diff", version, func() (interface{}, error) {
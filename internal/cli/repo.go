@@ -2,11 +2,11 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strconv"
 
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/git"
 	"github.com/spf13/cobra"
@@ -51,7 +51,7 @@ Output:
   {
     "success": true,
     "data": {
-      "repo_path": "/tmp/ai-review-repos/myproject",
+      "repo_path": "/tmp/ai-review-repos/myproject.repo",
       "change_number": 12345,
       "patchset_number": 3,
       "project": "myproject",
@@ -63,9 +63,57 @@ Output:
 	RunE: runRepoCheckout,
 }
 
+// repoMigrateCmd relocates clones created by the legacy flat layout
+var repoMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Relocate legacy clones to the hierarchical layout",
+	Long: `Relocate clones created by older versions, which stored every project
+under <repo_base_path>/<basename>, to <repo_base_path>/<project>.repo.
+
+Each clone's origin URL decides which project it belongs to, so clones shared
+by projects with the same basename (e.g. platform/foo and tools/foo) are moved
+to the project they were actually cloned from.
+
+This runs automatically on "gerrit-reviewer serve" startup; use this command
+to run it explicitly, e.g. before "repo checkout" on an old repo_base_path.
+
+Examples:
+  gerrit-cli repo migrate`,
+	Args: cobra.NoArgs,
+	RunE: runRepoMigrate,
+}
+
 func init() {
 	// Add subcommands to repoCmd
 	repoCmd.AddCommand(repoCheckoutCmd)
+	repoCmd.AddCommand(repoMigrateCmd)
+}
+
+// runRepoMigrate executes the repo migrate command
+func runRepoMigrate(cmd *cobra.Command, args []string) error {
+	format := viper.GetString("output.format")
+
+	repoBasePath := viper.GetString("git.repo_base_path")
+	if repoBasePath == "" {
+		// Default to /tmp/ai-review-repos if not specified
+		repoBasePath = "/tmp/ai-review-repos"
+	}
+
+	return ExecuteCommand(format, "repo migrate", version, func() (interface{}, error) {
+		ctx := context.Background()
+
+		results, err := git.MigrateLegacyClones(ctx, repoBasePath, func(project string) string {
+			return config.ResolveRepoPath(repoBasePath, project)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate legacy clones: %w", err)
+		}
+
+		if results == nil {
+			results = []git.RelocatedClone{}
+		}
+		return results, nil
+	})
 }
 
 // CheckoutResult represents the result of a checkout operation
@@ -84,24 +132,23 @@ func runRepoCheckout(cmd *cobra.Command, args []string) error {
 	var patchsetNum int
 	var err error
 
+	format := viper.GetString("output.format")
+
 	// Parse patchset number if provided
 	if len(args) > 1 {
 		patchsetNum, err = strconv.Atoi(args[1])
 		if err != nil {
-			return fmt.Errorf("invalid patchset number: %s", args[1])
+			return reportError(format, ErrCodeInvalidInput, fmt.Errorf("invalid patchset number: %s", args[1]))
 		}
 	}
 
-	format := viper.GetString("output.format")
-
 	// Get Gerrit HTTP configuration
 	httpURL := viper.GetString("gerrit.http_url")
 	httpUser := viper.GetString("gerrit.http_user")
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
 	}
 
 	// Get Git configuration
@@ -109,8 +156,7 @@ func runRepoCheckout(cmd *cobra.Command, args []string) error {
 	repoBasePath := viper.GetString("git.repo_base_path")
 
 	if sshAlias == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Git SSH alias not found. Set GERRIT_SSH_ALIAS.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errors.New("Git SSH alias not found. Set GERRIT_SSH_ALIAS."))
 	}
 
 	if repoBasePath == "" {
@@ -123,7 +169,7 @@ func runRepoCheckout(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
 		// Create Gerrit client
-		client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+		client := newGerritClient()
 
 		// Fetch change details to get project and current revision
 		change, err := client.GetChangeDetail(ctx, changeID, []string{"CURRENT_REVISION", "ALL_REVISIONS"})
@@ -145,16 +191,16 @@ func runRepoCheckout(cmd *cobra.Command, args []string) error {
 				}
 			}
 			if revision == nil {
-				return nil, fmt.Errorf("patchset %d not found in change %s", patchsetNum, changeID)
+				return nil, withCode(ErrCodeNotFound, fmt.Errorf("patchset %d not found in change %s", patchsetNum, changeID))
 			}
 		} else {
 			// Use current revision
 			if change.CurrentRevision == "" {
-				return nil, fmt.Errorf("no current revision found for change %s", changeID)
+				return nil, withCode(ErrCodeNotFound, fmt.Errorf("no current revision found for change %s", changeID))
 			}
 			revision = change.Revisions[change.CurrentRevision]
 			if revision == nil {
-				return nil, fmt.Errorf("current revision data not found for change %s", changeID)
+				return nil, withCode(ErrCodeNotFound, fmt.Errorf("current revision data not found for change %s", changeID))
 			}
 			targetPatchsetNum = revision.Number
 		}
@@ -162,10 +208,8 @@ func runRepoCheckout(cmd *cobra.Command, args []string) error {
 		// Construct git URL (format: ssh://{ssh_alias}/{project})
 		gitURL := fmt.Sprintf("%s:%s", sshAlias, change.Project)
 
-		// Construct local repo path
-		// Replace / with - to create safe directory names
-		safeName := filepath.Base(change.Project)
-		repoPath := filepath.Join(repoBasePath, safeName)
+		// Construct local repo path (keeps the full project hierarchy)
+		repoPath := config.ResolveRepoPath(repoBasePath, change.Project)
 
 		// Create repo manager
 		repoManager := git.NewRepoManager(repoPath, gitURL)
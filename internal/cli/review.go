@@ -2,12 +2,15 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
 	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -31,6 +34,17 @@ var reviewPostCmd = &cobra.Command{
 	Short: "Post a review on a change",
 	Long: `Post a code review with message, vote, and inline comments.
 
+The vote is checked against the review.vote policy before it is posted: a
+[P0] or [P1] comment (inline or draft) allows at most -1, a [P2] comment at
+most 0, and the vote stays within review.vote.min and review.vote.max. A
+vote outside that range is lowered or raised to the nearest allowed one, with
+the reason appended to the message, or rejected when review.vote.enforce is
+"reject". +2 is never posted.
+
+--vote is posted on the review.label label (default Code-Review). Other
+labels are set with --label; every label must exist on the change and allow
+the value for this account.
+
 The revision-id can be:
   - "current" (default) - the latest patchset
   - Numeric patchset number (e.g., 1, 2, 3)
@@ -51,10 +65,33 @@ Examples:
   # Post review on specific patchset
   gerrit-cli review post 12345 2 --message "Review of patchset 2" --vote 1
 
+  # Set other labels as well
+  gerrit-cli review post 12345 --message "Build passed" --vote 1 --label Verified=+1
+
+  # Comment on a range, on the whole file, and on a deleted line
+  gerrit-cli review post 12345 --message "Review feedback" --vote 0 \
+    --comment "src/main.go:10:4-12:1:This block can be simplified" \
+    --comment "src/util.go::Consider splitting this file" \
+    --parent-comment "src/main.go:30:This check should be kept"
+
+  # Ask a domain owner to look at a finding
+  gerrit-cli review post 12345 --message "Unsure about the locking" --vote 0 \
+    --reviewer storage-owner@example.com --attention storage-owner@example.com \
+    --attention-reason "Please check the locking in store.go"
+
 Inline Comment Format:
-  file:line:message
+  file:line:message                                 comment on a line
+  file:startLine:startChar-endLine:endChar:message  comment on a character range
+  file::message                                     comment on the whole file
+
+  Example: "src/main.go:42:This should be refactored"
 
-  Example: "src/main.go:42:This should be refactored"`,
+--parent-comment takes the same format and comments on the parent of the
+revision, where deleted lines are shown.
+
+--reviewer and --cc add accounts (ID, email or username) or groups to the
+change and --attention adds accounts to its attention set, in the same
+request as the review.`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runReviewPost,
 }
@@ -62,8 +99,14 @@ Inline Comment Format:
 func init() {
 	// Add flags for reviewPostCmd
 	reviewPostCmd.Flags().StringP("message", "m", "", "Review message (required)")
-	reviewPostCmd.Flags().IntP("vote", "v", 0, "Code-Review vote (-2, -1, 0, +1, +2)")
-	reviewPostCmd.Flags().StringSliceP("comment", "c", []string{}, "Inline comments in format 'file:line:message'")
+	reviewPostCmd.Flags().IntP("vote", "v", 0, "Vote on review.label, Code-Review by default (-1, 0, +1)")
+	reviewPostCmd.Flags().StringArrayP("label", "l", []string{}, "Vote on another label in format 'Name=value' (repeatable)")
+	reviewPostCmd.Flags().StringArrayP("comment", "c", []string{}, "Inline comment in format 'file:line:message' (repeatable)")
+	reviewPostCmd.Flags().StringArray("parent-comment", []string{}, "Inline comment on the parent (deleted lines), same format as --comment (repeatable)")
+	reviewPostCmd.Flags().StringArray("reviewer", []string{}, "Add a reviewer: account ID, email, username or group (repeatable)")
+	reviewPostCmd.Flags().StringArray("cc", []string{}, "Add a CC: account ID, email, username or group (repeatable)")
+	reviewPostCmd.Flags().StringArray("attention", []string{}, "Add an account to the attention set (repeatable)")
+	reviewPostCmd.Flags().String("attention-reason", defaultAttentionReason, "Reason shown for --attention")
 
 	reviewPostCmd.MarkFlagRequired("message")
 
@@ -71,25 +114,85 @@ func init() {
 	reviewCmd.AddCommand(reviewPostCmd)
 }
 
-// parseInlineComment parses an inline comment string in format "file:line:message"
+var (
+	// rangePattern matches a comment range "startLine:startChar-endLine:endChar"
+	rangePattern = regexp.MustCompile(`^(\d+):(\d+)-(\d+):(\d+)$`)
+	// rangeCommentPattern matches the range and message of a range comment
+	rangeCommentPattern = regexp.MustCompile(`(?s)^(\d+:\d+-\d+:\d+):(.*)$`)
+)
+
+// parseInlineComment parses an inline comment string in format
+// "file:line:message", "file:startLine:startChar-endLine:endChar:message"
+// or, for a comment on the whole file, "file::message"
 func parseInlineComment(commentStr string) (*types.Comment, error) {
-	parts := strings.SplitN(commentStr, ":", 3)
-	if len(parts) != 3 {
+	file, rest, ok := strings.Cut(commentStr, ":")
+	position, message, found := strings.Cut(rest, ":")
+	if m := rangeCommentPattern.FindStringSubmatch(rest); m != nil {
+		position, message = m[1], m[2]
+	}
+	if !ok || !found || file == "" {
 		return nil, fmt.Errorf("invalid comment format: %s (expected file:line:message)", commentStr)
 	}
 
-	line, err := strconv.Atoi(parts[1])
+	line, rng, err := parseCommentPosition(position)
 	if err != nil {
-		return nil, fmt.Errorf("invalid line number in comment: %s", parts[1])
+		return nil, err
 	}
 
 	return &types.Comment{
-		File:    parts[0],
+		File:    file,
 		Line:    line,
-		Message: parts[2],
+		Range:   rng,
+		Message: message,
 	}, nil
 }
 
+// parseCommentPosition parses where a comment goes: "line",
+// "startLine:startChar-endLine:endChar" for a range, or "" (or "0") for the
+// whole file
+func parseCommentPosition(position string) (int, *types.Range, error) {
+	if position == "" {
+		return 0, nil, nil
+	}
+
+	if !strings.Contains(position, "-") {
+		line, err := strconv.Atoi(position)
+		if err != nil || line < 0 {
+			return 0, nil, fmt.Errorf("invalid line number in comment: %s", position)
+		}
+		return line, nil, nil
+	}
+
+	m := rangePattern.FindStringSubmatch(position)
+	if m == nil {
+		return 0, nil, fmt.Errorf("invalid range in comment: %s (expected startLine:startChar-endLine:endChar)", position)
+	}
+	var values [4]int
+	for i := range values {
+		values[i], _ = strconv.Atoi(m[i+1])
+	}
+	rng := &types.Range{StartLine: values[0], StartCharacter: values[1], EndLine: values[2], EndCharacter: values[3]}
+	if !rng.Valid() {
+		return 0, nil, fmt.Errorf("invalid range in comment: %s (lines start at 1 and the range must not end before it starts)", position)
+	}
+	return rng.EndLine, rng, nil
+}
+
+// parseLabel parses a label vote in format "Name=value", e.g. "Verified=+1"
+func parseLabel(labelStr string) (string, int, error) {
+	name, valueStr, ok := strings.Cut(labelStr, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", 0, fmt.Errorf("invalid label format: %s (expected Name=value)", labelStr)
+	}
+
+	value, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(valueStr), "+"))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid value for label %s: %s", name, valueStr)
+	}
+	return name, value, nil
+}
+
 // runReviewPost executes the review post command
 func runReviewPost(cmd *cobra.Command, args []string) error {
 	changeID := args[0]
@@ -100,13 +203,40 @@ func runReviewPost(cmd *cobra.Command, args []string) error {
 
 	message, _ := cmd.Flags().GetString("message")
 	vote, _ := cmd.Flags().GetInt("vote")
-	commentStrs, _ := cmd.Flags().GetStringSlice("comment")
+	commentStrs, _ := cmd.Flags().GetStringArray("comment")
+	parentCommentStrs, _ := cmd.Flags().GetStringArray("parent-comment")
+	labelStrs, _ := cmd.Flags().GetStringArray("label")
+	reviewerStrs, _ := cmd.Flags().GetStringArray("reviewer")
+	ccStrs, _ := cmd.Flags().GetStringArray("cc")
+	attentionStrs, _ := cmd.Flags().GetStringArray("attention")
+	attentionReason, _ := cmd.Flags().GetString("attention-reason")
 	format := viper.GetString("output.format")
+	voteLabel := viper.GetString("review.label")
+	if voteLabel == "" {
+		voteLabel = gerrit.DefaultVoteLabel
+	}
 
 	// Validate vote
 	if vote < -2 || vote > 2 {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Vote must be between -2 and +2", "INVALID_VOTE"))
-		return fmt.Errorf("invalid vote")
+		return reportError(format, ErrCodeInvalidInput, errors.New("Vote must be between -2 and +2"))
+	}
+
+	// Parse label votes; --label on the vote label is the same as --vote
+	labels := make(map[string]int)
+	for _, labelStr := range labelStrs {
+		name, value, err := parseLabel(labelStr)
+		if err != nil {
+			return reportError(format, ErrCodeInvalidInput, err)
+		}
+		if name != voteLabel {
+			labels[name] = value
+			continue
+		}
+		if cmd.Flags().Changed("vote") && value != vote {
+			err := fmt.Errorf("conflicting votes on %s: --vote %+d and --label %s", voteLabel, vote, labelStr)
+			return reportError(format, ErrCodeInvalidInput, err)
+		}
+		vote = value
 	}
 
 	// Parse inline comments
@@ -114,9 +244,16 @@ func runReviewPost(cmd *cobra.Command, args []string) error {
 	for _, commentStr := range commentStrs {
 		comment, err := parseInlineComment(commentStr)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, FormatErrorResponse(format, err.Error(), "INVALID_COMMENT"))
-			return err
+			return reportError(format, ErrCodeInvalidInput, err)
+		}
+		comments = append(comments, *comment)
+	}
+	for _, commentStr := range parentCommentStrs {
+		comment, err := parseInlineComment(commentStr)
+		if err != nil {
+			return reportError(format, ErrCodeInvalidInput, err)
 		}
+		comment.Side = types.SideParent
 		comments = append(comments, *comment)
 	}
 
@@ -126,19 +263,26 @@ func runReviewPost(cmd *cobra.Command, args []string) error {
 	httpPassword := viper.GetString("gerrit.http_password")
 
 	if httpURL == "" || httpUser == "" || httpPassword == "" {
-		fmt.Fprintln(os.Stderr, FormatErrorResponse(format, "Gerrit HTTP configuration not found. Set GERRIT_HTTP_URL, GERRIT_HTTP_USER, and GERRIT_HTTP_PASSWORD.", "CONFIG_ERROR"))
-		return fmt.Errorf("configuration error")
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
+	}
+
+	voteCfg, err := config.LoadVoteConfig()
+	if err != nil {
+		return reportError(format, ErrCodeConfig, err)
 	}
 
 	// Create Gerrit client
-	client := gerrit.NewClient(httpURL, httpUser, httpPassword)
+	language := viper.GetString("review.language")
+	client := newGerritClient()
+	client.SetLanguage(language)
+	client.SetVoteLabel(voteLabel)
 
 	// Execute command with standard formatting
 	return ExecuteCommand(format, "review post", version, func() (interface{}, error) {
 		ctx := context.Background()
 
 		// First, get the change to extract numeric IDs if needed
-		change, err := client.GetChangeDetail(ctx, changeID, []string{})
+		change, err := client.GetChangeDetail(ctx, changeID, []string{"CURRENT_REVISION", "DETAILED_LABELS"})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get change details: %w", err)
 		}
@@ -156,7 +300,7 @@ func runReviewPost(cmd *cobra.Command, args []string) error {
 			// Try to parse as number
 			num, err := strconv.Atoi(revisionID)
 			if err != nil {
-				return nil, fmt.Errorf("invalid revision ID: %s", revisionID)
+				return nil, withCode(ErrCodeInvalidInput, fmt.Errorf("invalid revision ID: %s", revisionID))
 			}
 			patchsetNum = num
 		}
@@ -169,8 +313,34 @@ func runReviewPost(cmd *cobra.Command, args []string) error {
 		reviewResult := &types.ReviewResult{
 			Summary:  message,
 			Vote:     vote,
+			Labels:   labels,
 			Comments: comments,
 		}
+		for _, account := range reviewerStrs {
+			reviewResult.Reviewers = append(reviewResult.Reviewers, types.Reviewer{Account: account})
+		}
+		for _, account := range ccStrs {
+			reviewResult.Reviewers = append(reviewResult.Reviewers, types.Reviewer{Account: account, CC: true})
+		}
+		for _, account := range attentionStrs {
+			reviewResult.Attention = append(reviewResult.Attention, types.Attention{Account: account, Reason: attentionReason})
+		}
+
+		// Enforce the vote policy over the comments this review publishes
+		drafts, err := client.ListDrafts(ctx, strconv.Itoa(change.Number), strconv.Itoa(patchsetNum))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list drafts: %w", err)
+		}
+		decision, err := voteCfg.Policy().ApplyToReview(reviewResult, gerrit.CommentMessages(drafts), locale.For(language))
+		if err != nil {
+			return nil, err
+		}
+
+		// Check every label against what this account may vote on the change
+		votes := client.ReviewLabels(reviewResult)
+		if err := change.CheckLabels(votes); err != nil {
+			return nil, err
+		}
 
 		// Post the review
 		err = client.PostReview(ctx, change.Number, patchsetNum, reviewResult)
@@ -180,11 +350,16 @@ func runReviewPost(cmd *cobra.Command, args []string) error {
 
 		// Return success response
 		return map[string]interface{}{
-			"change":   change.Number,
-			"patchset": patchsetNum,
-			"vote":     vote,
-			"message":  message,
-			"comments": len(comments),
+			"change":    change.Number,
+			"patchset":  patchsetNum,
+			"vote":      reviewResult.Vote,
+			"labels":    votes,
+			"message":   reviewResult.Summary,
+			"comments":  len(comments),
+			"reviewers": reviewerStrs,
+			"ccs":       ccStrs,
+			"attention": attentionStrs,
+			"policy":    decision,
 		}, nil
 	})
 }
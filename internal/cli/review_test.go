@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
+	"github.com/spf13/viper"
+)
+
+func TestParseLabel(t *testing.T) {
+	tests := []struct {
+		input string
+		name  string
+		value int
+		ok    bool
+	}{
+		{"Verified=+1", "Verified", 1, true},
+		{"Code-Review=-1", "Code-Review", -1, true},
+		{" AI-Review = 0 ", "AI-Review", 0, true},
+		{"Verified", "", 0, false},
+		{"=1", "", 0, false},
+		{"Verified=yes", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		name, value, err := parseLabel(tt.input)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseLabel(%q): expected ok=%t, got error %v", tt.input, tt.ok, err)
+			continue
+		}
+		if tt.ok && (name != tt.name || value != tt.value) {
+			t.Errorf("parseLabel(%q): expected %s=%+d, got %s=%+d", tt.input, tt.name, tt.value, name, value)
+		}
+	}
+}
+
+func TestParseInlineComment(t *testing.T) {
+	tests := []struct {
+		input   string
+		file    string
+		line    int
+		rng     *types.Range
+		message string
+	}{
+		{"src/main.go:42:Use a constant", "src/main.go", 42, nil, "Use a constant"},
+		{"src/main.go:42:Note: see main.go:10", "src/main.go", 42, nil, "Note: see main.go:10"},
+		{"src/main.go:10:4-12:1:Simplify this", "src/main.go", 12, &types.Range{StartLine: 10, StartCharacter: 4, EndLine: 12, EndCharacter: 1}, "Simplify this"},
+		{"src/util.go::Split this file", "src/util.go", 0, nil, "Split this file"},
+		{"src/util.go:0:Split this file", "src/util.go", 0, nil, "Split this file"},
+	}
+
+	for _, tt := range tests {
+		comment, err := parseInlineComment(tt.input)
+		if err != nil {
+			t.Errorf("parseInlineComment(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if comment.File != tt.file || comment.Line != tt.line || comment.Message != tt.message {
+			t.Errorf("parseInlineComment(%q) = %+v", tt.input, comment)
+		}
+		if (comment.Range == nil) != (tt.rng == nil) || (tt.rng != nil && *comment.Range != *tt.rng) {
+			t.Errorf("parseInlineComment(%q): expected range %+v, got %+v", tt.input, tt.rng, comment.Range)
+		}
+	}
+
+	for _, input := range []string{
+		"src/main.go",
+		"src/main.go:42",
+		":42:message",
+		"src/main.go:x:message",
+		"src/main.go:-3:message",
+		"src/main.go:12:1-10:4:ends before it starts",
+		"src/main.go:0:0-1:0:line 0",
+	} {
+		if _, err := parseInlineComment(input); err == nil {
+			t.Errorf("parseInlineComment(%q): expected an error", input)
+		}
+	}
+}
+
+func TestParseCommentPosition(t *testing.T) {
+	if line, rng, err := parseCommentPosition("3:0-3:8"); err != nil || line != 3 || rng == nil || rng.EndCharacter != 8 {
+		t.Errorf("parseCommentPosition(3:0-3:8) = %d, %+v, %v", line, rng, err)
+	}
+	for _, position := range []string{"4x-12:1", "10:4-12", "1:2-3:4:5", "x"} {
+		if _, _, err := parseCommentPosition(position); err == nil {
+			t.Errorf("parseCommentPosition(%q): expected an error", position)
+		}
+	}
+}
+
+func TestReviewPostCommentFlagsKeepCommas(t *testing.T) {
+	flags := reviewPostCmd.Flags()
+	t.Cleanup(func() {
+		for _, name := range []string{"comment", "parent-comment"} {
+			flags.Lookup(name).Value.(interface{ Replace([]string) error }).Replace(nil)
+			flags.Lookup(name).Changed = false
+		}
+	})
+
+	err := flags.Parse([]string{
+		"--comment", "main.go:10:Use a, b and c here",
+		"--parent-comment", "main.go:3:Keep x, y",
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	comments, _ := flags.GetStringArray("comment")
+	if len(comments) != 1 || comments[0] != "main.go:10:Use a, b and c here" {
+		t.Errorf("Expected one --comment, got %q", comments)
+	}
+	parentComments, _ := flags.GetStringArray("parent-comment")
+	if len(parentComments) != 1 || parentComments[0] != "main.go:3:Keep x, y" {
+		t.Errorf("Expected one --parent-comment, got %q", parentComments)
+	}
+}
+
+func TestReviewPostAppliesPolicyToOtherLabels(t *testing.T) {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("skipping network-dependent test: %v", err)
+	}
+	var mu sync.Mutex
+	var posted gerrit.ReviewInput
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/drafts/"):
+			w.Write([]byte(`)]}'
+{"main.go": [{"id": "d1", "line": 3, "message": "[P0] nil dereference"}]}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/review"):
+			mu.Lock()
+			defer mu.Unlock()
+			json.NewDecoder(r.Body).Decode(&posted)
+			w.Write([]byte(")]}'\n{}"))
+		default:
+			w.Write([]byte(`)]}'
+{"_number": 100, "current_revision": "abc", "revisions": {"abc": {"_number": 1}},
+ "labels": {"Code-Review": {}, "AI-Review": {}},
+ "permitted_labels": {"Code-Review": ["-2", "-1", " 0", "+1", "+2"], "AI-Review": ["-1", " 0", "+1"]}}`))
+		}
+	})}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Close()
+
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("gerrit.http_url", "http://"+listener.Addr().String())
+	viper.Set("gerrit.http_user", "ai-bot")
+	viper.Set("gerrit.http_password", "secret")
+	viper.Set("review.label", "AI-Review")
+
+	flags := reviewPostCmd.Flags()
+	defer func() {
+		flags.Set("message", "")
+		flags.Lookup("label").Value.(interface{ Replace([]string) error }).Replace(nil)
+	}()
+	if err := flags.Parse([]string{"--message", "LGTM", "--label", "Code-Review=+1"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if err := runReviewPost(reviewPostCmd, []string{"100"}); err != nil {
+		t.Fatalf("runReviewPost failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := posted.Labels["Code-Review"]; got != -1 {
+		t.Errorf("Expected Code-Review +1 with a [P0] draft to be posted as -1, got %+d (%v)", got, posted.Labels)
+	}
+}
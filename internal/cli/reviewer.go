@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/spf13/cobra"
+)
+
+// reviewerCmd represents the reviewer command group
+var reviewerCmd = &cobra.Command{
+	Use:   "reviewer",
+	Short: "Manage reviewers and CCs of changes",
+	Long: `List, add, remove and suggest reviewers and CCs of Gerrit changes.
+
+Accounts can be given by account ID, email or username. Groups can be added
+by name; their members are added individually.`,
+}
+
+// reviewerListCmd lists the reviewers of a change
+var reviewerListCmd = &cobra.Command{
+	Use:   "list <change-id>",
+	Short: "List reviewers and CCs of a change with their votes",
+	Long: `List the reviewers and CCs of a change with their votes.
+
+Examples:
+  gerrit-cli reviewer list 12345`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReviewerList,
+}
+
+// reviewerAddCmd adds reviewers to a change
+var reviewerAddCmd = &cobra.Command{
+	Use:   "add <change-id> <account>...",
+	Short: "Add reviewers or CCs to a change",
+	Long: `Add accounts or groups as reviewers of a change, or as CCs with --cc.
+
+Examples:
+  # Add a domain owner as reviewer
+  gerrit-cli reviewer add 12345 storage-owner@example.com
+
+  # CC two accounts
+  gerrit-cli reviewer add 12345 jdoe asmith --cc`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runReviewerAdd,
+}
+
+// reviewerRemoveCmd removes a reviewer from a change
+var reviewerRemoveCmd = &cobra.Command{
+	Use:   "remove <change-id> <account>",
+	Short: "Remove a reviewer or CC from a change",
+	Long: `Remove a reviewer or CC from a change. The votes of the reviewer are
+removed as well.
+
+Examples:
+  gerrit-cli reviewer remove 12345 jdoe@example.com`,
+	Args: cobra.ExactArgs(2),
+	RunE: runReviewerRemove,
+}
+
+// reviewerSuggestCmd suggests reviewers for a change
+var reviewerSuggestCmd = &cobra.Command{
+	Use:   "suggest <change-id> <query>",
+	Short: "Suggest reviewers for a change",
+	Long: `Suggest accounts and groups matching a query as reviewers of a change.
+
+Examples:
+  gerrit-cli reviewer suggest 12345 storage --limit 5`,
+	Args: cobra.ExactArgs(2),
+	RunE: runReviewerSuggest,
+}
+
+func init() {
+	// Flags for reviewerAddCmd
+	reviewerAddCmd.Flags().Bool("cc", false, "Add as CC instead of reviewer")
+
+	// Flags for reviewerSuggestCmd
+	reviewerSuggestCmd.Flags().IntP("limit", "n", 10, "Maximum number of suggestions")
+
+	// Add subcommands to reviewerCmd
+	reviewerCmd.AddCommand(reviewerListCmd)
+	reviewerCmd.AddCommand(reviewerAddCmd)
+	reviewerCmd.AddCommand(reviewerRemoveCmd)
+	reviewerCmd.AddCommand(reviewerSuggestCmd)
+}
+
+// runReviewerList executes the reviewer list command
+func runReviewerList(cmd *cobra.Command, args []string) error {
+	return runChangeAction("reviewer list", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		reviewers, err := client.ListReviewers(ctx, changeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list reviewers: %w", err)
+		}
+		return reviewers, nil
+	})
+}
+
+// runReviewerAdd executes the reviewer add command
+func runReviewerAdd(cmd *cobra.Command, args []string) error {
+	accounts := args[1:]
+	cc, _ := cmd.Flags().GetBool("cc")
+
+	state := gerrit.ReviewerStateReviewer
+	if cc {
+		state = gerrit.ReviewerStateCC
+	}
+
+	return runChangeAction("reviewer add", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		results := make([]*gerrit.ReviewerResult, 0, len(accounts))
+		for _, account := range accounts {
+			result, err := client.AddReviewer(ctx, changeID, &gerrit.ReviewerInput{Reviewer: account, State: state})
+			if err != nil {
+				return nil, fmt.Errorf("failed to add %s: %w", account, err)
+			}
+			results = append(results, result)
+		}
+		return results, nil
+	})
+}
+
+// runReviewerRemove executes the reviewer remove command
+func runReviewerRemove(cmd *cobra.Command, args []string) error {
+	return runChangeAction("reviewer remove", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		account, err := client.ResolveAccount(ctx, args[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve account %s: %w", args[1], err)
+		}
+
+		if err := client.RemoveReviewer(ctx, changeID, fmt.Sprint(account.AccountID)); err != nil {
+			return nil, fmt.Errorf("failed to remove reviewer: %w", err)
+		}
+
+		return map[string]interface{}{
+			"change":  changeID,
+			"removed": account,
+		}, nil
+	})
+}
+
+// runReviewerSuggest executes the reviewer suggest command
+func runReviewerSuggest(cmd *cobra.Command, args []string) error {
+	query := args[1]
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	return runChangeAction("reviewer suggest", args, func(ctx context.Context, client *gerrit.Client, changeID string) (interface{}, error) {
+		suggestions, err := client.SuggestReviewers(ctx, changeID, query, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to suggest reviewers: %w", err)
+		}
+		return suggestions, nil
+	})
+}
@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultRobotID is the robot_id used when review.robot_comments.robot_id is unset
+const defaultRobotID = "gerrit-ai-review"
+
+// robotCommentCmd represents the robot-comment command group
+var robotCommentCmd = &cobra.Command{
+	Use:   "robot-comment",
+	Short: "Manage robot comments on changes",
+	Long: `List and post robot comments on Gerrit changes.
+
+Robot comments are findings of automated tools. Each one carries the
+robot_id of the tool and the robot_run_id of the run that produced it, and
+may carry fix suggestions that Gerrit offers to apply with one click.`,
+}
+
+// robotCommentListCmd lists robot comments for a change
+var robotCommentListCmd = &cobra.Command{
+	Use:   "list <change-id> [revision-id]",
+	Short: "List robot comments for a change",
+	Long: `List all robot comments for a specific patchset, including their fix suggestions.
+
+The revision-id can be:
+  - "current" (default) - the latest patchset
+  - Numeric patchset number (e.g., 1, 2, 3)
+  - Commit SHA
+
+Examples:
+  # List all robot comments on current patchset
+  gerrit-cli robot-comment list 12345
+
+  # List robot comments of one robot on patchset 2
+  gerrit-cli robot-comment list 12345 2 --robot-id gerrit-ai-review
+
+  # List robot comments for specific file only
+  gerrit-cli robot-comment list 12345 --file src/main.go`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runRobotCommentList,
+}
+
+// robotCommentPostCmd posts a robot comment
+var robotCommentPostCmd = &cobra.Command{
+	Use:   "post <change-id> <file> <line> <message> [revision-id]",
+	Short: "Post a robot comment with an optional fix suggestion",
+	Long: `Post a robot comment on a specific file and line.
+
+The line can also be a character range "startLine:startChar-endLine:endChar",
+or 0 for a comment on the whole file.
+
+The comment is published immediately, without a vote; your draft comments
+stay unpublished. With --fix or --fix-file the comment carries a fix
+suggestion replacing the whole line, or the range, which Gerrit offers to
+apply.
+
+The robot_id defaults to review.robot_comments.robot_id (REVIEW_ROBOT_ID,
+default gerrit-ai-review) and the robot_run_id to the current time.
+
+As with drafts, a [P0]/[P1] prefix marks the comment unresolved and a
+[P2]/[P3] prefix marks it resolved.
+
+The revision-id can be:
+  - "current" (default) - the latest patchset
+  - Numeric patchset number (e.g., 1, 2, 3)
+  - Commit SHA
+
+Examples:
+  # Post a finding
+  gerrit-cli robot-comment post 12345 src/main.go 42 "[P1] Missing error check"
+
+  # Post a finding with a one-line fix
+  gerrit-cli robot-comment post 12345 src/main.go 42 "[P2] Use a constant" --fix "const maxRetries = 3"
+
+  # Post a multi-line fix read from a file
+  gerrit-cli robot-comment post 12345 src/main.go 42 "[P1] Missing error check" --fix-file fix.txt`,
+	Args: cobra.RangeArgs(4, 5),
+	RunE: runRobotCommentPost,
+}
+
+func init() {
+	// Flags for robotCommentListCmd
+	robotCommentListCmd.Flags().StringP("file", "f", "", "Filter robot comments for specific file")
+	robotCommentListCmd.Flags().String("robot-id", "", "Show only comments of this robot")
+
+	// Flags for robotCommentPostCmd
+	robotCommentPostCmd.Flags().String("robot-id", "", "Robot ID (default: review.robot_comments.robot_id)")
+	robotCommentPostCmd.Flags().String("run-id", "", "Robot run ID (default: current time)")
+	robotCommentPostCmd.Flags().String("fix", "", "Replacement for the commented line or range")
+	robotCommentPostCmd.Flags().String("fix-file", "", "Read the replacement for the commented line or range from a file")
+	robotCommentPostCmd.Flags().Bool("resolved", false, "Mark as resolved (override auto-detection)")
+	robotCommentPostCmd.Flags().Bool("unresolved", false, "Mark as unresolved (override auto-detection)")
+	robotCommentPostCmd.MarkFlagsMutuallyExclusive("fix", "fix-file")
+
+	// Add subcommands to robotCommentCmd
+	robotCommentCmd.AddCommand(robotCommentListCmd)
+	robotCommentCmd.AddCommand(robotCommentPostCmd)
+}
+
+// runRobotCommentList executes the robot-comment list command
+func runRobotCommentList(cmd *cobra.Command, args []string) error {
+	changeID := args[0]
+	revisionID := "current"
+	if len(args) > 1 {
+		revisionID = args[1]
+	}
+
+	fileFilter, _ := cmd.Flags().GetString("file")
+	robotFilter, _ := cmd.Flags().GetString("robot-id")
+	format := viper.GetString("output.format")
+
+	// Get Gerrit configuration
+	httpURL := viper.GetString("gerrit.http_url")
+	httpUser := viper.GetString("gerrit.http_user")
+	httpPassword := viper.GetString("gerrit.http_password")
+
+	if httpURL == "" || httpUser == "" || httpPassword == "" {
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
+	}
+
+	// Create Gerrit client
+	client := newGerritClient()
+
+	// Execute command with standard formatting
+	return ExecuteCommand(format, "robot-comment list", version, func() (interface{}, error) {
+		ctx := context.Background()
+
+		comments, err := client.ListRobotComments(ctx, changeID, revisionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Apply filters
+		filteredComments := make(map[string][]gerrit.RobotCommentInfo)
+		for filePath, fileComments := range comments {
+			if fileFilter != "" && filePath != fileFilter {
+				continue
+			}
+			for _, comment := range fileComments {
+				if robotFilter == "" || comment.RobotID == robotFilter {
+					filteredComments[filePath] = append(filteredComments[filePath], comment)
+				}
+			}
+		}
+
+		return filteredComments, nil
+	})
+}
+
+// runRobotCommentPost executes the robot-comment post command
+func runRobotCommentPost(cmd *cobra.Command, args []string) error {
+	changeID := args[0]
+	filePath := args[1]
+	position := args[2]
+	message := args[3]
+
+	revisionID := "current"
+	if len(args) > 4 {
+		revisionID = args[4]
+	}
+
+	format := viper.GetString("output.format")
+
+	// Parse line number or range
+	line, rng, err := parseCommentPosition(position)
+	if err != nil {
+		return reportError(format, ErrCodeInvalidInput, err)
+	}
+
+	// Get flags
+	robotID, _ := cmd.Flags().GetString("robot-id")
+	runID, _ := cmd.Flags().GetString("run-id")
+	fix, _ := cmd.Flags().GetString("fix")
+	fixFile, _ := cmd.Flags().GetString("fix-file")
+	resolvedFlag, _ := cmd.Flags().GetBool("resolved")
+	unresolvedFlag, _ := cmd.Flags().GetBool("unresolved")
+
+	if robotID == "" {
+		robotID = viper.GetString("review.robot_comments.robot_id")
+	}
+	if robotID == "" {
+		robotID = defaultRobotID
+	}
+	if runID == "" {
+		runID = time.Now().UTC().Format("20060102T150405Z")
+	}
+
+	if fixFile != "" {
+		data, err := os.ReadFile(fixFile)
+		if err != nil {
+			return reportError(format, ErrCodeInvalidInput, err)
+		}
+		fix = string(data)
+	}
+	if fix != "" && line == 0 {
+		err := fmt.Errorf("a fix needs a line or range to replace")
+		return reportError(format, ErrCodeInvalidInput, err)
+	}
+
+	// Determine unresolved status
+	var unresolved *bool
+	if unresolvedFlag {
+		val := true
+		unresolved = &val
+	} else if resolvedFlag {
+		val := false
+		unresolved = &val
+	} else {
+		// Auto-detect from message priority prefix
+		unresolved = determineUnresolved(message)
+	}
+
+	// Get Gerrit configuration
+	httpURL := viper.GetString("gerrit.http_url")
+	httpUser := viper.GetString("gerrit.http_user")
+	httpPassword := viper.GetString("gerrit.http_password")
+
+	if httpURL == "" || httpUser == "" || httpPassword == "" {
+		return reportError(format, ErrCodeConfig, errGerritHTTPConfig)
+	}
+
+	// Create Gerrit client
+	client := newGerritClient()
+	client.SetLanguage(viper.GetString("review.language"))
+
+	// Execute command with standard formatting
+	return ExecuteCommand(format, "robot-comment post", version, func() (interface{}, error) {
+		ctx := context.Background()
+
+		robot := types.Robot{ID: robotID, RunID: runID}
+		comment := types.Comment{
+			File:       filePath,
+			Line:       line,
+			Range:      rng,
+			Message:    message,
+			Unresolved: unresolved,
+			Fix:        fix,
+		}
+
+		if err := client.PostRobotComments(ctx, changeID, revisionID, robot, []types.Comment{comment}); err != nil {
+			return nil, fmt.Errorf("failed to post robot comment: %w", err)
+		}
+
+		return map[string]interface{}{
+			"change":       changeID,
+			"revision":     revisionID,
+			"file":         filePath,
+			"line":         line,
+			"robot_id":     robotID,
+			"robot_run_id": runID,
+			"fix":          fix != "",
+		}, nil
+	})
+}
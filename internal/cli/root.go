@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -43,9 +45,9 @@ func ExecuteGerritCLI(ver string) error {
 // ExecuteReviewer executes the gerrit-reviewer CLI tool
 func ExecuteReviewer(ver string) error {
 	version = ver
-	reviewerCmd := createReviewerRootCmd()
-	reviewerCmd.Version = ver
-	return reviewerCmd.Execute()
+	cmd := createReviewerRootCmd()
+	cmd.Version = ver
+	return cmd.Execute()
 }
 
 // createReviewerRootCmd creates the root command for gerrit-reviewer CLI
@@ -57,7 +59,11 @@ func createReviewerRootCmd() *cobra.Command {
 
 It can run in two modes:
   - One-shot mode: Review a specific patchset (use flags directly)
-  - Serve mode: Listen to Gerrit events and review automatically (use 'serve' subcommand)`,
+  - Serve mode: Listen to Gerrit events and review automatically (use 'serve' subcommand)
+
+Review tasks that failed every retry can be inspected and requeued with the
+'deadletter' subcommand. 'skill render' prints the prompt a change would be
+reviewed with.`,
 		Version: version,
 	}
 
@@ -69,8 +75,10 @@ It can run in two modes:
 	// Initialize config on command initialization
 	cobra.OnInitialize(initConfig)
 
-	// Add subcommands (only serve for now)
+	// Add subcommands
 	cmd.AddCommand(serveCmd)
+	cmd.AddCommand(deadletterCmd)
+	cmd.AddCommand(skillCmd)
 
 	return cmd
 }
@@ -95,7 +103,7 @@ and focuses on querying changes, fetching diffs, and managing reviews.`,
 	cmd.PersistentFlags().String("user", "", "Gerrit SSH user")
 	cmd.PersistentFlags().String("http-url", "", "Gerrit HTTP URL for REST API")
 	cmd.PersistentFlags().String("http-user", "", "HTTP username for authentication")
-	cmd.PersistentFlags().String("format", "json", "Output format: json or text")
+	cmd.PersistentFlags().String("format", "json", "Output format: json, text or ndjson")
 
 	// Bind flags to viper
 	viper.BindPFlag("gerrit.ssh_alias", cmd.PersistentFlags().Lookup("ssh-alias"))
@@ -114,7 +122,10 @@ and focuses on querying changes, fetching diffs, and managing reviews.`,
 	cmd.AddCommand(patchsetCmd)
 	cmd.AddCommand(commentCmd)
 	cmd.AddCommand(draftCmd)
+	cmd.AddCommand(robotCommentCmd)
 	cmd.AddCommand(reviewCmd)
+	cmd.AddCommand(reviewerCmd)
+	cmd.AddCommand(attentionCmd)
 	cmd.AddCommand(summaryCmd)
 	cmd.AddCommand(repoCmd)
 
@@ -185,6 +196,12 @@ func initConfig() {
 	}
 }
 
+// newGerritClient creates a Gerrit REST client from the gerrit.* settings,
+// including gerrit.http_retry and gerrit.http_rate_limit
+func newGerritClient() *gerrit.Client {
+	return config.LoadGerritConfig().NewClient()
+}
+
 // bindEnvVariables manually binds environment variables to viper keys
 // This ensures backward compatibility with existing environment variable names
 func bindEnvVariables() {
@@ -196,6 +213,8 @@ func bindEnvVariables() {
 	viper.BindEnv("gerrit.http_url", "GERRIT_HTTP_URL")
 	viper.BindEnv("gerrit.http_user", "GERRIT_HTTP_USER")
 	viper.BindEnv("gerrit.http_password", "GERRIT_HTTP_PASSWORD")
+	viper.BindEnv("gerrit.http_retry.max_attempts", "GERRIT_HTTP_MAX_ATTEMPTS")
+	viper.BindEnv("gerrit.http_rate_limit", "GERRIT_HTTP_RATE_LIMIT")
 
 	// Git configuration
 	viper.BindEnv("git.repo_base_path", "GIT_REPO_BASE_PATH")
@@ -204,6 +223,13 @@ func bindEnvVariables() {
 	viper.BindEnv("review.cli", "REVIEW_CLI")
 	viper.BindEnv("review.claude_timeout", "CLAUDE_TIMEOUT")
 	viper.BindEnv("review.claude_skip_permissions", "CLAUDE_SKIP_PERMISSIONS")
+	viper.BindEnv("review.output_mode", "REVIEW_OUTPUT_MODE")
+	viper.BindEnv("review.language", "REVIEW_LANGUAGE")
+	viper.BindEnv("review.label", "REVIEW_LABEL")
+	viper.BindEnv("review.robot_comments.robot_id", "REVIEW_ROBOT_ID")
+	viper.BindEnv("review.vote.max", "REVIEW_VOTE_MAX")
+	viper.BindEnv("review.vote.min", "REVIEW_VOTE_MIN")
+	viper.BindEnv("review.vote.enforce", "REVIEW_VOTE_ENFORCE")
 
 	// Output configuration
 	viper.BindEnv("output.format", "OUTPUT_FORMAT")
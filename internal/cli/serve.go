@@ -16,6 +16,7 @@ import (
 
 	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/events"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/git"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/queue"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/reviewer"
@@ -31,6 +32,7 @@ This replaces the bash scripts (listener.sh + reviewer-worker.sh) with a single
 Go binary that:
   - Listens to Gerrit SSH stream-events
   - Filters patchset-created events
+  - Cancels reviews of superseded, abandoned or merged changes
   - Dispatches review tasks to a worker pool
   - Processes reviews automatically
 
@@ -68,10 +70,16 @@ func runServe(cmd *cobra.Command, args []string) error {
 	fmt.Println("║       Gerrit AI Reviewer - Serve Mode               ║")
 	fmt.Println("╚══════════════════════════════════════════════════════╝")
 	fmt.Println("")
-	fmt.Printf("SSH Alias:    %s\n", cfg.Gerrit.SSHAlias)
+	if cfg.Gerrit.SSH.Client == "native" {
+		fmt.Printf("SSH:          %s@%s:%d (native)\n", cfg.Gerrit.SSH.User, cfg.Gerrit.SSH.Host, cfg.Gerrit.SSH.Port)
+	} else {
+		fmt.Printf("SSH Alias:    %s\n", cfg.Gerrit.SSHAlias)
+	}
 	fmt.Printf("Workers:      %d\n", cfg.Serve.Workers)
 	fmt.Printf("Queue size:   %d\n", cfg.Serve.QueueSize)
 	fmt.Printf("Lazy mode:    %t\n", cfg.Serve.LazyMode)
+	fmt.Printf("Durable:      %t\n", cfg.Serve.Durable)
+	fmt.Printf("Language:     %s\n", cfg.Review.Language)
 	if len(cfg.Serve.Filter.Projects) > 0 {
 		fmt.Printf("Watch:        %v\n", cfg.Serve.Filter.Projects)
 	} else {
@@ -80,16 +88,47 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if len(cfg.Serve.Filter.Exclude) > 0 {
 		fmt.Printf("Exclude:      %v\n", cfg.Serve.Filter.Exclude)
 	}
+	if len(cfg.Projects) > 0 {
+		fmt.Printf("Overrides:    %d projects entries\n", len(cfg.Projects))
+	}
+	if cfg.Review.RepoConfig {
+		fmt.Printf("Repo config:  %s\n", config.RepoConfigFile)
+	}
 	fmt.Println("")
 
+	transport, err := newEventTransport(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to set up SSH client: %w", err)
+	}
+
 	// Run preflight checks
 	log.Info("Running preflight checks...")
-	if err := runPreflightChecks(log, cfg); err != nil {
+	if err := runPreflightChecks(log, cfg, transport); err != nil {
 		return fmt.Errorf("preflight checks failed: %w", err)
 	}
 	log.Info("✓ All preflight checks passed")
 	fmt.Println("")
 
+	// Relocate clones from the legacy flat layout
+	migrated, err := git.MigrateLegacyClones(context.Background(), cfg.Git.RepoBasePath, cfg.GetRepoPath)
+	if err != nil {
+		log.Warnf("Failed to migrate legacy clones: %v", err)
+	}
+	for _, m := range migrated {
+		if m.Skipped != "" {
+			log.Warnf("Legacy clone %s left in place: %s", m.From, m.Skipped)
+		} else {
+			log.Infof("Relocated clone of %s: %s -> %s", m.Project, m.From, m.To)
+		}
+	}
+
+	// Remove worktrees left behind by a previous run
+	if removed, err := git.PruneWorktrees(context.Background(), cfg.GetWorktreeBasePath()); err != nil {
+		log.Warnf("Failed to prune stale worktrees: %v", err)
+	} else if removed > 0 {
+		log.Infof("Pruned %d stale worktree(s)", removed)
+	}
+
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -103,26 +142,67 @@ func runServe(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
+	// Load the event watermark used to catch up on missed patchsets
+	watermark, err := events.LoadWatermark(cfg.GetWatermarkPath())
+	if err != nil {
+		return err
+	}
+	if watermark.Last().IsZero() {
+		// First start: only review what arrives from now on
+		if err := watermark.Advance(time.Now().Unix()); err != nil {
+			log.Warnf("Failed to persist event watermark: %v", err)
+		}
+	} else {
+		log.Infof("Last processed event: %s", watermark.Last().Format(time.RFC3339))
+	}
+
 	// Create components
-	listener := events.NewListener(cfg.Gerrit.SSHAlias)
-	filter := events.NewFilter(events.FilterConfig{
-		Projects: cfg.Serve.Filter.Projects,
-		Exclude:  cfg.Serve.Filter.Exclude,
-	})
-	q := queue.NewQueue(cfg.Serve.QueueSize, queue.QueueConfig{LazyMode: cfg.Serve.LazyMode})
+	listener := events.NewListenerWithTransport(transport)
+	gerritClient := cfg.Gerrit.NewClient()
+	listener.SetCatchUp(watermark, events.NewCatchUp(gerritClient, cfg.Gerrit.HTTPUser).MissedEvents)
+	filter, err := newEventFilter(cfg, gerritClient)
+	if err != nil {
+		return fmt.Errorf("invalid serve.filter: %w", err)
+	}
+	queueCfg := queue.QueueConfig{LazyMode: cfg.Serve.LazyMode}
+	var q *queue.Queue
+	if cfg.Serve.Durable {
+		var restored []queue.Task
+		q, restored, err = queue.NewDurableQueue(cfg.Serve.QueueSize, queueCfg, cfg.GetQueueJournalPath())
+		if err != nil {
+			return fmt.Errorf("failed to open queue journal: %w", err)
+		}
+		defer q.Close()
+		for _, task := range restored {
+			log.Infof("♻️  Restored: %s #%d/%d", task.Project, task.ChangeNumber, task.PatchsetNumber)
+		}
+	} else {
+		q = queue.NewQueue(cfg.Serve.QueueSize, queueCfg)
+	}
 	rev := reviewer.NewReviewer(cfg)
-	pool := worker.NewPool(cfg.Serve.Workers, q, rev)
+	deadLetters := queue.NewDeadLetterStore(cfg.GetQueueDir())
+	pool := worker.NewPool(cfg.Serve.Workers, q, rev, worker.PoolConfig{
+		Retry:       retryPolicies(cfg),
+		DeadLetters: deadLetters,
+	})
 
 	// Start worker pool
 	go pool.Start(ctx)
 
+	// Pick up tasks requeued with "gerrit-reviewer deadletter requeue"
+	go pollRequeued(ctx, log, deadLetters, q)
+
+	// Route each subscribed event type to its handler
+	dispatcher := newEventDispatcher(log, cfg, q, filter)
+	listener.SetEventTypes(cfg.Serve.Events)
+
 	// Start listening to events
 	eventCh, err := listener.StreamEvents(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to start listener: %w", err)
 	}
 
-	log.Info("🎧 Listening for patchset-created events...")
+	log.Infof("🎧 Listening for %s events...", strings.Join(cfg.Serve.Events, ", "))
 	log.Info("Ready to process reviews")
 	fmt.Println("")
 
@@ -135,51 +215,15 @@ func runServe(cmd *cobra.Command, args []string) error {
 				return nil
 			}
 
-			if !filter.ShouldProcess(event) {
-				log.Debugf("Filtered out: %s", event.Type)
-				continue
-			}
-
-			// Validate event has required fields
-			if event.Change == nil || event.PatchSet == nil {
-				log.Warnf("Event missing required fields, skipping")
-				continue
+			if !dispatcher.Dispatch(ctx, event) {
+				log.Debugf("No handler for event: %s", event.Type)
 			}
 
-			// Convert event to task
-			task := queue.Task{
-				ID:             fmt.Sprintf("%s-%d-%d", event.Change.Project, event.Change.Number, event.PatchSet.Number),
-				Project:        event.Change.Project,
-				ChangeNumber:   event.Change.Number,
-				PatchsetNumber: event.PatchSet.Number,
-				Subject:        event.Change.Subject,
-				CreatedAt:      time.Now(),
+			// Only a dispatched event counts as processed
+			if err := watermark.Advance(event.EventCreatedOn); err != nil {
+				log.Warnf("Failed to persist event watermark: %v", err)
 			}
 
-			if err := q.Push(task); err != nil {
-				if errors.Is(err, queue.ErrQueueFull) {
-					log.Warnf("Queue full, dropping task: %s", task.ID)
-				} else if errors.Is(err, queue.ErrObsoleteTask) {
-					log.Debugf("Task superseded by newer patchset, dropping: %s", task.ID)
-				} else {
-					// Already queued (duplicate)
-					log.Debugf("Task already queued: %s", task.ID)
-				}
-				continue
-			}
-
-			// Truncate subject for display
-			subject := task.Subject
-			if len(subject) > 60 {
-				subject = subject[:60] + "..."
-			}
-
-			log.Infof("📥 Queued: %s #%d/%d - %s",
-				event.Change.Project,
-				event.Change.Number,
-				event.PatchSet.Number,
-				subject)
-
 		case <-ctx.Done():
 			log.Info("Context cancelled, shutting down...")
 
@@ -196,8 +240,114 @@ func runServe(cmd *cobra.Command, args []string) error {
 	}
 }
 
+// requeuePollInterval is how often serve mode checks for requeued dead letters
+const requeuePollInterval = 10 * time.Second
+
+// pollRequeued pushes tasks handed back from the dead-letter store
+func pollRequeued(ctx context.Context, log *logger.Logger, deadLetters *queue.DeadLetterStore, q *queue.Queue) {
+	ticker := time.NewTicker(requeuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		tasks, err := deadLetters.TakeRequeued()
+		if err != nil {
+			log.Warnf("Failed to read requeued tasks: %v", err)
+		}
+		for _, task := range tasks {
+			if err := q.Push(task); err != nil {
+				log.Warnf("Failed to requeue %s: %v", task.ID, err)
+				continue
+			}
+			log.Infof("📥 Requeued: %s #%d/%d", task.Project, task.ChangeNumber, task.PatchsetNumber)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// retryPolicies converts the configured retry policies for the worker pool
+func retryPolicies(cfg *config.Config) map[string]worker.RetryPolicy {
+	policies := make(map[string]worker.RetryPolicy, len(cfg.Serve.Retry))
+	for class, policy := range cfg.Serve.Retry {
+		policies[class] = worker.RetryPolicy{
+			MaxAttempts: policy.MaxAttempts,
+			BaseDelay:   time.Duration(policy.BaseDelay) * time.Second,
+			MaxDelay:    time.Duration(policy.MaxDelay) * time.Second,
+		}
+	}
+	return policies
+}
+
+// newEventTransport creates the SSH transport selected by gerrit.ssh.client
+func newEventTransport(cfg *config.Config) (events.Transport, error) {
+	if cfg.Gerrit.SSH.Client != "native" {
+		return events.NewExecTransport(cfg.Gerrit.SSHAlias), nil
+	}
+	return events.NewSSHTransport(events.SSHConfig{
+		Host:           cfg.Gerrit.SSH.Host,
+		Port:           cfg.Gerrit.SSH.Port,
+		User:           cfg.Gerrit.SSH.User,
+		KeyFile:        cfg.Gerrit.SSH.KeyFile,
+		KnownHostsFile: cfg.Gerrit.SSH.KnownHosts,
+		Keepalive:      time.Duration(cfg.Gerrit.SSH.Keepalive) * time.Second,
+	})
+}
+
+// sshFailureHint suggests a fix for a failed SSH connection test
+func sshFailureHint(cfg *config.Config, err error) string {
+	if cfg.Gerrit.SSH.Client != "native" {
+		return fmt.Sprintf("Ensure SSH alias '%s' is configured in ~/.ssh/config", cfg.Gerrit.SSHAlias)
+	}
+
+	var connErr *events.ConnectError
+	if !errors.As(err, &connErr) {
+		return "Check the gerrit.ssh settings"
+	}
+	switch connErr.Stage {
+	case events.StageDial:
+		return "Check gerrit.ssh.host and gerrit.ssh.port"
+	case events.StageHostKey:
+		return fmt.Sprintf("Add the Gerrit host key to %s (ssh-keyscan -p %d %s)", cfg.Gerrit.SSH.KnownHosts, cfg.Gerrit.SSH.Port, cfg.Gerrit.SSH.Host)
+	case events.StageAuth:
+		return fmt.Sprintf("Ensure the public key of %s is registered for Gerrit user '%s'", cfg.Gerrit.SSH.KeyFile, cfg.Gerrit.SSH.User)
+	default:
+		return "Ensure the Gerrit SSH daemon is reachable and the user may run gerrit commands"
+	}
+}
+
+// checkReviewBackend verifies that a review CLI is installed
+func checkReviewBackend(ctx context.Context, log *logger.Logger, cfg *config.Config, reviewCLI string) error {
+	backend, err := reviewer.NewBackend(reviewCLI, cfg)
+	if err != nil {
+		return err
+	}
+	log.Infof("  Checking %s CLI...", reviewCLI)
+	backendPath, err := exec.LookPath(backend.Executable())
+	if err != nil {
+		log.Warnf("  ✗ %s not found: %v", backend.Executable(), err)
+		return fmt.Errorf("%s CLI not found in PATH: %w", reviewCLI, err)
+	}
+	if versionArgs := backend.VersionArgs(); len(versionArgs) > 0 {
+		reviewCmd := exec.CommandContext(ctx, backendPath, versionArgs...)
+		log.Debugf("  [preflight] executing command: %s", strings.Join(reviewCmd.Args, " "))
+		output, err := reviewCmd.CombinedOutput()
+		if err != nil {
+			log.Warnf("  ✗ %s version check failed: %v", reviewCLI, err)
+			return fmt.Errorf("%s CLI version check failed: %w", reviewCLI, err)
+		}
+		log.Infof("  ✓ %s CLI found: %s", reviewCLI, string(output))
+	} else {
+		log.Infof("  ✓ %s CLI found: %s", reviewCLI, backendPath)
+	}
+	return nil
+}
+
 // runPreflightChecks runs startup checks before starting serve mode
-func runPreflightChecks(log *logger.Logger, cfg *config.Config) error {
+func runPreflightChecks(log *logger.Logger, cfg *config.Config, transport events.Transport) error {
 	cliCmd := "gerrit-cli"
 	log.Debugf("  [preflight] resolving executable: %s", cliCmd)
 
@@ -242,28 +392,19 @@ func runPreflightChecks(log *logger.Logger, cfg *config.Config) error {
 
 	// 3. Test SSH connection to Gerrit
 	log.Info("  Testing SSH connection to Gerrit...")
-	sshCmd := exec.CommandContext(ctx, "ssh", cfg.Gerrit.SSHAlias, "gerrit", "version")
-	log.Debugf("  [preflight] executing command: %s", strings.Join(sshCmd.Args, " "))
-	if output, err := sshCmd.CombinedOutput(); err != nil {
+	log.Debugf("  [preflight] executing command on %s: gerrit version", transport)
+	if output, err := transport.Run(ctx, "gerrit version"); err != nil {
 		log.Warnf("  ✗ SSH test failed: %v", err)
 		log.Warnf("  Output: %s", string(output))
-		return fmt.Errorf("SSH connection test failed: %w\nEnsure SSH alias '%s' is configured in ~/.ssh/config", err, cfg.Gerrit.SSHAlias)
+		return fmt.Errorf("SSH connection test failed: %w\n%s", err, sshFailureHint(cfg, err))
 	}
 	log.Info("  ✓ SSH connection test passed")
 
-	// 4. Check configured review CLI
-	reviewCLI := strings.ToLower(strings.TrimSpace(cfg.Review.CLI))
-	if reviewCLI == "" {
-		reviewCLI = "claude"
-	}
-	log.Infof("  Checking %s CLI...", reviewCLI)
-	reviewCmd := exec.CommandContext(ctx, reviewCLI, "--version")
-	log.Debugf("  [preflight] executing command: %s", strings.Join(reviewCmd.Args, " "))
-	if output, err := reviewCmd.CombinedOutput(); err != nil {
-		log.Warnf("  ✗ %s not found: %v", reviewCLI, err)
-		return fmt.Errorf("%s CLI not found in PATH: %w", reviewCLI, err)
-	} else {
-		log.Infof("  ✓ %s CLI found: %s", reviewCLI, string(output))
+	// 4. Check the review CLIs used globally and by projects entries
+	for _, reviewCLI := range cfg.ReviewBackends() {
+		if err := checkReviewBackend(ctx, log, cfg, reviewCLI); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/events"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/queue"
+)
+
+// eventHandlers holds the serve mode handlers for each Gerrit event type
+type eventHandlers struct {
+	log     *logger.Logger
+	queue   *queue.Queue
+	filter  *events.Filter
+	trigger string // Comment command requesting a review; empty disables
+	botUser string // Account the reviewer posts as; its own comments never trigger
+}
+
+// newEventDispatcher registers a handler for every supported event type.
+// Which of them actually arrive is decided by the serve.events subscription.
+func newEventDispatcher(log *logger.Logger, cfg *config.Config, q *queue.Queue, filter *events.Filter) *events.Dispatcher {
+	h := &eventHandlers{
+		log:     log,
+		queue:   q,
+		filter:  filter,
+		trigger: cfg.Serve.Trigger,
+		botUser: cfg.Gerrit.HTTPUser,
+	}
+
+	d := events.NewDispatcher()
+	d.Handle(events.TypePatchsetCreated, h.patchsetCreated)
+	d.Handle(events.TypeCommentAdded, h.commentAdded)
+	d.Handle(events.TypeChangeAbandoned, h.changeClosed)
+	d.Handle(events.TypeChangeMerged, h.changeClosed)
+	d.Handle(events.TypeWIPStateChanged, h.wipStateChanged)
+	d.Handle(events.TypePrivateStateChanged, h.privateStateChanged)
+	d.Handle(events.TypeReviewerAdded, h.reviewerAdded)
+	d.Handle(events.TypeRefUpdated, h.refUpdated)
+	return d
+}
+
+// newEventFilter builds the serve.filter rules and the filter rules of the
+// projects entries. Changed files for the file rules are listed through the
+// REST API.
+func newEventFilter(cfg *config.Config, client *gerrit.Client) (*events.Filter, error) {
+	filter, err := events.NewFilter(eventFilterConfig(cfg.Serve.Filter))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, project := range cfg.Projects {
+		name := fmt.Sprintf("projects[%d]", i)
+		rules, err := events.NewFilter(eventFilterConfig(project.Filter))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := filter.AddScoped(name, project.Project, project.Branches, rules); err != nil {
+			return nil, err
+		}
+	}
+
+	filter.SetFilesLookup(func(ctx context.Context, event events.Event) ([]string, error) {
+		files, err := client.GetRevisionFiles(ctx, strconv.Itoa(event.Change.Number), strconv.Itoa(event.PatchSet.Number), "")
+		if err != nil {
+			return nil, err
+		}
+		paths := make([]string, 0, len(files))
+		for path := range files {
+			// Skip magic files such as /COMMIT_MSG and /MERGE_LIST
+			if !strings.HasPrefix(path, "/") {
+				paths = append(paths, path)
+			}
+		}
+		return paths, nil
+	})
+
+	return filter, nil
+}
+
+// eventFilterConfig converts configured filter rules to the events form
+func eventFilterConfig(rules config.FilterConfig) events.FilterConfig {
+	return events.FilterConfig{
+		Projects:         rules.Projects,
+		Exclude:          rules.Exclude,
+		Branches:         rules.Branches,
+		ExcludeBranches:  rules.ExcludeBranches,
+		Owners:           rules.Owners,
+		ExcludeOwners:    rules.ExcludeOwners,
+		Uploaders:        rules.Uploaders,
+		ExcludeUploaders: rules.ExcludeUploaders,
+		Hashtags:         rules.Hashtags,
+		ExcludeHashtags:  rules.ExcludeHashtags,
+		Topics:           rules.Topics,
+		ExcludeTopics:    rules.ExcludeTopics,
+		Files:            rules.Files,
+		ExcludeFiles:     rules.ExcludeFiles,
+		SkipWIP:          rules.SkipWIP,
+		SkipPrivate:      rules.SkipPrivate,
+		SkipKinds:        rules.SkipKinds,
+	}
+}
+
+// patchsetCreated queues a review of the new patchset
+func (h *eventHandlers) patchsetCreated(ctx context.Context, event events.Event) {
+	// Validate event has required fields
+	if event.Change == nil || event.PatchSet == nil {
+		h.log.Warnf("Event missing required fields, skipping")
+		return
+	}
+
+	if rule := h.filter.Check(ctx, event); rule != "" {
+		h.log.Infof("⏭️  Skipped %s #%d/%d: %s", event.Change.Project, event.Change.Number,
+			event.PatchSet.Number, rule)
+		return
+	}
+
+	// Convert event to task
+	task := queue.Task{
+		ID:             fmt.Sprintf("%s-%d-%d", event.Change.Project, event.Change.Number, event.PatchSet.Number),
+		Project:        event.Change.Project,
+		Branch:         event.Change.Branch,
+		ChangeNumber:   event.Change.Number,
+		PatchsetNumber: event.PatchSet.Number,
+		Subject:        event.Change.Subject,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := h.queue.Push(task); err != nil {
+		if errors.Is(err, queue.ErrQueueFull) {
+			h.log.Warnf("Queue full, dropping task: %s", task.ID)
+		} else if errors.Is(err, queue.ErrObsoleteTask) {
+			h.log.Debugf("Task superseded by newer patchset, dropping: %s", task.ID)
+		} else {
+			// Already queued (duplicate)
+			h.log.Debugf("Task already queued: %s", task.ID)
+		}
+		return
+	}
+
+	// Truncate subject for display
+	subject := task.Subject
+	if len(subject) > 60 {
+		subject = subject[:60] + "..."
+	}
+
+	h.log.Infof("📥 Queued: %s #%d/%d - %s",
+		event.Change.Project,
+		event.Change.Number,
+		event.PatchSet.Number,
+		subject)
+}
+
+// commentAdded queues an on-demand review when the comment contains the
+// trigger command
+func (h *eventHandlers) commentAdded(ctx context.Context, event events.Event) {
+	if event.Change == nil || event.PatchSet == nil || !h.filter.ShouldProcess(event) {
+		return
+	}
+	if event.Author != nil && event.Author.Username != "" && event.Author.Username == h.botUser {
+		return
+	}
+
+	trigger, ok := events.ParseTrigger(event.Comment, h.trigger)
+	if !ok {
+		h.log.Debugf("Comment on %s #%d/%d by %s", event.Change.Project, event.Change.Number,
+			event.PatchSet.Number, accountName(event.Author))
+		return
+	}
+	if len(trigger.Unknown) > 0 {
+		h.log.Warnf("Ignoring unknown %s options from %s: %v", h.trigger, accountName(event.Author), trigger.Unknown)
+	}
+	if event.Change.Status == "MERGED" || event.Change.Status == "ABANDONED" {
+		h.log.Infof("Ignoring %s on closed change %s #%d", h.trigger, event.Change.Project, event.Change.Number)
+		return
+	}
+
+	task := queue.Task{
+		ID: fmt.Sprintf("%s-%d-%d-ondemand-%d", event.Change.Project, event.Change.Number,
+			event.PatchSet.Number, time.Now().Unix()),
+		Project:        event.Change.Project,
+		Branch:         event.Change.Branch,
+		ChangeNumber:   event.Change.Number,
+		PatchsetNumber: event.PatchSet.Number,
+		Subject:        event.Change.Subject,
+		CreatedAt:      time.Now(),
+		OnDemand:       true,
+		RequestedBy:    accountName(event.Author),
+		Focus:          trigger.Focus,
+		Incremental:    trigger.Incremental,
+	}
+
+	if err := h.queue.Push(task); err != nil {
+		h.log.Warnf("Could not queue requested review %s: %v", task.ID, err)
+		return
+	}
+
+	h.log.Infof("📥 Queued on request of %s: %s #%d/%d", task.RequestedBy,
+		task.Project, task.ChangeNumber, task.PatchsetNumber)
+}
+
+// changeClosed stops reviewing abandoned and merged changes. It is not
+// filtered: cancelling work for an unwatched project is a no-op.
+func (h *eventHandlers) changeClosed(ctx context.Context, event events.Event) {
+	if event.Change == nil {
+		return
+	}
+	if n := h.queue.CancelChange(event.Change.Project, event.Change.Number); n > 0 {
+		h.log.Infof("🛑 %s: cancelled %d running review(s) of %s #%d",
+			event.Type, n, event.Change.Project, event.Change.Number)
+	}
+}
+
+// wipStateChanged queues a review of the current patchset when a change
+// is marked ready and skip_wip kept it from being reviewed on upload
+func (h *eventHandlers) wipStateChanged(ctx context.Context, event events.Event) {
+	if event.Change == nil || !h.filter.ShouldProcess(event) {
+		return
+	}
+	h.log.Debugf("%s #%d work-in-progress=%t (by %s)", event.Change.Project, event.Change.Number,
+		event.Change.WIP, accountName(event.Changer))
+
+	if !event.Change.WIP && event.PatchSet != nil && h.filter.SkipsWIP(event) {
+		h.patchsetCreated(ctx, event)
+	}
+}
+
+// privateStateChanged queues a review of the current patchset when a
+// change is made public and skip_private kept it from being reviewed on
+// upload
+func (h *eventHandlers) privateStateChanged(ctx context.Context, event events.Event) {
+	if event.Change == nil || !h.filter.ShouldProcess(event) {
+		return
+	}
+	h.log.Debugf("%s #%d private=%t (by %s)", event.Change.Project, event.Change.Number,
+		event.Change.Private, accountName(event.Changer))
+
+	if !event.Change.Private && event.PatchSet != nil && h.filter.SkipsPrivate(event) {
+		h.patchsetCreated(ctx, event)
+	}
+}
+
+func (h *eventHandlers) reviewerAdded(ctx context.Context, event events.Event) {
+	if event.Change == nil || !h.filter.ShouldProcess(event) {
+		return
+	}
+	h.log.Debugf("%s #%d: reviewer %s added by %s", event.Change.Project, event.Change.Number,
+		accountName(event.Reviewer), accountName(event.Adder))
+}
+
+func (h *eventHandlers) refUpdated(ctx context.Context, event events.Event) {
+	if event.RefUpdate == nil || !h.filter.ShouldProcess(event) {
+		return
+	}
+	h.log.Debugf("%s: %s updated %s -> %s", event.RefUpdate.Project, event.RefUpdate.RefName,
+		event.RefUpdate.OldRev, event.RefUpdate.NewRev)
+}
+
+// accountName returns a printable name for an event account
+func accountName(account *events.Account) string {
+	switch {
+	case account == nil:
+		return "unknown"
+	case account.Username != "":
+		return account.Username
+	case account.Name != "":
+		return account.Name
+	default:
+		return account.Email
+	}
+}
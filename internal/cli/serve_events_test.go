@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/events"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/queue"
+)
+
+func TestEventDispatcher_QueuesAndCancels(t *testing.T) {
+	q := queue.NewQueue(10, queue.QueueConfig{})
+	filter, err := events.NewFilter(events.FilterConfig{Exclude: []string{"excluded"}})
+	if err != nil {
+		t.Fatalf("NewFilter failed: %v", err)
+	}
+	d := newEventDispatcher(logger.Get(), &config.Config{}, q, filter)
+	ctx := context.Background()
+
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypePatchsetCreated,
+		Change:   &events.Change{Project: "platform/app", Number: 42, Subject: "Add feature"},
+		PatchSet: &events.PatchSet{Number: 2},
+	})
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypePatchsetCreated,
+		Change:   &events.Change{Project: "excluded", Number: 7},
+		PatchSet: &events.PatchSet{Number: 1},
+	})
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypeCommentAdded,
+		Change:   &events.Change{Project: "platform/app", Number: 42},
+		PatchSet: &events.PatchSet{Number: 2},
+		Comment:  "Looks good",
+	})
+
+	if q.Size() != 1 {
+		t.Fatalf("Expected 1 queued task, got %d", q.Size())
+	}
+
+	task, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("Pop failed: %v", err)
+	}
+	if task.ID != "platform/app-42-2" {
+		t.Errorf("Expected task platform/app-42-2, got %s", task.ID)
+	}
+
+	taskCtx := q.Start(ctx, task)
+	d.Dispatch(ctx, events.Event{
+		Type:   events.TypeChangeMerged,
+		Change: &events.Change{Project: "platform/app", Number: 42},
+	})
+	if taskCtx.Err() == nil {
+		t.Errorf("expected change-merged to cancel the running review")
+	}
+}
+
+func TestEventDispatcher_CommentTriggerQueuesOnDemandReview(t *testing.T) {
+	q := queue.NewQueue(10, queue.QueueConfig{})
+	cfg := &config.Config{
+		Gerrit: config.GerritConfig{HTTPUser: "ai-bot"},
+		Serve:  config.ServeConfig{Trigger: "/ai-review"},
+	}
+	filter, err := events.NewFilter(events.FilterConfig{})
+	if err != nil {
+		t.Fatalf("NewFilter failed: %v", err)
+	}
+	d := newEventDispatcher(logger.Get(), cfg, q, filter)
+	ctx := context.Background()
+
+	change := &events.Change{Project: "platform/app", Number: 42, Status: "NEW"}
+	patchSet := &events.PatchSet{Number: 2}
+
+	// An automatic review of the same patchset is already pending
+	d.Dispatch(ctx, events.Event{Type: events.TypePatchsetCreated, Change: change, PatchSet: patchSet})
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypeCommentAdded,
+		Change:   change,
+		PatchSet: patchSet,
+		Author:   &events.Account{Username: "alice"},
+		Comment:  "Patch Set 2:\n\n/ai-review focus:security incremental",
+	})
+	// The bot quoting the trigger must not queue another review
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypeCommentAdded,
+		Change:   change,
+		PatchSet: patchSet,
+		Author:   &events.Account{Username: "ai-bot"},
+		Comment:  "/ai-review",
+	})
+
+	if q.Size() != 2 {
+		t.Fatalf("Expected 2 queued tasks, got %d", q.Size())
+	}
+
+	if _, err := q.Pop(ctx); err != nil {
+		t.Fatalf("Pop failed: %v", err)
+	}
+	task, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("Pop failed: %v", err)
+	}
+	if !task.OnDemand || task.RequestedBy != "alice" || !task.Incremental {
+		t.Errorf("Expected on-demand incremental task requested by alice, got %+v", task)
+	}
+	if len(task.Focus) != 1 || task.Focus[0] != "security" {
+		t.Errorf("Expected focus [security], got %v", task.Focus)
+	}
+}
+
+func TestEventDispatcher_ProjectFilterAndBranch(t *testing.T) {
+	cfg := &config.Config{
+		Projects: []config.ProjectConfig{
+			{Project: "monorepo", Branches: []string{"main"}, Filter: config.FilterConfig{SkipWIP: true}},
+		},
+	}
+	filter, err := newEventFilter(cfg, gerrit.NewClient("http://127.0.0.1:0", "user", "pass"))
+	if err != nil {
+		t.Fatalf("newEventFilter failed: %v", err)
+	}
+	q := queue.NewQueue(10, queue.QueueConfig{})
+	d := newEventDispatcher(logger.Get(), cfg, q, filter)
+	ctx := context.Background()
+
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypePatchsetCreated,
+		Change:   &events.Change{Project: "monorepo", Branch: "main", Number: 1, WIP: true},
+		PatchSet: &events.PatchSet{Number: 1},
+	})
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypePatchsetCreated,
+		Change:   &events.Change{Project: "monorepo", Branch: "dev", Number: 2, WIP: true},
+		PatchSet: &events.PatchSet{Number: 1},
+	})
+
+	if q.Size() != 1 {
+		t.Fatalf("Expected 1 queued task, got %d", q.Size())
+	}
+	task, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("Pop failed: %v", err)
+	}
+	if task.ChangeNumber != 2 || task.Branch != "dev" {
+		t.Errorf("Expected change 2 on dev, got %d on %q", task.ChangeNumber, task.Branch)
+	}
+}
+
+func TestEventDispatcher_ReviewsWhenSkippedStateEnds(t *testing.T) {
+	filter, err := events.NewFilter(events.FilterConfig{SkipWIP: true, SkipPrivate: true})
+	if err != nil {
+		t.Fatalf("NewFilter failed: %v", err)
+	}
+	q := queue.NewQueue(10, queue.QueueConfig{})
+	d := newEventDispatcher(logger.Get(), &config.Config{}, q, filter)
+	ctx := context.Background()
+
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypePatchsetCreated,
+		Change:   &events.Change{Project: "app", Number: 1, WIP: true},
+		PatchSet: &events.PatchSet{Number: 1},
+	})
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypePatchsetCreated,
+		Change:   &events.Change{Project: "app", Number: 2, Private: true},
+		PatchSet: &events.PatchSet{Number: 3},
+	})
+	if q.Size() != 0 {
+		t.Fatalf("Expected WIP and private patchsets to be skipped, got %d queued", q.Size())
+	}
+
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypeWIPStateChanged,
+		Change:   &events.Change{Project: "app", Number: 1},
+		PatchSet: &events.PatchSet{Number: 1},
+	})
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypePrivateStateChanged,
+		Change:   &events.Change{Project: "app", Number: 2},
+		PatchSet: &events.PatchSet{Number: 3},
+	})
+	// Becoming WIP again queues nothing
+	d.Dispatch(ctx, events.Event{
+		Type:     events.TypeWIPStateChanged,
+		Change:   &events.Change{Project: "app", Number: 3, WIP: true},
+		PatchSet: &events.PatchSet{Number: 1},
+	})
+
+	var ids []string
+	for q.Size() > 0 {
+		task, err := q.Pop(ctx)
+		if err != nil {
+			t.Fatalf("Pop failed: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+	if len(ids) != 2 || ids[0] != "app-1-1" || ids[1] != "app-2-3" {
+		t.Errorf("Expected reviews app-1-1 and app-2-3, got %v", ids)
+	}
+}
+
+func TestEventDispatcher_ReadyWithoutSkipWIPQueuesNothing(t *testing.T) {
+	filter, err := events.NewFilter(events.FilterConfig{})
+	if err != nil {
+		t.Fatalf("NewFilter failed: %v", err)
+	}
+	q := queue.NewQueue(10, queue.QueueConfig{})
+	d := newEventDispatcher(logger.Get(), &config.Config{}, q, filter)
+
+	// The WIP patchset was already reviewed on upload
+	d.Dispatch(context.Background(), events.Event{
+		Type:     events.TypeWIPStateChanged,
+		Change:   &events.Change{Project: "app", Number: 1},
+		PatchSet: &events.PatchSet{Number: 1},
+	})
+	if q.Size() != 0 {
+		t.Errorf("Expected no review without skip_wip, got %d queued", q.Size())
+	}
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/reviewer"
+	"github.com/spf13/cobra"
+)
+
+// skillCmd represents the skill command group
+var skillCmd = &cobra.Command{
+	Use:   "skill",
+	Short: "Inspect the review skill sent to the AI backend",
+	Long: `The review prompt is composed from the base skill (review.skill.base or the
+embedded SKILL.md), language fragments, configured fragments and fragments
+from the reviewed repository, rendered with the change metadata.`,
+}
+
+// skillRenderCmd prints the final review prompt of a change
+var skillRenderCmd = &cobra.Command{
+	Use:   "render <change-number> [patchset]",
+	Short: "Print the review prompt for a change",
+	Long: `Check out a patchset the way a review does and print the prompt the review
+backend would receive, without running it. The patchset defaults to the
+current one; project and branch overrides are resolved as for a review.
+
+Examples:
+  gerrit-reviewer skill render 12345
+  gerrit-reviewer skill render 12345 3 --focus security --incremental`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSkillRender,
+}
+
+func init() {
+	skillCmd.AddCommand(skillRenderCmd)
+
+	skillRenderCmd.Flags().StringSlice("focus", nil, "Render as an on-demand review focusing on these areas")
+	skillRenderCmd.Flags().Bool("incremental", false, "Render as an incremental on-demand review")
+}
+
+// runSkillRender executes the skill render command
+func runSkillRender(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := ConfigureGlobalLogger(cfg); err != nil {
+		return err
+	}
+
+	changeNumber, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid change number: %s", args[0])
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	client := cfg.Gerrit.NewClient()
+	change, err := client.GetChangeDetail(ctx, args[0], []string{"CURRENT_REVISION"})
+	if err != nil {
+		return fmt.Errorf("failed to get change %d: %w", changeNumber, err)
+	}
+
+	patchset := 0
+	if rev, ok := change.Revisions[change.CurrentRevision]; ok && rev != nil {
+		patchset = rev.Number
+	}
+	if len(args) > 1 {
+		if patchset, err = strconv.Atoi(args[1]); err != nil {
+			return fmt.Errorf("invalid patchset number: %s", args[1])
+		}
+	}
+	if patchset == 0 {
+		return fmt.Errorf("could not determine the current patchset of change %d", changeNumber)
+	}
+
+	req := reviewer.ReviewRequest{
+		Project:        change.Project,
+		Branch:         change.Branch,
+		ChangeNumber:   changeNumber,
+		PatchsetNumber: patchset,
+	}
+	focus, _ := cmd.Flags().GetStringSlice("focus")
+	incremental, _ := cmd.Flags().GetBool("incremental")
+	if len(focus) > 0 || incremental {
+		req.Options = reviewer.ReviewOptions{RequestedBy: cfg.Gerrit.HTTPUser, Focus: focus, Incremental: incremental}
+	}
+
+	prompt, skipReason, err := reviewer.NewReviewer(cfg).RenderPrompt(ctx, req)
+	if err != nil {
+		return err
+	}
+	if skipReason != "" {
+		fmt.Fprintf(os.Stderr, "Note: an automatic review of this patchset would be skipped: %s\n", skipReason)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), prompt)
+	return nil
+}
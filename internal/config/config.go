@@ -1,21 +1,33 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/pattern"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/policy"
 	"github.com/spf13/viper"
 )
 
 // Config holds all configuration for the gerrit-reviewer
 type Config struct {
-	Gerrit  GerritConfig
-	Git     GitConfig
-	Review  ReviewConfig
-	Serve   ServeConfig
-	Logging LoggingConfig
+	Gerrit   GerritConfig
+	Git      GitConfig
+	Review   ReviewConfig
+	Serve    ServeConfig
+	Logging  LoggingConfig
+	Projects []ProjectConfig // Per-project overrides, applied in order
 }
 
 // GerritConfig holds Gerrit connection settings
@@ -24,6 +36,34 @@ type GerritConfig struct {
 	HTTPUrl  string // Base URL for REST API (e.g., https://gerrit.stranity.dev)
 	HTTPUser string // Username for HTTP basic auth
 	HTTPPass string // Password for HTTP basic auth
+	SSH      SSHConfig
+
+	HTTPRetry     RetryConfig // Retries of rate-limited and failed REST requests
+	HTTPRateLimit float64     // REST requests per second; 0 = unlimited
+}
+
+// NewClient creates a Gerrit REST client with the configured credentials,
+// retry policy and rate limit
+func (g GerritConfig) NewClient() *gerrit.Client {
+	client := gerrit.NewClient(g.HTTPUrl, g.HTTPUser, g.HTTPPass)
+	client.SetRetryPolicy(gerrit.RetryPolicy{
+		MaxAttempts: g.HTTPRetry.MaxAttempts,
+		BaseDelay:   time.Duration(g.HTTPRetry.BaseDelay) * time.Second,
+		MaxDelay:    time.Duration(g.HTTPRetry.MaxDelay) * time.Second,
+	})
+	client.SetRateLimit(g.HTTPRateLimit)
+	return client
+}
+
+// SSHConfig selects how serve mode connects to Gerrit's SSH port
+type SSHConfig struct {
+	Client     string // "exec" (default): system ssh with SSHAlias; "native": in-process client
+	Host       string // Gerrit SSH host (native client)
+	Port       int    // Gerrit SSH port (native client, default: 29418)
+	User       string // Gerrit SSH user (native client)
+	KeyFile    string // Private key for public key authentication (native client)
+	KnownHosts string // known_hosts file used to verify the host key (native client)
+	Keepalive  int    // Seconds between keepalive requests; 0 disables them (native client)
 }
 
 // GitConfig holds Git repository settings
@@ -33,17 +73,211 @@ type GitConfig struct {
 
 // ReviewConfig holds review-specific settings
 type ReviewConfig struct {
-	CLI                        string // AI CLI backend to use: "claude" (default) or "codex"
-	ClaudeTimeout              int    // Timeout in seconds for Claude execution (default: 600)
-	ClaudeSkipPermissionsCheck bool   // Whether to bypass permission/sandbox checks in the selected CLI
+	CLI                        string               // AI CLI backend to use: "claude" (default), "codex", "command" or any registered backend
+	ClaudeTimeout              int                  // Timeout in seconds for Claude execution (default: 600)
+	ClaudeSkipPermissionsCheck bool                 // Whether to bypass permission/sandbox checks in the selected CLI
+	OutputMode                 string               // "agent" (default): AI posts via gerrit-cli; "structured": AI emits JSON, reviewer posts
+	Language                   string               // Language of the review output and bot messages: "zh-TW" (default) or "en"
+	Command                    CommandBackendConfig // Settings for the "command" backend
+	Size                       SizeConfig           // Limits above which a change is not reviewed
+	Skill                      SkillConfig          // Skill fragments composed into the review prompt
+	Label                      string               // Label the review vote is posted on (default: Code-Review)
+	Vote                       VoteConfig           // Range of the review vote and how it is enforced
+	RobotComments              RobotCommentsConfig  // Post structured review findings as robot comments
+	RepoConfig                 bool                 // Read overrides from .gerrit-reviewer.yaml in the reviewed patchset
+}
+
+// SizeConfig guards against reviewing oversized or generated changes
+type SizeConfig struct {
+	MaxFiles      int      // Skip changes touching more files (0 = unlimited)
+	MaxInsertions int      // Skip changes inserting more lines (0 = unlimited)
+	Ignore        []string // Generated, vendored and lock file patterns not counted or reviewed
+	Notify        bool     // Post Message when a change is skipped
+	Message       string   // Posted instead of a review; supports {{reason}}, {{files}}, {{insertions}}. Empty = default message in the review language
+}
+
+// SkillConfig selects the skill fragments composed into the review prompt:
+// the base skill, lang/<ext>.md from Dir for each changed file extension,
+// Fragments in order, then the *.md files under RepoDir in the patchset.
+// Every fragment is a text/template executed with the change metadata.
+type SkillConfig struct {
+	Base      string   // Base skill file; empty = embedded skills/code-review/SKILL.md
+	Dir       string   // Directory of language fragments and relative Base and Fragments paths
+	Fragments []string // Additional fragments, e.g. team or project conventions
+	RepoDir   string   // Directory in the reviewed repository with project fragments; empty disables
+}
+
+// VoteConfig bounds the Code-Review vote of the bot account. The vote is
+// further capped by the severities of the published comments.
+type VoteConfig struct {
+	Max     int    // Highest vote (at most +1; the bot never approves with +2)
+	Min     int    // Lowest vote (-2 to Max)
+	Enforce string // "clamp" (default): post the nearest allowed vote; "reject": refuse to post
+}
+
+// Policy returns the vote policy described by v
+func (v VoteConfig) Policy() policy.VotePolicy {
+	return policy.VotePolicy{Max: v.Max, Min: v.Min, Enforce: v.Enforce}
+}
+
+// validate checks the vote range; key prefixes error messages
+func (v VoteConfig) validate(key string) error {
+	if v.Max > policy.HardMax {
+		return fmt.Errorf("%s.max must be <= %+d", key, policy.HardMax)
+	}
+	if v.Min < -2 || v.Min > v.Max {
+		return fmt.Errorf("%s.min must be between -2 and %s.max", key, key)
+	}
+	switch v.Enforce {
+	case "", policy.Clamp, policy.Reject:
+		// valid
+	default:
+		return fmt.Errorf("%s.enforce must be one of: clamp, reject", key)
+	}
+	return nil
+}
+
+// apply returns v with the fields set in o replaced
+func (v VoteConfig) apply(o VoteConfigOverride) VoteConfig {
+	if o.Max != nil {
+		v.Max = *o.Max
+	}
+	if o.Min != nil {
+		v.Min = *o.Min
+	}
+	if o.Enforce != nil {
+		v.Enforce = strings.ToLower(strings.TrimSpace(*o.Enforce))
+	}
+	return v
+}
+
+// RobotCommentsConfig posts the findings of structured reviews as robot
+// comments, whose suggested fixes Gerrit offers to apply
+type RobotCommentsConfig struct {
+	Enabled bool   // Post findings as robot comments (review.output_mode structured only)
+	RobotID string // robot_id of the posted comments (default: gerrit-ai-review)
+}
+
+// defaultSizeIgnore lists generated, vendored and lock files ignored by default
+var defaultSizeIgnore = []string{
+	"**/vendor/**",
+	"**/node_modules/**",
+	"**/third_party/**",
+	"**/go.sum",
+	"**/package-lock.json",
+	"**/yarn.lock",
+	"**/pnpm-lock.yaml",
+	"**/Cargo.lock",
+	"**/poetry.lock",
+	"**/Gemfile.lock",
+	"**/composer.lock",
+	"**.pb.go",
+	"**_generated.go",
+	"**.min.js",
+	"**.min.css",
+}
+
+// RepoConfigFile is the in-repo file read when review.repo_config is set
+const RepoConfigFile = ".gerrit-reviewer.yaml"
+
+// ProjectConfig overrides settings for changes of matching projects and branches.
+//
+// Review settings are resolved in this order, later winning: built-in
+// defaults, the global config file and environment, the in-repo
+// RepoConfigFile, then every matching projects entry in order.
+type ProjectConfig struct {
+	Project        string   `mapstructure:"project"`  // Project name pattern, glob or "re:" regex
+	Branches       []string `mapstructure:"branches"` // Branch patterns (empty = all)
+	ReviewOverride `mapstructure:",squash"`
+	Filter         FilterConfig `mapstructure:"filter"` // Rules applied on top of serve.filter
+}
+
+// ReviewOverride holds the review settings a project overrides; unset
+// fields keep the inherited value. It is also the schema of RepoConfigFile.
+type ReviewOverride struct {
+	CLI      *string             `mapstructure:"cli"`
+	Timeout  *int                `mapstructure:"timeout"` // Seconds, overrides review.claude_timeout
+	Language *string             `mapstructure:"language"`
+	Label    *string             `mapstructure:"label"`
+	Size     SizeConfigOverride  `mapstructure:"size"`
+	Skill    SkillConfigOverride `mapstructure:"skill"`
+	Vote     VoteConfigOverride  `mapstructure:"vote"`
+}
+
+// VoteConfigOverride holds the review.vote settings a project overrides
+type VoteConfigOverride struct {
+	Max     *int    `mapstructure:"max"`
+	Min     *int    `mapstructure:"min"`
+	Enforce *string `mapstructure:"enforce"`
+}
+
+// SkillConfigOverride holds the review.skill settings a project overrides
+type SkillConfigOverride struct {
+	Base      *string  `mapstructure:"base"`
+	Fragments []string `mapstructure:"fragments"` // Replaces the global list
+	RepoDir   *string  `mapstructure:"repo_dir"`
+}
+
+// SizeConfigOverride holds the review.size settings a project overrides;
+// unset fields keep the global value.
+type SizeConfigOverride struct {
+	MaxFiles      *int     `mapstructure:"max_files"`
+	MaxInsertions *int     `mapstructure:"max_insertions"`
+	Ignore        []string `mapstructure:"ignore"` // Replaces the global list
+	Notify        *bool    `mapstructure:"notify"`
+	Message       *string  `mapstructure:"message"`
+}
+
+// CommandBackendConfig describes an arbitrary review CLI for the "command" backend
+type CommandBackendConfig struct {
+	Argv        []string // Program and arguments; supports {{prompt}}, {{prompt_file}}, {{output_file}}, {{workdir}}
+	Stdin       string   // "none" (default) or "prompt": write the prompt to stdin
+	Stdout      string   // "text" (default): stdout is the review; "jsonl": concatenate TextField of each JSON line
+	TextField   string   // JSON field holding text in jsonl mode (default: "text")
+	OutputFile  bool     // Read the final review from {{output_file}} (implied when argv references it)
+	VersionArgs []string // Arguments for the serve preflight check (empty skips the check)
 }
 
 // ServeConfig holds serve mode specific settings
 type ServeConfig struct {
-	Workers   int          // Number of concurrent workers
-	QueueSize int          // Maximum queue size
-	LazyMode  bool         // Keep only latest patchset per change in queue
-	Filter    FilterConfig // Event filtering rules
+	Workers   int                    // Number of concurrent workers
+	QueueSize int                    // Maximum queue size
+	LazyMode  bool                   // Keep only latest patchset per change in queue
+	Durable   bool                   // Persist the queue under git.repo_base_path so it survives restarts
+	Filter    FilterConfig           // Event filtering rules
+	Retry     map[string]RetryConfig // Retry policy per error class: rate_limited, timeout, git, other
+	Events    []string               // Gerrit stream-events types to subscribe to
+	Trigger   string                 // Comment command requesting a review, e.g. "/ai-review"; empty disables
+}
+
+// serveEventTypes are the stream-events types serve mode has handlers for
+var serveEventTypes = []string{
+	"patchset-created",
+	"comment-added",
+	"change-abandoned",
+	"change-merged",
+	"wip-state-changed",
+	"private-state-changed",
+	"reviewer-added",
+	"ref-updated",
+}
+
+// defaultServeEvents are subscribed to when serve.events is not set
+var defaultServeEvents = []string{"patchset-created", "comment-added", "change-abandoned", "change-merged"}
+
+// RetryConfig holds the retry policy for one error class
+type RetryConfig struct {
+	MaxAttempts int // Total attempts including the first; 1 disables retries
+	BaseDelay   int // Seconds before the first retry, doubled per attempt
+	MaxDelay    int // Upper bound in seconds for the retry delay
+}
+
+// retryDefaults are the built-in retry policies, keyed by error class
+var retryDefaults = map[string]RetryConfig{
+	"rate_limited": {MaxAttempts: 4, BaseDelay: 300, MaxDelay: 3600},
+	"timeout":      {MaxAttempts: 2, BaseDelay: 60, MaxDelay: 600},
+	"git":          {MaxAttempts: 4, BaseDelay: 30, MaxDelay: 600},
+	"other":        {MaxAttempts: 1, BaseDelay: 60, MaxDelay: 600},
 }
 
 // LoggingConfig holds logger behavior settings.
@@ -53,10 +287,153 @@ type LoggingConfig struct {
 	Verbose bool   // Explicit debug logging switch (same effect as level=debug/trace)
 }
 
-// FilterConfig holds event filtering rules
+// FilterConfig holds event filtering rules.
+// Patterns are globs ("*" within a path segment, "**" across) or, with a
+// "re:" prefix, regular expressions.
 type FilterConfig struct {
-	Projects []string // Projects to review (empty = all)
-	Exclude  []string // Projects to exclude
+	Projects         []string `mapstructure:"projects"`          // Projects to review (empty = all)
+	Exclude          []string `mapstructure:"exclude"`           // Projects to exclude
+	Branches         []string `mapstructure:"branches"`          // Branches to review (empty = all)
+	ExcludeBranches  []string `mapstructure:"exclude_branches"`  // Branches to exclude
+	Owners           []string `mapstructure:"owners"`            // Change owners to review (username, email or name; empty = all)
+	ExcludeOwners    []string `mapstructure:"exclude_owners"`    // Change owners to exclude
+	Uploaders        []string `mapstructure:"uploaders"`         // Patchset uploaders to review (empty = all)
+	ExcludeUploaders []string `mapstructure:"exclude_uploaders"` // Patchset uploaders to exclude
+	Hashtags         []string `mapstructure:"hashtags"`          // Review only changes with a matching hashtag (empty = all)
+	ExcludeHashtags  []string `mapstructure:"exclude_hashtags"`  // Skip changes with a matching hashtag
+	Topics           []string `mapstructure:"topics"`            // Review only changes with a matching topic (empty = all)
+	ExcludeTopics    []string `mapstructure:"exclude_topics"`    // Skip changes with a matching topic
+	Files            []string `mapstructure:"files"`             // Review only patchsets touching a matching file (empty = all)
+	ExcludeFiles     []string `mapstructure:"exclude_files"`     // Skip patchsets whose changed files all match
+	SkipWIP          bool     `mapstructure:"skip_wip"`          // Skip work-in-progress changes
+	SkipPrivate      bool     `mapstructure:"skip_private"`      // Skip private changes
+	SkipKinds        []string `mapstructure:"skip_kinds"`        // Patchset kinds to skip: TRIVIAL_REBASE, NO_CODE_CHANGE, NO_CHANGE, MERGE_FIRST_PARENT_UPDATE
+}
+
+// validate checks that every "re:" pattern compiles; key prefixes error messages
+func (f FilterConfig) validate(key string) error {
+	lists := map[string][]string{
+		"projects": f.Projects, "exclude": f.Exclude,
+		"branches": f.Branches, "exclude_branches": f.ExcludeBranches,
+		"owners": f.Owners, "exclude_owners": f.ExcludeOwners,
+		"uploaders": f.Uploaders, "exclude_uploaders": f.ExcludeUploaders,
+		"hashtags": f.Hashtags, "exclude_hashtags": f.ExcludeHashtags,
+		"topics": f.Topics, "exclude_topics": f.ExcludeTopics,
+		"files": f.Files, "exclude_files": f.ExcludeFiles,
+	}
+	for name, patterns := range lists {
+		if _, err := pattern.Compile(patterns); err != nil {
+			return fmt.Errorf("%s.%s: %w", key, name, err)
+		}
+	}
+	return nil
+}
+
+// validate checks the limits and ignore patterns; key prefixes error messages
+func (s SizeConfig) validate(key string) error {
+	if s.MaxFiles < 0 {
+		return fmt.Errorf("%s.max_files must be >= 0", key)
+	}
+	if s.MaxInsertions < 0 {
+		return fmt.Errorf("%s.max_insertions must be >= 0", key)
+	}
+	if _, err := pattern.Compile(s.Ignore); err != nil {
+		return fmt.Errorf("%s.ignore: %w", key, err)
+	}
+	return nil
+}
+
+// apply returns r with the fields set in o replaced
+func (r ReviewConfig) apply(o ReviewOverride) ReviewConfig {
+	if o.CLI != nil {
+		r.CLI = strings.ToLower(strings.TrimSpace(*o.CLI))
+	}
+	if o.Timeout != nil {
+		r.ClaudeTimeout = *o.Timeout
+	}
+	if o.Language != nil {
+		r.Language = normalizeLanguage(*o.Language)
+	}
+	if o.Label != nil {
+		r.Label = strings.TrimSpace(*o.Label)
+	}
+	r.Size = r.Size.apply(o.Size)
+	if o.Skill.Base != nil {
+		r.Skill.Base = strings.TrimSpace(*o.Skill.Base)
+	}
+	if o.Skill.Fragments != nil {
+		r.Skill.Fragments = o.Skill.Fragments
+	}
+	if o.Skill.RepoDir != nil {
+		r.Skill.RepoDir = strings.TrimSpace(*o.Skill.RepoDir)
+	}
+	r.Vote = r.Vote.apply(o.Vote)
+	return r
+}
+
+// validateOverride checks the settings o overrides; key prefixes error messages
+func (r ReviewConfig) validateOverride(key string, o ReviewOverride) error {
+	if o.CLI != nil && !isReviewBackend(strings.ToLower(strings.TrimSpace(*o.CLI))) {
+		return fmt.Errorf("%s.cli must be one of: %s", key, strings.Join(ReviewBackendNames(), ", "))
+	}
+	if o.CLI != nil && strings.EqualFold(strings.TrimSpace(*o.CLI), "command") && len(r.Command.Argv) == 0 {
+		return fmt.Errorf("%s.cli: review.command.argv is required for the command backend", key)
+	}
+	if o.Timeout != nil && *o.Timeout <= 0 {
+		return fmt.Errorf("%s.timeout must be > 0", key)
+	}
+	if o.Language != nil {
+		if _, ok := locale.Normalize(*o.Language); !ok {
+			return fmt.Errorf("%s.language must be one of: %s", key, strings.Join(locale.Supported(), ", "))
+		}
+	}
+	if o.Skill.RepoDir != nil && *o.Skill.RepoDir != "" && !filepath.IsLocal(*o.Skill.RepoDir) {
+		return fmt.Errorf("%s.skill.repo_dir must be a relative path inside the repository", key)
+	}
+	if o.Label != nil && !validLabel(strings.TrimSpace(*o.Label)) {
+		return fmt.Errorf("%s.label must be a label name such as Code-Review", key)
+	}
+	if err := r.Vote.apply(o.Vote).validate(key + ".vote"); err != nil {
+		return err
+	}
+	return r.Size.apply(o.Size).validate(key + ".size")
+}
+
+// apply returns s with the fields set in o replaced
+func (s SizeConfig) apply(o SizeConfigOverride) SizeConfig {
+	if o.MaxFiles != nil {
+		s.MaxFiles = *o.MaxFiles
+	}
+	if o.MaxInsertions != nil {
+		s.MaxInsertions = *o.MaxInsertions
+	}
+	if o.Ignore != nil {
+		s.Ignore = o.Ignore
+	}
+	if o.Notify != nil {
+		s.Notify = *o.Notify
+	}
+	if o.Message != nil {
+		s.Message = strings.TrimSpace(*o.Message)
+	}
+	return s
+}
+
+// labelPattern matches Gerrit label names
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// validLabel reports whether name is a valid Gerrit label name
+func validLabel(name string) bool {
+	return labelPattern.MatchString(name)
+}
+
+// normalizeLanguage maps lang to a supported language tag, leaving an
+// unsupported value for Validate to reject
+func normalizeLanguage(lang string) string {
+	if supported, ok := locale.Normalize(lang); ok {
+		return supported
+	}
+	return strings.TrimSpace(lang)
 }
 
 // LoadFromEnv loads configuration from environment variables
@@ -95,11 +472,34 @@ func bindEnvVars() {
 	viper.BindEnv("gerrit.http_url", "GERRIT_HTTP_URL")
 	viper.BindEnv("gerrit.http_user", "GERRIT_HTTP_USER")
 	viper.BindEnv("gerrit.http_password", "GERRIT_HTTP_PASSWORD")
+	viper.BindEnv("gerrit.http_retry.max_attempts", "GERRIT_HTTP_MAX_ATTEMPTS")
+	viper.BindEnv("gerrit.http_rate_limit", "GERRIT_HTTP_RATE_LIMIT")
+	viper.BindEnv("gerrit.ssh.client", "GERRIT_SSH_CLIENT")
+	viper.BindEnv("gerrit.ssh.host", "GERRIT_SSH_HOST")
+	viper.BindEnv("gerrit.ssh.port", "GERRIT_SSH_PORT")
+	viper.BindEnv("gerrit.ssh.user", "GERRIT_SSH_USER")
+	viper.BindEnv("gerrit.ssh.key_file", "GERRIT_SSH_KEY_FILE")
+	viper.BindEnv("gerrit.ssh.known_hosts", "GERRIT_SSH_KNOWN_HOSTS")
 	viper.BindEnv("git.repo_base_path", "GIT_REPO_BASE_PATH")
 	viper.BindEnv("review.cli", "REVIEW_CLI")
 	viper.BindEnv("review.claude_timeout", "CLAUDE_TIMEOUT")
 	viper.BindEnv("review.claude_skip_permissions", "CLAUDE_SKIP_PERMISSIONS")
+	viper.BindEnv("review.output_mode", "REVIEW_OUTPUT_MODE")
+	viper.BindEnv("review.language", "REVIEW_LANGUAGE")
+	viper.BindEnv("review.label", "REVIEW_LABEL")
+	viper.BindEnv("review.robot_comments.enabled", "REVIEW_ROBOT_COMMENTS")
+	viper.BindEnv("review.robot_comments.robot_id", "REVIEW_ROBOT_ID")
+	viper.BindEnv("review.size.max_files", "REVIEW_MAX_FILES")
+	viper.BindEnv("review.size.max_insertions", "REVIEW_MAX_INSERTIONS")
+	viper.BindEnv("review.repo_config", "REVIEW_REPO_CONFIG")
+	viper.BindEnv("review.vote.max", "REVIEW_VOTE_MAX")
+	viper.BindEnv("review.vote.min", "REVIEW_VOTE_MIN")
+	viper.BindEnv("review.vote.enforce", "REVIEW_VOTE_ENFORCE")
+	viper.BindEnv("review.skill.base", "REVIEW_SKILL")
+	viper.BindEnv("review.skill.dir", "REVIEW_SKILL_DIR")
 	viper.BindEnv("serve.lazy_mode", "SERVE_LAZY_MODE")
+	viper.BindEnv("serve.durable_queue", "SERVE_DURABLE_QUEUE")
+	viper.BindEnv("serve.trigger", "SERVE_TRIGGER")
 	viper.BindEnv("logging.level", "LOG_LEVEL")
 	viper.BindEnv("logging.file", "LOG_FILE")
 	viper.BindEnv("logging.verbose", "LOG_VERBOSE")
@@ -108,28 +508,126 @@ func bindEnvVars() {
 // initViperDefaults sets default values
 func initViperDefaults() {
 	viper.SetDefault("gerrit.ssh_alias", "gerrit-review")
+	viper.SetDefault("gerrit.ssh.client", "exec")
+	viper.SetDefault("gerrit.ssh.port", 29418)
+	viper.SetDefault("gerrit.ssh.known_hosts", "~/.ssh/known_hosts")
+	viper.SetDefault("gerrit.ssh.keepalive", 30)
+	setHTTPDefaults()
 	viper.SetDefault("git.repo_base_path", "/tmp/ai-review-repos")
 	viper.SetDefault("review.cli", "claude")
 	viper.SetDefault("review.claude_timeout", 600)
 	viper.SetDefault("review.claude_skip_permissions", false)
+	viper.SetDefault("review.output_mode", "agent")
+	viper.SetDefault("review.language", locale.Default)
+	viper.SetDefault("review.label", "Code-Review")
+	viper.SetDefault("review.robot_comments.enabled", false)
+	viper.SetDefault("review.robot_comments.robot_id", "gerrit-ai-review")
+	viper.SetDefault("review.command.stdin", "none")
+	viper.SetDefault("review.command.stdout", "text")
+	viper.SetDefault("review.command.text_field", "text")
+	viper.SetDefault("review.size.max_files", 0)
+	viper.SetDefault("review.size.max_insertions", 0)
+	viper.SetDefault("review.size.ignore", defaultSizeIgnore)
+	viper.SetDefault("review.size.notify", true)
+	viper.SetDefault("review.repo_config", false)
+	setVoteDefaults()
 	viper.SetDefault("serve.workers", 1)
 	viper.SetDefault("serve.queue_size", 100)
+	viper.SetDefault("serve.durable_queue", false)
+	for class, policy := range retryDefaults {
+		viper.SetDefault("serve.retry."+class+".max_attempts", policy.MaxAttempts)
+		viper.SetDefault("serve.retry."+class+".base_delay", policy.BaseDelay)
+		viper.SetDefault("serve.retry."+class+".max_delay", policy.MaxDelay)
+	}
 	viper.SetDefault("serve.lazy_mode", false)
+	viper.SetDefault("serve.events", defaultServeEvents)
+	viper.SetDefault("serve.trigger", "/ai-review")
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.file", "")
 	viper.SetDefault("logging.verbose", false)
 }
 
+// setVoteDefaults sets the defaults of review.vote, which gerrit-cli also reads
+func setVoteDefaults() {
+	viper.SetDefault("review.vote.max", 1)
+	viper.SetDefault("review.vote.min", -1)
+	viper.SetDefault("review.vote.enforce", policy.Clamp)
+}
+
+// setHTTPDefaults sets the defaults of the Gerrit REST client settings,
+// which gerrit-cli also reads
+func setHTTPDefaults() {
+	viper.SetDefault("gerrit.http_retry.max_attempts", 3)
+	viper.SetDefault("gerrit.http_retry.base_delay", 1)
+	viper.SetDefault("gerrit.http_retry.max_delay", 30)
+	viper.SetDefault("gerrit.http_rate_limit", 0)
+}
+
+// httpRetryFromViper reads gerrit.http_retry from the current Viper state
+func httpRetryFromViper() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: viper.GetInt("gerrit.http_retry.max_attempts"),
+		BaseDelay:   viper.GetInt("gerrit.http_retry.base_delay"),
+		MaxDelay:    viper.GetInt("gerrit.http_retry.max_delay"),
+	}
+}
+
+// LoadGerritConfig reads the Gerrit REST settings from Viper. gerrit-cli
+// uses it to create its client without loading the full reviewer
+// configuration.
+func LoadGerritConfig() GerritConfig {
+	setHTTPDefaults()
+	return GerritConfig{
+		HTTPUrl:       viper.GetString("gerrit.http_url"),
+		HTTPUser:      viper.GetString("gerrit.http_user"),
+		HTTPPass:      viper.GetString("gerrit.http_password"),
+		HTTPRetry:     httpRetryFromViper(),
+		HTTPRateLimit: viper.GetFloat64("gerrit.http_rate_limit"),
+	}
+}
+
+// voteConfigFromViper reads review.vote from the current Viper state
+func voteConfigFromViper() VoteConfig {
+	return VoteConfig{
+		Max:     viper.GetInt("review.vote.max"),
+		Min:     viper.GetInt("review.vote.min"),
+		Enforce: strings.ToLower(strings.TrimSpace(viper.GetString("review.vote.enforce"))),
+	}
+}
+
+// LoadVoteConfig reads and validates review.vote from Viper. gerrit-cli
+// uses it to enforce the vote policy without loading the full reviewer
+// configuration.
+func LoadVoteConfig() (VoteConfig, error) {
+	setVoteDefaults()
+	vote := voteConfigFromViper()
+	if err := vote.validate("review.vote"); err != nil {
+		return VoteConfig{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return vote, nil
+}
+
 // buildConfig constructs a Config from current Viper state
 func buildConfig() (*Config, error) {
 	initViperDefaults()
 
 	cfg := &Config{
 		Gerrit: GerritConfig{
-			SSHAlias: viper.GetString("gerrit.ssh_alias"),
-			HTTPUrl:  viper.GetString("gerrit.http_url"),
-			HTTPUser: viper.GetString("gerrit.http_user"),
-			HTTPPass: viper.GetString("gerrit.http_password"),
+			SSHAlias:      viper.GetString("gerrit.ssh_alias"),
+			HTTPUrl:       viper.GetString("gerrit.http_url"),
+			HTTPUser:      viper.GetString("gerrit.http_user"),
+			HTTPPass:      viper.GetString("gerrit.http_password"),
+			HTTPRetry:     httpRetryFromViper(),
+			HTTPRateLimit: viper.GetFloat64("gerrit.http_rate_limit"),
+			SSH: SSHConfig{
+				Client:     strings.ToLower(strings.TrimSpace(viper.GetString("gerrit.ssh.client"))),
+				Host:       strings.TrimSpace(viper.GetString("gerrit.ssh.host")),
+				Port:       viper.GetInt("gerrit.ssh.port"),
+				User:       strings.TrimSpace(viper.GetString("gerrit.ssh.user")),
+				KeyFile:    strings.TrimSpace(viper.GetString("gerrit.ssh.key_file")),
+				KnownHosts: strings.TrimSpace(viper.GetString("gerrit.ssh.known_hosts")),
+				Keepalive:  viper.GetInt("gerrit.ssh.keepalive"),
+			},
 		},
 		Git: GitConfig{
 			RepoBasePath: viper.GetString("git.repo_base_path"),
@@ -138,15 +636,64 @@ func buildConfig() (*Config, error) {
 			CLI:                        strings.ToLower(strings.TrimSpace(viper.GetString("review.cli"))),
 			ClaudeTimeout:              viper.GetInt("review.claude_timeout"),
 			ClaudeSkipPermissionsCheck: viper.GetBool("review.claude_skip_permissions"),
+			OutputMode:                 strings.ToLower(strings.TrimSpace(viper.GetString("review.output_mode"))),
+			Language:                   normalizeLanguage(viper.GetString("review.language")),
+			Label:                      strings.TrimSpace(viper.GetString("review.label")),
+			Command: CommandBackendConfig{
+				Argv:        viper.GetStringSlice("review.command.argv"),
+				Stdin:       strings.ToLower(strings.TrimSpace(viper.GetString("review.command.stdin"))),
+				Stdout:      strings.ToLower(strings.TrimSpace(viper.GetString("review.command.stdout"))),
+				TextField:   strings.TrimSpace(viper.GetString("review.command.text_field")),
+				OutputFile:  viper.GetBool("review.command.output_file"),
+				VersionArgs: viper.GetStringSlice("review.command.version_args"),
+			},
+			Size: SizeConfig{
+				MaxFiles:      viper.GetInt("review.size.max_files"),
+				MaxInsertions: viper.GetInt("review.size.max_insertions"),
+				Ignore:        viper.GetStringSlice("review.size.ignore"),
+				Notify:        viper.GetBool("review.size.notify"),
+				Message:       strings.TrimSpace(viper.GetString("review.size.message")),
+			},
+			RepoConfig: viper.GetBool("review.repo_config"),
+			Skill: SkillConfig{
+				Base:      strings.TrimSpace(viper.GetString("review.skill.base")),
+				Dir:       strings.TrimSpace(viper.GetString("review.skill.dir")),
+				Fragments: viper.GetStringSlice("review.skill.fragments"),
+				RepoDir:   strings.TrimSpace(viper.GetString("review.skill.repo_dir")),
+			},
+			Vote: voteConfigFromViper(),
+			RobotComments: RobotCommentsConfig{
+				Enabled: viper.GetBool("review.robot_comments.enabled"),
+				RobotID: strings.TrimSpace(viper.GetString("review.robot_comments.robot_id")),
+			},
 		},
 		Serve: ServeConfig{
 			Workers:   viper.GetInt("serve.workers"),
 			QueueSize: viper.GetInt("serve.queue_size"),
 			LazyMode:  viper.GetBool("serve.lazy_mode"),
+			Durable:   viper.GetBool("serve.durable_queue"),
 			Filter: FilterConfig{
-				Projects: viper.GetStringSlice("serve.filter.projects"),
-				Exclude:  viper.GetStringSlice("serve.filter.exclude"),
+				Projects:         viper.GetStringSlice("serve.filter.projects"),
+				Exclude:          viper.GetStringSlice("serve.filter.exclude"),
+				Branches:         viper.GetStringSlice("serve.filter.branches"),
+				ExcludeBranches:  viper.GetStringSlice("serve.filter.exclude_branches"),
+				Owners:           viper.GetStringSlice("serve.filter.owners"),
+				ExcludeOwners:    viper.GetStringSlice("serve.filter.exclude_owners"),
+				Uploaders:        viper.GetStringSlice("serve.filter.uploaders"),
+				ExcludeUploaders: viper.GetStringSlice("serve.filter.exclude_uploaders"),
+				Hashtags:         viper.GetStringSlice("serve.filter.hashtags"),
+				ExcludeHashtags:  viper.GetStringSlice("serve.filter.exclude_hashtags"),
+				Topics:           viper.GetStringSlice("serve.filter.topics"),
+				ExcludeTopics:    viper.GetStringSlice("serve.filter.exclude_topics"),
+				Files:            viper.GetStringSlice("serve.filter.files"),
+				ExcludeFiles:     viper.GetStringSlice("serve.filter.exclude_files"),
+				SkipWIP:          viper.GetBool("serve.filter.skip_wip"),
+				SkipPrivate:      viper.GetBool("serve.filter.skip_private"),
+				SkipKinds:        viper.GetStringSlice("serve.filter.skip_kinds"),
 			},
+			Retry:   make(map[string]RetryConfig, len(retryDefaults)),
+			Events:  eventTypesFromViper(),
+			Trigger: strings.TrimSpace(viper.GetString("serve.trigger")),
 		},
 		Logging: LoggingConfig{
 			Level:   strings.ToLower(strings.TrimSpace(viper.GetString("logging.level"))),
@@ -155,6 +702,18 @@ func buildConfig() (*Config, error) {
 		},
 	}
 
+	for class := range retryDefaults {
+		cfg.Serve.Retry[class] = RetryConfig{
+			MaxAttempts: viper.GetInt("serve.retry." + class + ".max_attempts"),
+			BaseDelay:   viper.GetInt("serve.retry." + class + ".base_delay"),
+			MaxDelay:    viper.GetInt("serve.retry." + class + ".max_delay"),
+		}
+	}
+
+	if err := viper.UnmarshalKey("projects", &cfg.Projects); err != nil {
+		return nil, fmt.Errorf("invalid configuration: projects: %w", err)
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
@@ -162,12 +721,53 @@ func buildConfig() (*Config, error) {
 	return cfg, nil
 }
 
+// eventTypesFromViper reads serve.events, falling back to the defaults when empty
+func eventTypesFromViper() []string {
+	var types []string
+	for _, eventType := range viper.GetStringSlice("serve.events") {
+		if eventType = strings.ToLower(strings.TrimSpace(eventType)); eventType != "" {
+			types = append(types, eventType)
+		}
+	}
+	if len(types) == 0 {
+		return append([]string(nil), defaultServeEvents...)
+	}
+	return types
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	if c.Gerrit.SSHAlias == "" {
 		return fmt.Errorf("gerrit.ssh_alias is required")
 	}
 
+	switch c.Gerrit.SSH.Client {
+	case "", "exec":
+	case "native":
+		if c.Gerrit.SSH.Host == "" {
+			return fmt.Errorf("gerrit.ssh.host is required when gerrit.ssh.client is native")
+		}
+		if c.Gerrit.SSH.User == "" {
+			return fmt.Errorf("gerrit.ssh.user is required when gerrit.ssh.client is native")
+		}
+		if c.Gerrit.SSH.KeyFile == "" {
+			return fmt.Errorf("gerrit.ssh.key_file is required when gerrit.ssh.client is native")
+		}
+		if c.Gerrit.SSH.KnownHosts == "" {
+			return fmt.Errorf("gerrit.ssh.known_hosts is required when gerrit.ssh.client is native")
+		}
+	default:
+		return fmt.Errorf("gerrit.ssh.client must be one of: exec, native")
+	}
+
+	if c.Gerrit.SSH.Port < 0 || c.Gerrit.SSH.Port > 65535 {
+		return fmt.Errorf("gerrit.ssh.port must be between 0 and 65535")
+	}
+
+	if c.Gerrit.SSH.Keepalive < 0 {
+		return fmt.Errorf("gerrit.ssh.keepalive must be >= 0")
+	}
+
 	if c.Gerrit.HTTPUrl == "" {
 		return fmt.Errorf("gerrit.http_url is required")
 	}
@@ -180,15 +780,122 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("gerrit.http_password is required")
 	}
 
+	if retry := c.Gerrit.HTTPRetry; retry.MaxAttempts < 0 || retry.BaseDelay < 0 || retry.MaxDelay < 0 {
+		return fmt.Errorf("gerrit.http_retry values must not be negative")
+	}
+	if c.Gerrit.HTTPRateLimit < 0 {
+		return fmt.Errorf("gerrit.http_rate_limit must be >= 0")
+	}
+
 	if c.Git.RepoBasePath == "" {
 		return fmt.Errorf("git.repo_base_path is required")
 	}
 
-	switch c.Review.CLI {
-	case "", "claude", "codex":
+	if c.Review.CLI != "" && !isReviewBackend(c.Review.CLI) {
+		return fmt.Errorf("review.cli must be one of: %s", strings.Join(ReviewBackendNames(), ", "))
+	}
+
+	if c.Review.CLI == "command" {
+		if len(c.Review.Command.Argv) == 0 || strings.TrimSpace(c.Review.Command.Argv[0]) == "" {
+			return fmt.Errorf("review.command.argv is required when review.cli is command")
+		}
+	}
+
+	switch c.Review.Command.Stdin {
+	case "", "none", "prompt":
 		// valid
 	default:
-		return fmt.Errorf("review.cli must be one of: claude, codex")
+		return fmt.Errorf("review.command.stdin must be one of: none, prompt")
+	}
+
+	switch c.Review.Command.Stdout {
+	case "", "text", "jsonl":
+		// valid
+	default:
+		return fmt.Errorf("review.command.stdout must be one of: text, jsonl")
+	}
+
+	switch c.Review.OutputMode {
+	case "", "agent", "structured":
+		// valid
+	default:
+		return fmt.Errorf("review.output_mode must be one of: agent, structured")
+	}
+
+	if c.Review.Language != "" {
+		if _, ok := locale.Normalize(c.Review.Language); !ok {
+			return fmt.Errorf("review.language must be one of: %s", strings.Join(locale.Supported(), ", "))
+		}
+	}
+
+	for class, policy := range c.Serve.Retry {
+		if _, ok := retryDefaults[class]; !ok {
+			return fmt.Errorf("serve.retry.%s: unknown error class (must be one of: git, other, rate_limited, timeout)", class)
+		}
+		if policy.MaxAttempts < 1 {
+			return fmt.Errorf("serve.retry.%s.max_attempts must be at least 1", class)
+		}
+		if policy.BaseDelay < 0 || policy.MaxDelay < 0 {
+			return fmt.Errorf("serve.retry.%s delays must not be negative", class)
+		}
+	}
+
+	if err := c.Serve.Filter.validate("serve.filter"); err != nil {
+		return err
+	}
+
+	if strings.ContainsAny(c.Serve.Trigger, " \t\n") {
+		return fmt.Errorf("serve.trigger must be a single word")
+	}
+
+	for _, eventType := range c.Serve.Events {
+		if !slices.Contains(serveEventTypes, eventType) {
+			return fmt.Errorf("serve.events: unknown event type %q (must be one of: %s)", eventType, strings.Join(serveEventTypes, ", "))
+		}
+	}
+
+	if err := c.Review.Size.validate("review.size"); err != nil {
+		return err
+	}
+
+	if c.Review.Skill.RepoDir != "" && !filepath.IsLocal(c.Review.Skill.RepoDir) {
+		return fmt.Errorf("review.skill.repo_dir must be a relative path inside the repository")
+	}
+
+	if c.Review.Label != "" && !validLabel(c.Review.Label) {
+		return fmt.Errorf("review.label must be a label name such as Code-Review")
+	}
+
+	if err := c.Review.Vote.validate("review.vote"); err != nil {
+		return err
+	}
+
+	if c.Review.RobotComments.Enabled {
+		if c.Review.OutputMode != "structured" {
+			return fmt.Errorf("review.robot_comments requires review.output_mode: structured")
+		}
+		if c.Review.RobotComments.RobotID == "" {
+			return fmt.Errorf("review.robot_comments.robot_id is required")
+		}
+	}
+
+	for i, project := range c.Projects {
+		if strings.TrimSpace(project.Project) == "" {
+			return fmt.Errorf("projects[%d].project is required", i)
+		}
+		if _, err := pattern.Compile([]string{project.Project}); err != nil {
+			return fmt.Errorf("projects[%d].project: %w", i, err)
+		}
+		if _, err := pattern.Compile(project.Branches); err != nil {
+			return fmt.Errorf("projects[%d].branches: %w", i, err)
+		}
+		key := fmt.Sprintf("projects[%d]", i)
+		if err := c.Review.validateOverride(key, project.ReviewOverride); err != nil {
+			return err
+		}
+		if err := project.Filter.validate(key + ".filter"); err != nil {
+			return err
+		}
 	}
 
 	switch c.Logging.Level {
@@ -201,6 +908,38 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+var (
+	reviewBackendsMu sync.RWMutex
+	reviewBackends   = map[string]bool{"claude": true, "codex": true, "command": true}
+)
+
+// RegisterReviewBackend marks name as a valid review.cli value.
+// The reviewer package calls this when a backend is registered.
+func RegisterReviewBackend(name string) {
+	reviewBackendsMu.Lock()
+	defer reviewBackendsMu.Unlock()
+	reviewBackends[strings.ToLower(strings.TrimSpace(name))] = true
+}
+
+// ReviewBackendNames returns the sorted list of valid review.cli values
+func ReviewBackendNames() []string {
+	reviewBackendsMu.RLock()
+	defer reviewBackendsMu.RUnlock()
+
+	names := make([]string, 0, len(reviewBackends))
+	for name := range reviewBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isReviewBackend(name string) bool {
+	reviewBackendsMu.RLock()
+	defer reviewBackendsMu.RUnlock()
+	return reviewBackends[name]
+}
+
 // LogVerbose reports whether debug-level logging should be enabled.
 func (c *Config) LogVerbose() bool {
 	if c.Logging.Verbose {
@@ -215,6 +954,131 @@ func (c *Config) LogVerbose() bool {
 	}
 }
 
+// StructuredOutput reports whether the AI backend must emit a structured
+// review result that the reviewer validates and posts itself.
+func (c *Config) StructuredOutput() bool {
+	return c.Review.OutputMode == "structured"
+}
+
+// ForChange returns a copy of the configuration with the review settings
+// for a change of project and branch resolved: repo (the in-repo
+// RepoConfigFile, may be nil) is applied first, then every matching
+// projects entry in order. An empty branch only matches entries without
+// branches.
+func (c *Config) ForChange(project, branch string, repo *ReviewOverride) *Config {
+	resolved := *c
+	if repo != nil {
+		resolved.Review = resolved.Review.apply(*repo)
+	}
+	for _, p := range c.Projects {
+		if p.Matches(project, branch) {
+			resolved.Review = resolved.Review.apply(p.ReviewOverride)
+		}
+	}
+	return &resolved
+}
+
+// ValidateRepoConfig checks overrides read from RepoConfigFile. The file
+// comes from the patchset under review, so it may only set the language and
+// skill.repo_dir: it may not point the skill at files outside the
+// repository, change the label or vote range the bot may cast on its own
+// change, nor skip or sabotage its review through the backend, timeout or
+// size limits.
+func (c *Config) ValidateRepoConfig(repo *ReviewOverride) error {
+	if repo.Skill.Base != nil || repo.Skill.Fragments != nil {
+		return fmt.Errorf("%s: skill.base and skill.fragments can only be set in the server configuration; use skill.repo_dir", RepoConfigFile)
+	}
+	if repo.Vote != (VoteConfigOverride{}) || repo.Label != nil {
+		return fmt.Errorf("%s: vote and label can only be set in the server configuration", RepoConfigFile)
+	}
+	if repo.CLI != nil || repo.Timeout != nil || !reflect.ValueOf(repo.Size).IsZero() {
+		return fmt.Errorf("%s: cli, timeout and size can only be set in the server configuration", RepoConfigFile)
+	}
+	return c.Review.validateOverride(RepoConfigFile, *repo)
+}
+
+// Matches reports whether the entry applies to a change of project and branch
+func (p ProjectConfig) Matches(project, branch string) bool {
+	projects, err := pattern.Compile([]string{p.Project})
+	if err != nil || projects.Match(project) == "" {
+		return false
+	}
+	if len(p.Branches) == 0 {
+		return true
+	}
+	branches, err := pattern.Compile(p.Branches)
+	return err == nil && branches.Match(branch) != ""
+}
+
+// ReviewBackends returns the review backends used globally or by a
+// projects entry, sorted.
+func (c *Config) ReviewBackends() []string {
+	seen := map[string]bool{c.Review.CLI: true}
+	for _, p := range c.Projects {
+		if p.CLI != nil {
+			seen[strings.ToLower(strings.TrimSpace(*p.CLI))] = true
+		}
+	}
+
+	var backends []string
+	for name := range seen {
+		if name == "" {
+			name = "claude"
+		}
+		if !slices.Contains(backends, name) {
+			backends = append(backends, name)
+		}
+	}
+	sort.Strings(backends)
+	return backends
+}
+
+// LoadRepoConfig reads RepoConfigFile from dir. It returns nil without an
+// error when the file does not exist.
+func LoadRepoConfig(dir string) (*ReviewOverride, error) {
+	path, err := ResolveInRepo(dir, RepoConfigFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", RepoConfigFile, err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", RepoConfigFile, err)
+	}
+
+	var override ReviewOverride
+	if err := v.Unmarshal(&override); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", RepoConfigFile, err)
+	}
+	return &override, nil
+}
+
+// ResolveInRepo returns the path of rel inside the repository at root with
+// all symlinks resolved. Repository content comes from the reviewed change,
+// so a symlink in it that leads outside root is an error.
+func ResolveInRepo(root, rel string) (string, error) {
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%q is not inside the repository", rel)
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(root, rel))
+	if err != nil {
+		return "", err
+	}
+	if inside, err := filepath.Rel(realRoot, path); err != nil || !filepath.IsLocal(inside) {
+		return "", fmt.Errorf("%q leads outside the repository", rel)
+	}
+	return path, nil
+}
+
 // GetGitURL returns the SSH URL for cloning a project
 func (c *Config) GetGitURL(project string) string {
 	return fmt.Sprintf("%s:%s", c.Gerrit.SSHAlias, project)
@@ -222,17 +1086,84 @@ func (c *Config) GetGitURL(project string) string {
 
 // GetRepoPath returns the local path for a project's repository
 func (c *Config) GetRepoPath(project string) string {
-	safeName := filepath.Base(project)
-	return filepath.Join(c.Git.RepoBasePath, safeName)
+	return ResolveRepoPath(c.Git.RepoBasePath, project)
+}
+
+// repoDirSuffix marks the leaf directory of a clone. It keeps a project
+// ("platform") from colliding with the parent directory of a nested
+// project ("platform/foo").
+const repoDirSuffix = ".repo"
+
+// ResolveRepoPath maps a Gerrit project name to its local clone directory.
+// The full project hierarchy is preserved:
+//
+//	platform/foo -> <basePath>/platform/foo.repo
+//	tools/foo    -> <basePath>/tools/foo.repo
+//
+// The mapping is reversible with ProjectFromRepoPath.
+func ResolveRepoPath(basePath, project string) string {
+	// Cleaning against a rooted path drops any ".." that would escape basePath.
+	cleaned := strings.TrimPrefix(filepath.Clean("/"+strings.Trim(project, "/")), "/")
+	return filepath.Join(basePath, filepath.FromSlash(cleaned)+repoDirSuffix)
+}
+
+// ProjectFromRepoPath is the inverse of ResolveRepoPath.
+// It returns false if repoPath is not a clone directory under basePath.
+func ProjectFromRepoPath(basePath, repoPath string) (string, bool) {
+	rel, err := filepath.Rel(basePath, repoPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	if !strings.HasSuffix(rel, repoDirSuffix) {
+		return "", false
+	}
+	return filepath.ToSlash(strings.TrimSuffix(rel, repoDirSuffix)), true
+}
+
+// GetWorktreeBasePath returns the directory holding per-task review worktrees
+func (c *Config) GetWorktreeBasePath() string {
+	return filepath.Join(c.Git.RepoBasePath, ".worktrees")
 }
 
-// GerritEnvVars returns the environment variables needed by gerrit-cli
+// GetQueueDir returns the directory holding the serve queue journal and
+// dead-lettered tasks
+func (c *Config) GetQueueDir() string {
+	return filepath.Join(c.Git.RepoBasePath, ".queue")
+}
+
+// GetQueueJournalPath returns the journal file of the durable serve queue
+func (c *Config) GetQueueJournalPath() string {
+	return filepath.Join(c.GetQueueDir(), "journal.jsonl")
+}
+
+// GetWatermarkPath returns the file holding the last processed event time
+func (c *Config) GetWatermarkPath() string {
+	return filepath.Join(c.Git.RepoBasePath, ".state", "watermark.json")
+}
+
+// AgentEnvVar is set in the environment of gerrit-cli when it runs on behalf
+// of gerrit-reviewer. gerrit-cli then refuses the commands that submit,
+// abandon or otherwise change the state of a change.
+const AgentEnvVar = "GERRIT_CLI_AGENT"
+
+// GerritEnvVars returns the environment variables needed by gerrit-cli,
+// including the review language, vote policy and robot ID for the reviews it
+// posts, and AgentEnvVar
 func (c *Config) GerritEnvVars() []string {
 	return []string{
+		AgentEnvVar + "=1",
 		fmt.Sprintf("GERRIT_SSH_ALIAS=%s", c.Gerrit.SSHAlias),
 		fmt.Sprintf("GERRIT_HTTP_URL=%s", c.Gerrit.HTTPUrl),
 		fmt.Sprintf("GERRIT_HTTP_USER=%s", c.Gerrit.HTTPUser),
 		fmt.Sprintf("GERRIT_HTTP_PASSWORD=%s", c.Gerrit.HTTPPass),
+		fmt.Sprintf("GERRIT_HTTP_MAX_ATTEMPTS=%d", c.Gerrit.HTTPRetry.MaxAttempts),
+		fmt.Sprintf("GERRIT_HTTP_RATE_LIMIT=%g", c.Gerrit.HTTPRateLimit),
 		fmt.Sprintf("GIT_REPO_BASE_PATH=%s", c.Git.RepoBasePath),
+		fmt.Sprintf("REVIEW_LANGUAGE=%s", c.Review.Language),
+		fmt.Sprintf("REVIEW_LABEL=%s", c.Review.Label),
+		fmt.Sprintf("REVIEW_ROBOT_ID=%s", c.Review.RobotComments.RobotID),
+		fmt.Sprintf("REVIEW_VOTE_MAX=%d", c.Review.Vote.Max),
+		fmt.Sprintf("REVIEW_VOTE_MIN=%d", c.Review.Vote.Min),
+		fmt.Sprintf("REVIEW_VOTE_ENFORCE=%s", c.Review.Vote.Enforce),
 	}
 }
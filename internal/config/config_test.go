@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -96,8 +98,10 @@ func TestGetRepoPath(t *testing.T) {
 		project  string
 		expected string
 	}{
-		{"simple-project", "/tmp/repos/simple-project"},
-		{"group/nested-project", "/tmp/repos/nested-project"},
+		{"simple-project", "/tmp/repos/simple-project.repo"},
+		{"group/nested-project", "/tmp/repos/group/nested-project.repo"},
+		{"platform", "/tmp/repos/platform.repo"},
+		{"../escape", "/tmp/repos/escape.repo"},
 	}
 
 	for _, tt := range tests {
@@ -110,6 +114,32 @@ func TestGetRepoPath(t *testing.T) {
 	}
 }
 
+func TestResolveRepoPath_NoCollisionAndReversible(t *testing.T) {
+	base := "/tmp/repos"
+	projects := []string{"platform/foo", "tools/foo", "foo", "platform", "a/b/c"}
+
+	seen := make(map[string]string)
+	for _, project := range projects {
+		path := ResolveRepoPath(base, project)
+		if other, ok := seen[path]; ok {
+			t.Fatalf("projects %q and %q both map to %s", other, project, path)
+		}
+		seen[path] = project
+
+		got, ok := ProjectFromRepoPath(base, path)
+		if !ok || got != project {
+			t.Fatalf("ProjectFromRepoPath(%s) = %q, %v; want %q", path, got, ok, project)
+		}
+	}
+
+	if _, ok := ProjectFromRepoPath(base, "/tmp/repos/legacy"); ok {
+		t.Fatalf("expected legacy path without suffix to be rejected")
+	}
+	if _, ok := ProjectFromRepoPath(base, "/elsewhere/foo.repo"); ok {
+		t.Fatalf("expected path outside base to be rejected")
+	}
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -157,10 +187,16 @@ func TestGerritEnvVars(t *testing.T) {
 			HTTPUrl:  "https://gerrit.example.com",
 			HTTPUser: "user1",
 			HTTPPass: "pass1",
+
+			HTTPRetry:     RetryConfig{MaxAttempts: 5},
+			HTTPRateLimit: 2.5,
 		},
 		Git: GitConfig{
 			RepoBasePath: "/tmp/repos",
 		},
+		Review: ReviewConfig{
+			Language: "en",
+		},
 	}
 
 	envVars := cfg.GerritEnvVars()
@@ -171,6 +207,10 @@ func TestGerritEnvVars(t *testing.T) {
 		"GERRIT_HTTP_USER=user1":                     false,
 		"GERRIT_HTTP_PASSWORD=pass1":                 false,
 		"GIT_REPO_BASE_PATH=/tmp/repos":              false,
+		"REVIEW_LANGUAGE=en":                         false,
+		"GERRIT_HTTP_MAX_ATTEMPTS=5":                 false,
+		"GERRIT_HTTP_RATE_LIMIT=2.5":                 false,
+		AgentEnvVar + "=1":                           false,
 	}
 
 	for _, env := range envVars {
@@ -186,6 +226,44 @@ func TestGerritEnvVars(t *testing.T) {
 	}
 }
 
+func TestGerritHTTPSettings(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("yaml")
+	yaml := `
+gerrit:
+  ssh_alias: gerrit
+  http_url: https://gerrit.test.com
+  http_user: user
+  http_password: pass
+  http_rate_limit: 5
+`
+	if err := viper.ReadConfig(strings.NewReader(yaml)); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	cfg, err := buildConfig()
+	if err != nil {
+		t.Fatalf("buildConfig() failed: %v", err)
+	}
+	if got := cfg.Gerrit.HTTPRetry; got != (RetryConfig{MaxAttempts: 3, BaseDelay: 1, MaxDelay: 30}) {
+		t.Errorf("Expected default REST retry policy, got %+v", got)
+	}
+	if cfg.Gerrit.HTTPRateLimit != 5 {
+		t.Errorf("Expected rate limit 5, got %v", cfg.Gerrit.HTTPRateLimit)
+	}
+	if got := LoadGerritConfig(); got.HTTPUrl != "https://gerrit.test.com" || got.HTTPRetry != cfg.Gerrit.HTTPRetry || got.HTTPRateLimit != 5 {
+		t.Errorf("Expected LoadGerritConfig to match, got %+v", got)
+	}
+
+	for key, value := range map[string]interface{}{"gerrit.http_retry.max_attempts": -1, "gerrit.http_rate_limit": -2} {
+		viper.Set(key, value)
+		if _, err := buildConfig(); err == nil || !strings.Contains(err.Error(), strings.Split(key, ".")[1]) {
+			t.Errorf("expected %s=%v to be rejected, got %v", key, value, err)
+		}
+		viper.Set(key, nil)
+	}
+}
+
 func TestClaudeSkipPermissionsDefaultFalse(t *testing.T) {
 	viper.Reset()
 
@@ -221,6 +299,367 @@ func TestClaudeSkipPermissionsDefaultFalse(t *testing.T) {
 	if cfg.Review.CLI != "claude" {
 		t.Fatalf("expected Review.CLI default claude, got %q", cfg.Review.CLI)
 	}
+	if got := cfg.Serve.Retry["rate_limited"]; got.MaxAttempts != 4 || got.BaseDelay != 300 {
+		t.Fatalf("expected default rate_limited retry policy, got %+v", got)
+	}
+	if got := cfg.Serve.Retry["other"]; got.MaxAttempts != 1 {
+		t.Fatalf("expected other errors not to be retried by default, got %+v", got)
+	}
+	if len(cfg.Serve.Events) != 4 || cfg.Serve.Events[1] != "comment-added" {
+		t.Fatalf("expected default serve.events, got %v", cfg.Serve.Events)
+	}
+	if cfg.Serve.Trigger != "/ai-review" {
+		t.Fatalf("expected default serve.trigger /ai-review, got %q", cfg.Serve.Trigger)
+	}
+	if cfg.Review.Size.MaxFiles != 0 || len(cfg.Review.Size.Ignore) == 0 || !cfg.Review.Size.Notify {
+		t.Fatalf("expected default review.size, got %+v", cfg.Review.Size)
+	}
+	if cfg.Review.Language != "zh-TW" {
+		t.Fatalf("expected default review.language zh-TW, got %q", cfg.Review.Language)
+	}
+}
+
+func TestProjectSizeLimits(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("yaml")
+	yaml := `
+gerrit:
+  ssh_alias: gerrit
+  http_url: https://gerrit.test.com
+  http_user: user
+  http_password: pass
+review:
+  size:
+    max_files: 50
+    max_insertions: 2000
+projects:
+  - project: platform/**
+    size:
+      max_files: 500
+  - project: platform/monorepo
+    size:
+      max_insertions: 0
+      ignore: ["generated/**"]
+      notify: false
+`
+	if err := viper.ReadConfig(strings.NewReader(yaml)); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	cfg, err := buildConfig()
+	if err != nil {
+		t.Fatalf("buildConfig() failed: %v", err)
+	}
+
+	if got := cfg.ForChange("tools/cli", "main", nil).Review.Size; got.MaxFiles != 50 || got.MaxInsertions != 2000 {
+		t.Errorf("Expected global limits for unmatched project, got %+v", got)
+	}
+	if got := cfg.ForChange("platform/core", "main", nil).Review.Size; got.MaxFiles != 500 || got.MaxInsertions != 2000 {
+		t.Errorf("Expected max_files override for platform/core, got %+v", got)
+	}
+
+	got := cfg.ForChange("platform/monorepo", "main", nil).Review.Size
+	if got.MaxFiles != 500 || got.MaxInsertions != 0 {
+		t.Errorf("Expected both overrides for platform/monorepo, got %+v", got)
+	}
+	if len(got.Ignore) != 1 || got.Ignore[0] != "generated/**" {
+		t.Errorf("Expected ignore list replaced, got %v", got.Ignore)
+	}
+	if got.Notify {
+		t.Errorf("Expected notify disabled, got %+v", got)
+	}
+}
+
+func TestReviewLanguage(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("yaml")
+	yaml := `
+gerrit:
+  ssh_alias: gerrit
+  http_url: https://gerrit.test.com
+  http_user: user
+  http_password: pass
+review:
+  language: en-US
+projects:
+  - project: asia/**
+    language: zh_tw
+`
+	if err := viper.ReadConfig(strings.NewReader(yaml)); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	cfg, err := buildConfig()
+	if err != nil {
+		t.Fatalf("buildConfig() failed: %v", err)
+	}
+	if cfg.Review.Language != "en" {
+		t.Errorf("Expected normalized language en, got %q", cfg.Review.Language)
+	}
+	if got := cfg.ForChange("asia/app", "main", nil).Review.Language; got != "zh-TW" {
+		t.Errorf("Expected project language zh-TW, got %q", got)
+	}
+
+	viper.Set("review.language", "klingon")
+	if _, err := buildConfig(); err == nil || !strings.Contains(err.Error(), "review.language") {
+		t.Fatalf("expected unsupported review.language to fail, got %v", err)
+	}
+}
+
+func TestProjectOverridePrecedence(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("yaml")
+	yaml := `
+gerrit:
+  ssh_alias: gerrit
+  http_url: https://gerrit.test.com
+  http_user: user
+  http_password: pass
+review:
+  cli: claude
+  claude_timeout: 600
+projects:
+  - project: monorepo
+    cli: codex
+    timeout: 1800
+    filter:
+      skip_wip: true
+      exclude_files: ["docs/**"]
+  - project: monorepo
+    branches: ["release-*"]
+    timeout: 3600
+`
+	if err := viper.ReadConfig(strings.NewReader(yaml)); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	cfg, err := buildConfig()
+	if err != nil {
+		t.Fatalf("buildConfig() failed: %v", err)
+	}
+
+	if !cfg.Projects[0].Filter.SkipWIP || len(cfg.Projects[0].Filter.ExcludeFiles) != 1 {
+		t.Errorf("Expected project filter to be loaded, got %+v", cfg.Projects[0].Filter)
+	}
+
+	if got := cfg.ForChange("service", "main", nil).Review; got.CLI != "claude" || got.ClaudeTimeout != 600 {
+		t.Errorf("Expected global settings for unmatched project, got cli=%s timeout=%d", got.CLI, got.ClaudeTimeout)
+	}
+	if got := cfg.ForChange("monorepo", "main", nil).Review; got.CLI != "codex" || got.ClaudeTimeout != 1800 {
+		t.Errorf("Expected project settings on main, got cli=%s timeout=%d", got.CLI, got.ClaudeTimeout)
+	}
+	if got := cfg.ForChange("monorepo", "release-2.0", nil).Review; got.CLI != "codex" || got.ClaudeTimeout != 3600 {
+		t.Errorf("Expected branch entry to win on release branch, got cli=%s timeout=%d", got.CLI, got.ClaudeTimeout)
+	}
+
+	// The in-repo file overrides global settings but not projects entries
+	cli, timeout := "command", 60
+	repo := &ReviewOverride{CLI: &cli, Timeout: &timeout}
+	if got := cfg.ForChange("service", "main", repo).Review; got.CLI != "command" || got.ClaudeTimeout != 60 {
+		t.Errorf("Expected in-repo settings for unmatched project, got cli=%s timeout=%d", got.CLI, got.ClaudeTimeout)
+	}
+	if got := cfg.ForChange("monorepo", "main", repo).Review; got.CLI != "codex" || got.ClaudeTimeout != 1800 {
+		t.Errorf("Expected projects entry to win over in-repo settings, got cli=%s timeout=%d", got.CLI, got.ClaudeTimeout)
+	}
+	if cfg.Review.CLI != "claude" {
+		t.Errorf("Expected ForChange not to modify the global config, got %s", cfg.Review.CLI)
+	}
+
+	if got := cfg.ReviewBackends(); len(got) != 2 || got[0] != "claude" || got[1] != "codex" {
+		t.Errorf("Expected backends [claude codex], got %v", got)
+	}
+}
+
+func TestLoadRepoConfigRejectsSymlinkOutsideRepo(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(t.TempDir(), "host.yaml")
+	if err := os.WriteFile(target, []byte("cli: codex\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(dir, RepoConfigFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	if repo, err := LoadRepoConfig(dir); err == nil {
+		t.Fatalf("expected LoadRepoConfig to fail, got %+v", repo)
+	}
+}
+
+func TestLoadRepoConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	repo, err := LoadRepoConfig(dir)
+	if err != nil || repo != nil {
+		t.Fatalf("expected no overrides without %s, got %+v, %v", RepoConfigFile, repo, err)
+	}
+
+	content := "cli: codex\nsize:\n  max_files: 300\n"
+	if err := os.WriteFile(filepath.Join(dir, RepoConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err = LoadRepoConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadRepoConfig() failed: %v", err)
+	}
+	if repo.CLI == nil || *repo.CLI != "codex" || repo.Size.MaxFiles == nil || *repo.Size.MaxFiles != 300 {
+		t.Fatalf("unexpected overrides: %+v", repo)
+	}
+	if repo.Timeout != nil {
+		t.Errorf("Expected unset timeout, got %d", *repo.Timeout)
+	}
+
+	cfg := &Config{Review: ReviewConfig{CLI: "claude"}}
+	language, reviewDir := "en", ".review"
+	if err := cfg.ValidateRepoConfig(&ReviewOverride{Language: &language, Skill: SkillConfigOverride{RepoDir: &reviewDir}}); err != nil {
+		t.Errorf("Expected language and skill.repo_dir to be accepted, got %v", err)
+	}
+	if err := cfg.ValidateRepoConfig(repo); err == nil {
+		t.Errorf("expected cli and size to be rejected in %s", RepoConfigFile)
+	}
+	codex, timeout, maxFiles := "codex", 1, 0
+	for name, untrusted := range map[string]*ReviewOverride{
+		"cli":            {CLI: &codex},
+		"timeout":        {Timeout: &timeout},
+		"size.max_files": {Size: SizeConfigOverride{MaxFiles: &maxFiles}},
+		"size.ignore":    {Size: SizeConfigOverride{Ignore: []string{"**"}}},
+	} {
+		if err := cfg.ValidateRepoConfig(untrusted); err == nil {
+			t.Errorf("expected %s to be rejected in %s", name, RepoConfigFile)
+		}
+	}
+	base := "/etc/passwd"
+	if err := cfg.ValidateRepoConfig(&ReviewOverride{Skill: SkillConfigOverride{Base: &base}}); err == nil {
+		t.Errorf("expected skill.base to be rejected in %s", RepoConfigFile)
+	}
+	repoDir := "../outside"
+	if err := cfg.ValidateRepoConfig(&ReviewOverride{Skill: SkillConfigOverride{RepoDir: &repoDir}}); err == nil {
+		t.Errorf("expected skill.repo_dir outside the repository to be rejected")
+	}
+	voteMax := 1
+	if err := cfg.ValidateRepoConfig(&ReviewOverride{Vote: VoteConfigOverride{Max: &voteMax}}); err == nil {
+		t.Errorf("expected vote to be rejected in %s", RepoConfigFile)
+	}
+	label := "Verified"
+	if err := cfg.ValidateRepoConfig(&ReviewOverride{Label: &label}); err == nil {
+		t.Errorf("expected label to be rejected in %s", RepoConfigFile)
+	}
+}
+
+func TestInvalidSizeLimits(t *testing.T) {
+	cfg := &Config{
+		Gerrit: GerritConfig{
+			SSHAlias: "gerrit",
+			HTTPUrl:  "https://gerrit.test.com",
+			HTTPUser: "user",
+			HTTPPass: "pass",
+		},
+		Git: GitConfig{
+			RepoBasePath: "/tmp/test-repos",
+		},
+		Review: ReviewConfig{
+			Size: SizeConfig{MaxFiles: -1},
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail for negative max_files")
+	}
+
+	cfg.Review.Size.MaxFiles = 10
+	cfg.Projects = []ProjectConfig{{Project: "re:(broken"}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail for invalid project pattern")
+	}
+
+	cfg.Projects = []ProjectConfig{{Project: "platform/*", ReviewOverride: ReviewOverride{Size: SizeConfigOverride{Ignore: []string{"re:(*.go"}}}}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail for invalid project ignore pattern")
+	}
+}
+
+func TestVotePolicy(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("yaml")
+	yaml := `
+gerrit:
+  ssh_alias: gerrit
+  http_url: https://gerrit.test.com
+  http_user: user
+  http_password: pass
+projects:
+  - project: critical/**
+    vote: {max: 0, enforce: reject}
+`
+	if err := viper.ReadConfig(strings.NewReader(yaml)); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	cfg, err := buildConfig()
+	if err != nil {
+		t.Fatalf("buildConfig() failed: %v", err)
+	}
+	if cfg.Review.Label != "Code-Review" {
+		t.Errorf("Expected default label Code-Review, got %q", cfg.Review.Label)
+	}
+	if got := cfg.Review.Vote; got != (VoteConfig{Max: 1, Min: -1, Enforce: "clamp"}) {
+		t.Errorf("Expected default vote policy, got %+v", got)
+	}
+	if got := cfg.ForChange("critical/db", "main", nil).Review.Vote; got != (VoteConfig{Max: 0, Min: -1, Enforce: "reject"}) {
+		t.Errorf("Expected project vote policy, got %+v", got)
+	}
+
+	tests := []struct {
+		key   string
+		value interface{}
+	}{
+		{"review.vote.max", 2},
+		{"review.vote.min", -3},
+		{"review.vote.min", 1},
+		{"review.vote.enforce", "ignore"},
+		{"review.label", "Code Review"},
+	}
+	for _, tt := range tests {
+		viper.Set(tt.key, tt.value)
+		if _, err := buildConfig(); err == nil || !strings.Contains(err.Error(), strings.TrimPrefix(tt.key, "review.")) {
+			t.Errorf("expected %s=%v to be rejected, got %v", tt.key, tt.value, err)
+		}
+		viper.Set(tt.key, nil)
+	}
+}
+
+func TestRobotComments(t *testing.T) {
+	viper.Reset()
+	viper.SetConfigType("yaml")
+	yaml := `
+gerrit:
+  ssh_alias: gerrit
+  http_url: https://gerrit.test.com
+  http_user: user
+  http_password: pass
+review:
+  robot_comments: {enabled: true}
+`
+	if err := viper.ReadConfig(strings.NewReader(yaml)); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	if _, err := buildConfig(); err == nil || !strings.Contains(err.Error(), "output_mode") {
+		t.Errorf("expected robot comments in agent mode to be rejected, got %v", err)
+	}
+
+	viper.Set("review.output_mode", "structured")
+	cfg, err := buildConfig()
+	if err != nil {
+		t.Fatalf("buildConfig() failed: %v", err)
+	}
+	if got := cfg.Review.RobotComments; got != (RobotCommentsConfig{Enabled: true, RobotID: "gerrit-ai-review"}) {
+		t.Errorf("Expected robot comments with the default robot ID, got %+v", got)
+	}
+
+	viper.Set("review.robot_comments.robot_id", " ")
+	if _, err := buildConfig(); err == nil || !strings.Contains(err.Error(), "robot_id") {
+		t.Errorf("expected an empty robot_id to be rejected, got %v", err)
+	}
 }
 
 func TestReviewCLIFromEnv(t *testing.T) {
@@ -272,6 +711,174 @@ func TestInvalidReviewCLI(t *testing.T) {
 	}
 }
 
+func TestInvalidReviewOutputMode(t *testing.T) {
+	cfg := &Config{
+		Gerrit: GerritConfig{
+			SSHAlias: "gerrit",
+			HTTPUrl:  "https://gerrit.test.com",
+			HTTPUser: "user",
+			HTTPPass: "pass",
+		},
+		Git: GitConfig{
+			RepoBasePath: "/tmp/test-repos",
+		},
+		Review: ReviewConfig{
+			OutputMode: "json",
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail for invalid review.output_mode")
+	}
+
+	cfg.Review.OutputMode = "structured"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected structured output mode to be valid: %v", err)
+	}
+}
+
+func TestCommandBackendValidation(t *testing.T) {
+	cfg := &Config{
+		Gerrit: GerritConfig{
+			SSHAlias: "gerrit",
+			HTTPUrl:  "https://gerrit.test.com",
+			HTTPUser: "user",
+			HTTPPass: "pass",
+		},
+		Git: GitConfig{
+			RepoBasePath: "/tmp/test-repos",
+		},
+		Review: ReviewConfig{
+			CLI: "command",
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail when review.command.argv is empty")
+	}
+
+	cfg.Review.Command.Argv = []string{"local-llm", "{{prompt}}"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected command backend to be valid: %v", err)
+	}
+
+	cfg.Review.Command.Stdout = "xml"
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail for invalid review.command.stdout")
+	}
+}
+
+func TestNativeSSHClientValidation(t *testing.T) {
+	cfg := &Config{
+		Gerrit: GerritConfig{
+			SSHAlias: "gerrit",
+			HTTPUrl:  "https://gerrit.test.com",
+			HTTPUser: "user",
+			HTTPPass: "pass",
+			SSH: SSHConfig{
+				Client:     "native",
+				Port:       29418,
+				KnownHosts: "~/.ssh/known_hosts",
+			},
+		},
+		Git: GitConfig{
+			RepoBasePath: "/tmp/test-repos",
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail when gerrit.ssh.host is empty")
+	}
+
+	cfg.Gerrit.SSH.Host = "gerrit.test.com"
+	cfg.Gerrit.SSH.User = "reviewer"
+	cfg.Gerrit.SSH.KeyFile = "~/.ssh/id_ed25519"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected native SSH client to be valid: %v", err)
+	}
+
+	cfg.Gerrit.SSH.Client = "libssh"
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail for invalid gerrit.ssh.client")
+	}
+}
+
+func TestServeEventsValidation(t *testing.T) {
+	cfg := &Config{
+		Gerrit: GerritConfig{
+			SSHAlias: "gerrit",
+			HTTPUrl:  "https://gerrit.test.com",
+			HTTPUser: "user",
+			HTTPPass: "pass",
+		},
+		Git: GitConfig{
+			RepoBasePath: "/tmp/test-repos",
+		},
+	}
+
+	cfg.Serve.Events = []string{"comment-added", "ref-updated"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected serve.events to be valid: %v", err)
+	}
+
+	cfg.Serve.Events = []string{"patchset-created", "project-created"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail for unknown event type")
+	}
+}
+
+func TestInvalidFilterPattern(t *testing.T) {
+	cfg := &Config{
+		Gerrit: GerritConfig{
+			SSHAlias: "gerrit",
+			HTTPUrl:  "https://gerrit.test.com",
+			HTTPUser: "user",
+			HTTPPass: "pass",
+		},
+		Git: GitConfig{
+			RepoBasePath: "/tmp/test-repos",
+		},
+		Serve: ServeConfig{
+			Filter: FilterConfig{Branches: []string{"release-*", "re:main|dev"}},
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected filter patterns to be valid: %v", err)
+	}
+
+	cfg.Serve.Filter.ExcludeFiles = []string{"re:(*.md"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail for invalid regexp pattern")
+	}
+}
+
+func TestInvalidRetryPolicy(t *testing.T) {
+	cfg := &Config{
+		Gerrit: GerritConfig{
+			SSHAlias: "gerrit",
+			HTTPUrl:  "https://gerrit.test.com",
+			HTTPUser: "user",
+			HTTPPass: "pass",
+		},
+		Git: GitConfig{
+			RepoBasePath: "/tmp/test-repos",
+		},
+		Serve: ServeConfig{
+			Retry: map[string]RetryConfig{"git": {MaxAttempts: 0}},
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail for max_attempts 0")
+	}
+
+	cfg.Serve.Retry = map[string]RetryConfig{"network": {MaxAttempts: 2}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected Validate() to fail for unknown error class")
+	}
+}
+
 func TestInvalidLoggingLevel(t *testing.T) {
 	cfg := &Config{
 		Gerrit: GerritConfig{
@@ -0,0 +1,115 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
+)
+
+// catchUpSkew widens the catch-up window to cover clock differences between
+// the Gerrit server and this host.
+const catchUpSkew = 2 * time.Minute
+
+// catchUpPageSize is the number of changes fetched per catch-up request.
+const catchUpPageSize = 100
+
+// CatchUp finds patchsets uploaded while the listener was disconnected.
+type CatchUp struct {
+	client  *gerrit.Client
+	botUser string
+	log     *logger.Logger
+}
+
+// NewCatchUp creates a catch-up that queries Gerrit through client and
+// skips patchsets already reviewed by botUser (the HTTP account).
+func NewCatchUp(client *gerrit.Client, botUser string) *CatchUp {
+	return &CatchUp{
+		client:  client,
+		botUser: botUser,
+		log:     logger.Get(),
+	}
+}
+
+// MissedEvents returns synthetic patchset-created events for open changes
+// updated since the given watermark whose current patchset has no review
+// from the bot account yet, oldest first. Without a watermark there is
+// nothing to catch up on and no events are returned.
+func (c *CatchUp) MissedEvents(ctx context.Context, since time.Time) ([]Event, error) {
+	if since.IsZero() {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`status:open after:"%s"`, since.Add(-catchUpSkew).UTC().Format("2006-01-02 15:04:05 -0700"))
+	c.log.Debugf("Catch-up query: %s", query)
+
+	var missed []Event
+	options := []string{"CURRENT_REVISION", "MESSAGES", "DETAILED_ACCOUNTS"}
+	err := c.client.ForEachChange(ctx, query, options, catchUpPageSize, 0, func(change gerrit.ChangeInfo) error {
+		rev, ok := change.Revisions[change.CurrentRevision]
+		if !ok || rev == nil {
+			return nil
+		}
+		if c.reviewedByBot(change, rev.Number) {
+			return nil
+		}
+
+		missed = append(missed, Event{
+			Type: TypePatchsetCreated,
+			Change: &Change{
+				Project:  change.Project,
+				Branch:   change.Branch,
+				Number:   change.Number,
+				Subject:  change.Subject,
+				Owner:    toAccount(&change.Owner),
+				Status:   change.Status,
+				Topic:    change.Topic,
+				Hashtags: change.Hashtags,
+				WIP:      change.WorkInProgress,
+				Private:  change.IsPrivate,
+			},
+			PatchSet: &PatchSet{
+				Number:   rev.Number,
+				Ref:      rev.Ref,
+				Revision: change.CurrentRevision,
+				Uploader: toAccount(&rev.Uploader),
+				Kind:     rev.Kind,
+			},
+			EventCreatedOn: rev.Created.Unix(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("catch-up query failed: %w", err)
+	}
+
+	sort.Slice(missed, func(i, j int) bool {
+		return missed[i].EventCreatedOn < missed[j].EventCreatedOn
+	})
+
+	return missed, nil
+}
+
+// reviewedByBot reports whether the bot account left a message on patchset.
+func (c *CatchUp) reviewedByBot(change gerrit.ChangeInfo, patchset int) bool {
+	for _, msg := range change.Messages {
+		if msg.Author == nil || msg.RevisionNumber != patchset {
+			continue
+		}
+		if msg.Author.Username == c.botUser {
+			return true
+		}
+	}
+	return false
+}
+
+// toAccount converts a REST account to its stream-events form
+func toAccount(account *gerrit.AccountInfo) *Account {
+	if account == nil || (account.Username == "" && account.Email == "" && account.Name == "") {
+		return nil
+	}
+	return &Account{Name: account.Name, Email: account.Email, Username: account.Username}
+}
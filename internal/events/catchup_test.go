@@ -0,0 +1,220 @@
+package events
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+)
+
+func newCatchUpTestServer(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("skipping network-dependent test: %v", err)
+	}
+
+	server := &http.Server{Handler: handler}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() { _ = server.Close() })
+
+	return "http://" + listener.Addr().String()
+}
+
+func TestWatermarkPersistsAndOnlyAdvances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "watermark.json")
+
+	w, err := LoadWatermark(path)
+	if err != nil {
+		t.Fatalf("LoadWatermark failed: %v", err)
+	}
+	if !w.Last().IsZero() {
+		t.Fatalf("expected zero watermark, got %v", w.Last())
+	}
+
+	if err := w.Advance(2000); err != nil {
+		t.Fatalf("Advance failed: %v", err)
+	}
+	if err := w.Advance(1000); err != nil {
+		t.Fatalf("Advance failed: %v", err)
+	}
+
+	reloaded, err := LoadWatermark(path)
+	if err != nil {
+		t.Fatalf("LoadWatermark failed: %v", err)
+	}
+	if reloaded.Last().Unix() != 2000 {
+		t.Errorf("Expected watermark 2000, got %d", reloaded.Last().Unix())
+	}
+}
+
+func TestCatchUpMissedEvents(t *testing.T) {
+	var gotQuery string
+	baseURL := newCatchUpTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("q")
+		w.Write([]byte(`)]}'
+[
+  {
+    "project": "platform/app", "branch": "main", "subject": "Not reviewed", "_number": 101,
+    "current_revision": "aaa",
+    "revisions": {"aaa": {"_number": 2, "ref": "refs/changes/01/101/2", "created": "2026-01-01 10:05:00.000000000"}},
+    "messages": [
+      {"id": "m1", "author": {"username": "ai-bot"}, "_revision_number": 1, "message": "Patch Set 1: Code-Review+1"}
+    ]
+  },
+  {
+    "project": "platform/app", "branch": "main", "subject": "Already reviewed", "_number": 102,
+    "current_revision": "bbb",
+    "revisions": {"bbb": {"_number": 1, "ref": "refs/changes/02/102/1", "created": "2026-01-01 10:01:00.000000000"}},
+    "messages": [
+      {"id": "m2", "author": {"username": "ai-bot"}, "_revision_number": 1, "message": "Patch Set 1: Code-Review-1"}
+    ]
+  }
+]`))
+	}))
+
+	watermark, err := LoadWatermark(filepath.Join(t.TempDir(), "watermark.json"))
+	if err != nil {
+		t.Fatalf("LoadWatermark failed: %v", err)
+	}
+	since := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	if err := watermark.Advance(since.Unix()); err != nil {
+		t.Fatalf("Advance failed: %v", err)
+	}
+
+	catchUp := NewCatchUp(gerrit.NewClient(baseURL, "ai-bot", "secret"), "ai-bot")
+	missed, err := catchUp.MissedEvents(context.Background(), watermark.Last())
+	if err != nil {
+		t.Fatalf("MissedEvents failed: %v", err)
+	}
+
+	if !strings.Contains(gotQuery, "status:open") || !strings.Contains(gotQuery, `after:"2026-01-01 09:58:00 +0000"`) {
+		t.Errorf("Unexpected catch-up query: %s", gotQuery)
+	}
+
+	if len(missed) != 1 {
+		t.Fatalf("Expected 1 missed event, got %d: %+v", len(missed), missed)
+	}
+	event := missed[0]
+	if event.Type != "patchset-created" || event.Change.Number != 101 || event.PatchSet.Number != 2 {
+		t.Errorf("Unexpected event: %+v %+v", event.Change, event.PatchSet)
+	}
+	if event.Change.Project != "platform/app" {
+		t.Errorf("Expected project platform/app, got %s", event.Change.Project)
+	}
+}
+
+func TestCatchUpMissedEventsFollowsPages(t *testing.T) {
+	var starts []string
+	baseURL := newCatchUpTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := r.URL.Query().Get("S")
+		starts = append(starts, start)
+		if start == "" {
+			w.Write([]byte(`)]}'
+[{"project": "p", "branch": "main", "_number": 1, "current_revision": "a",
+  "revisions": {"a": {"_number": 1, "created": "2026-01-01 10:01:00.000000000"}}, "_more_changes": true}]`))
+			return
+		}
+		w.Write([]byte(`)]}'
+[{"project": "p", "branch": "main", "_number": 2, "current_revision": "b",
+  "revisions": {"b": {"_number": 1, "created": "2026-01-01 10:02:00.000000000"}}}]`))
+	}))
+
+	catchUp := NewCatchUp(gerrit.NewClient(baseURL, "ai-bot", "secret"), "ai-bot")
+	missed, err := catchUp.MissedEvents(context.Background(), time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("MissedEvents failed: %v", err)
+	}
+
+	if len(starts) != 2 || starts[1] != "1" {
+		t.Errorf("Expected a second page starting at 1, got starts %q", starts)
+	}
+	if len(missed) != 2 || missed[0].Change.Number != 1 || missed[1].Change.Number != 2 {
+		t.Fatalf("Expected events for changes 1 and 2, got %+v", missed)
+	}
+}
+
+func TestCatchUpWithoutWatermarkIsNoop(t *testing.T) {
+	watermark, err := LoadWatermark(filepath.Join(t.TempDir(), "watermark.json"))
+	if err != nil {
+		t.Fatalf("LoadWatermark failed: %v", err)
+	}
+
+	// No server: a query would fail
+	catchUp := NewCatchUp(gerrit.NewClient("http://127.0.0.1:1", "ai-bot", "secret"), "ai-bot")
+	missed, err := catchUp.MissedEvents(context.Background(), watermark.Last())
+	if err != nil {
+		t.Fatalf("expected no query without a watermark, got: %v", err)
+	}
+	if len(missed) != 0 {
+		t.Fatalf("expected no events, got %d", len(missed))
+	}
+}
+
+// advancingTransport advances the watermark when the stream opens, like an
+// event processed while catch-up is still running
+type advancingTransport struct {
+	watermark *Watermark
+}
+
+func (t *advancingTransport) Open(ctx context.Context, command string) (io.ReadCloser, error) {
+	if err := t.watermark.Advance(time.Now().Unix()); err != nil {
+		return nil, err
+	}
+	// Keep the stream open until the test ends
+	r, w := io.Pipe()
+	go func() {
+		<-ctx.Done()
+		w.Close()
+	}()
+	return r, nil
+}
+
+func (t *advancingTransport) Run(ctx context.Context, command string) ([]byte, error) {
+	return nil, nil
+}
+
+func (t *advancingTransport) String() string { return "fake" }
+
+func TestListenerCatchUpUsesWatermarkFromBeforeConnect(t *testing.T) {
+	watermark, err := LoadWatermark(filepath.Join(t.TempDir(), "watermark.json"))
+	if err != nil {
+		t.Fatalf("LoadWatermark failed: %v", err)
+	}
+	disconnected := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	if err := watermark.Advance(disconnected.Unix()); err != nil {
+		t.Fatalf("Advance failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sinceCh := make(chan time.Time, 1)
+	listener := NewListenerWithTransport(&advancingTransport{watermark: watermark})
+	listener.SetCatchUp(watermark, func(ctx context.Context, since time.Time) ([]Event, error) {
+		sinceCh <- since
+		return nil, nil
+	})
+
+	if _, err := listener.StreamEvents(ctx); err != nil {
+		t.Fatalf("StreamEvents failed: %v", err)
+	}
+
+	select {
+	case since := <-sinceCh:
+		if !since.Equal(disconnected) {
+			t.Errorf("Expected catch-up since %v, got %v", disconnected, since)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("catch-up did not run")
+	}
+}
@@ -0,0 +1,34 @@
+package events
+
+import (
+	"context"
+)
+
+// Handler processes one event of the type it was registered for
+type Handler func(ctx context.Context, event Event)
+
+// Dispatcher routes events to the handler registered for their type
+type Dispatcher struct {
+	handlers map[string]Handler
+}
+
+// NewDispatcher creates a dispatcher without handlers
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[string]Handler)}
+}
+
+// Handle registers the handler for an event type, replacing any earlier one
+func (d *Dispatcher) Handle(eventType string, handler Handler) {
+	d.handlers[eventType] = handler
+}
+
+// Dispatch runs the handler for the event's type. It returns false if no
+// handler is registered for it.
+func (d *Dispatcher) Dispatch(ctx context.Context, event Event) bool {
+	handler, ok := d.handlers[event.Type]
+	if !ok {
+		return false
+	}
+	handler(ctx, event)
+	return true
+}
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestEventUnmarshal_CommentAdded(t *testing.T) {
+	line := `{"type":"comment-added","change":{"project":"platform/app","branch":"main","number":42,"wip":true},` +
+		`"patchSet":{"number":3,"kind":"REWORK"},"author":{"username":"alice"},` +
+		`"approvals":[{"type":"Code-Review","description":"Code-Review","value":"-1","oldValue":"0"}],` +
+		`"comment":"Patch Set 3: Code-Review-1\n\n/ai-review","eventCreatedOn":1700000000}`
+
+	var event Event
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if event.Author == nil || event.Author.Username != "alice" {
+		t.Errorf("Expected author alice, got %+v", event.Author)
+	}
+	if len(event.Approvals) != 1 || event.Approvals[0].Type != "Code-Review" || event.Approvals[0].Value != "-1" {
+		t.Errorf("Unexpected approvals: %+v", event.Approvals)
+	}
+	if event.Comment != "Patch Set 3: Code-Review-1\n\n/ai-review" {
+		t.Errorf("Unexpected comment: %q", event.Comment)
+	}
+	if !event.Change.WIP || event.PatchSet.Kind != "REWORK" {
+		t.Errorf("Expected wip change and REWORK patchset, got %+v %+v", event.Change, event.PatchSet)
+	}
+}
+
+func TestEventProject(t *testing.T) {
+	var event Event
+	line := `{"type":"ref-updated","submitter":{"username":"bob"},"refUpdate":{"oldRev":"a","newRev":"b","refName":"refs/heads/main","project":"platform/lib"}}`
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if event.Project() != "platform/lib" {
+		t.Errorf("Expected project platform/lib, got %q", event.Project())
+	}
+
+	event = Event{Type: TypeChangeMerged, Change: &Change{Project: "platform/app"}}
+	if event.Project() != "platform/app" {
+		t.Errorf("Expected project platform/app, got %q", event.Project())
+	}
+
+	if (Event{Type: "dropped-output"}).Project() != "" {
+		t.Errorf("Expected no project for event without change or refUpdate")
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	d := NewDispatcher()
+
+	var got []string
+	d.Handle(TypeCommentAdded, func(ctx context.Context, event Event) {
+		got = append(got, "comment:"+event.Comment)
+	})
+	d.Handle(TypeChangeMerged, func(ctx context.Context, event Event) {
+		got = append(got, "merged")
+	})
+
+	if !d.Dispatch(context.Background(), Event{Type: TypeCommentAdded, Comment: "lgtm"}) {
+		t.Errorf("expected comment-added to be dispatched")
+	}
+	if !d.Dispatch(context.Background(), Event{Type: TypeChangeMerged}) {
+		t.Errorf("expected change-merged to be dispatched")
+	}
+	if d.Dispatch(context.Background(), Event{Type: TypeReviewerAdded}) {
+		t.Errorf("expected reviewer-added without handler not to be dispatched")
+	}
+
+	if len(got) != 2 || got[0] != "comment:lgtm" || got[1] != "merged" {
+		t.Errorf("Unexpected handler calls: %v", got)
+	}
+}
+
+func TestStreamEventsCommand(t *testing.T) {
+	got := streamEventsCommand([]string{TypePatchsetCreated, TypeCommentAdded})
+	want := "gerrit stream-events -s patchset-created -s comment-added"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestParseTrigger(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    Trigger
+		ok      bool
+	}{
+		{"plain", "Patch Set 3:\n\n/ai-review", Trigger{}, true},
+		{"focus", "Patch Set 3:\n\n/ai-review focus:security,tests", Trigger{Focus: []string{"security", "tests"}}, true},
+		{"incremental", "/AI-Review incremental please", Trigger{Incremental: true, Unknown: []string{"please"}}, true},
+		{"not at line start", "Patch Set 3:\n\nplease run /ai-review", Trigger{}, false},
+		{"prefix only", "Patch Set 3:\n\n/ai-reviewer", Trigger{}, false},
+		{"no trigger", "Patch Set 3: Code-Review+1", Trigger{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseTrigger(tt.comment, "/ai-review")
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%t, got %t", tt.ok, ok)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	if _, ok := ParseTrigger("/ai-review", ""); ok {
+		t.Errorf("expected empty trigger command to disable triggers")
+	}
+}
@@ -1,56 +1,333 @@
 package events
 
 import (
+	"context"
+	"fmt"
 	"strings"
+	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/pattern"
 )
 
-// FilterConfig defines event filtering rules
+// FilterConfig defines event filtering rules.
+//
+// Patterns are globs or "re:" regular expressions; see package pattern.
 type FilterConfig struct {
-	Projects []string // Empty = allow all
-	Exclude  []string // Exclude these projects
+	Projects         []string // Empty = allow all
+	Exclude          []string // Exclude these projects
+	Branches         []string // Empty = allow all
+	ExcludeBranches  []string
+	Owners           []string // Change owner username, email or name; empty = allow all
+	ExcludeOwners    []string
+	Uploaders        []string // Patchset uploader username, email or name; empty = allow all
+	ExcludeUploaders []string
+	Hashtags         []string // Require one matching hashtag; empty = allow all
+	ExcludeHashtags  []string
+	Topics           []string // Require a matching topic; empty = allow all
+	ExcludeTopics    []string
+	Files            []string // Require at least one changed file matching; empty = allow all
+	ExcludeFiles     []string // Skip patchsets whose changed files all match
+	SkipWIP          bool     // Skip work-in-progress changes
+	SkipPrivate      bool     // Skip private changes
+	SkipKinds        []string // Patchset kinds to skip, e.g. TRIVIAL_REBASE, NO_CODE_CHANGE
 }
 
+// FilesLookup returns the files changed by the patchset of an event
+type FilesLookup func(ctx context.Context, event Event) ([]string, error)
+
+// filesLookupTimeout bounds a FilesLookup call. Filters run in the event
+// loop, so a slow Gerrit must not hold up the events behind it.
+var filesLookupTimeout = 5 * time.Second
+
 // Filter filters Gerrit events based on configuration
 type Filter struct {
-	config FilterConfig
+	projects, excludeProjects   pattern.List
+	branches, excludeBranches   pattern.List
+	owners, excludeOwners       pattern.List
+	uploaders, excludeUploaders pattern.List
+	hashtags, excludeHashtags   pattern.List
+	topics, excludeTopics       pattern.List
+	files, excludeFiles         pattern.List
+	skipWIP, skipPrivate        bool
+	skipKinds                   map[string]bool
+
+	scoped []scopedFilter
+
+	filesLookup FilesLookup
+	log         *logger.Logger
 }
 
-// NewFilter creates a new event filter
-func NewFilter(config FilterConfig) *Filter {
-	return &Filter{config: config}
+// scopedFilter holds rules that only apply to some projects and branches
+type scopedFilter struct {
+	name               string
+	projects, branches pattern.List
+	rules              *Filter
 }
 
-// ShouldProcess returns true if the event should be processed
-func (f *Filter) ShouldProcess(event Event) bool {
-	// Only patchset-created events
-	if event.Type != "patchset-created" {
-		return false
+// NewFilter creates a new event filter. It fails if a pattern is an invalid
+// regular expression.
+func NewFilter(config FilterConfig) (*Filter, error) {
+	f := &Filter{
+		skipWIP:     config.SkipWIP,
+		skipPrivate: config.SkipPrivate,
+		skipKinds:   make(map[string]bool, len(config.SkipKinds)),
+		log:         logger.Get(),
 	}
 
-	if event.Change == nil {
-		return false
+	lists := []struct {
+		dst  *pattern.List
+		src  []string
+		name string
+	}{
+		{&f.projects, config.Projects, "projects"},
+		{&f.excludeProjects, config.Exclude, "exclude"},
+		{&f.branches, config.Branches, "branches"},
+		{&f.excludeBranches, config.ExcludeBranches, "exclude_branches"},
+		{&f.owners, config.Owners, "owners"},
+		{&f.excludeOwners, config.ExcludeOwners, "exclude_owners"},
+		{&f.uploaders, config.Uploaders, "uploaders"},
+		{&f.excludeUploaders, config.ExcludeUploaders, "exclude_uploaders"},
+		{&f.hashtags, config.Hashtags, "hashtags"},
+		{&f.excludeHashtags, config.ExcludeHashtags, "exclude_hashtags"},
+		{&f.topics, config.Topics, "topics"},
+		{&f.excludeTopics, config.ExcludeTopics, "exclude_topics"},
+		{&f.files, config.Files, "files"},
+		{&f.excludeFiles, config.ExcludeFiles, "exclude_files"},
+	}
+	for _, l := range lists {
+		compiled, err := pattern.Compile(l.src)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s filter: %w", l.name, err)
+		}
+		*l.dst = compiled
+	}
+
+	for _, kind := range config.SkipKinds {
+		if kind = strings.ToUpper(strings.TrimSpace(kind)); kind != "" {
+			f.skipKinds[kind] = true
+		}
+	}
+
+	return f, nil
+}
+
+// SetFilesLookup sets how changed files are fetched for the files and
+// exclude_files rules. Without it those rules are not applied.
+func (f *Filter) SetFilesLookup(lookup FilesLookup) {
+	f.filesLookup = lookup
+	for _, s := range f.scoped {
+		s.rules.SetFilesLookup(lookup)
+	}
+}
+
+// AddScoped adds rules that apply, on top of the filter's own, to events of
+// projects matching project and branches matching one of branches (empty =
+// all). name identifies the rules in the descriptions returned by Check.
+func (f *Filter) AddScoped(name, project string, branches []string, rules *Filter) error {
+	projects, err := pattern.Compile([]string{project})
+	if err != nil {
+		return fmt.Errorf("invalid %s project: %w", name, err)
+	}
+	branchList, err := pattern.Compile(branches)
+	if err != nil {
+		return fmt.Errorf("invalid %s branches: %w", name, err)
 	}
 
-	project := event.Change.Project
+	rules.SetFilesLookup(f.filesLookup)
+	f.scoped = append(f.scoped, scopedFilter{name: name, projects: projects, branches: branchList, rules: rules})
+	return nil
+}
 
-	// Check exclude list
-	for _, excl := range f.config.Exclude {
-		if strings.TrimSpace(excl) == project {
+// inScope reports whether the scoped rules apply to event
+func (s scopedFilter) inScope(event Event) bool {
+	if s.projects.Match(event.Project()) == "" {
+		return false
+	}
+	if len(s.branches) == 0 {
+		return true
+	}
+	return event.Change != nil && s.branches.Match(event.Change.Branch) != ""
+}
+
+// ShouldProcess returns true if the event belongs to a watched project and
+// branch. Events without a project are never processed.
+func (f *Filter) ShouldProcess(event Event) bool {
+	if f.checkScope(event) != "" {
+		return false
+	}
+	for _, s := range f.scoped {
+		if s.inScope(event) && !s.rules.ShouldProcess(event) {
 			return false
 		}
 	}
+	return true
+}
 
-	// If no whitelist, allow all (except excluded)
-	if len(f.config.Projects) == 0 {
+// SkipsWIP reports whether a skip_wip rule applies to the change of event,
+// so that its patchsets were skipped while it was work in progress
+func (f *Filter) SkipsWIP(event Event) bool {
+	return f.hasRule(event, func(r *Filter) bool { return r.skipWIP })
+}
+
+// SkipsPrivate reports whether a skip_private rule applies to the change of
+// event, so that its patchsets were skipped while it was private
+func (f *Filter) SkipsPrivate(event Event) bool {
+	return f.hasRule(event, func(r *Filter) bool { return r.skipPrivate })
+}
+
+// hasRule reports whether rule holds for the filter or scoped rules that
+// apply to event
+func (f *Filter) hasRule(event Event, rule func(*Filter) bool) bool {
+	if rule(f) {
 		return true
 	}
-
-	// Check whitelist
-	for _, allowed := range f.config.Projects {
-		if strings.TrimSpace(allowed) == project {
+	for _, s := range f.scoped {
+		if s.inScope(event) && s.rules.hasRule(event, rule) {
 			return true
 		}
 	}
-
 	return false
 }
+
+// Check applies every rule to a patchset event. It returns a description of
+// the rule that rejected the event, or "" if the patchset should be reviewed.
+func (f *Filter) Check(ctx context.Context, event Event) string {
+	if rule := f.check(ctx, event); rule != "" {
+		return rule
+	}
+	for _, s := range f.scoped {
+		if !s.inScope(event) {
+			continue
+		}
+		if rule := s.rules.Check(ctx, event); rule != "" {
+			return s.name + " " + rule
+		}
+	}
+	return ""
+}
+
+// check applies the filter's own rules, without the scoped ones
+func (f *Filter) check(ctx context.Context, event Event) string {
+	if rule := f.checkScope(event); rule != "" {
+		return rule
+	}
+	if event.Change == nil {
+		return "event has no change"
+	}
+	change := event.Change
+
+	if f.skipWIP && change.WIP {
+		return "skip_wip: change is work in progress"
+	}
+	if f.skipPrivate && change.Private {
+		return "skip_private: change is private"
+	}
+	if event.PatchSet != nil && f.skipKinds[event.PatchSet.Kind] {
+		return fmt.Sprintf("skip_kinds: patchset kind is %s", event.PatchSet.Kind)
+	}
+
+	if rule := checkAccount("owners", "exclude_owners", f.owners, f.excludeOwners, change.Owner); rule != "" {
+		return rule
+	}
+	var uploader *Account
+	if event.PatchSet != nil {
+		uploader = event.PatchSet.Uploader
+	}
+	if rule := checkAccount("uploaders", "exclude_uploaders", f.uploaders, f.excludeUploaders, uploader); rule != "" {
+		return rule
+	}
+
+	if p := f.excludeHashtags.MatchAny(change.Hashtags); p != "" {
+		return fmt.Sprintf("exclude_hashtags: hashtag matches %q", p)
+	}
+	if len(f.hashtags) > 0 && f.hashtags.MatchAny(change.Hashtags) == "" {
+		return "hashtags: no hashtag matches"
+	}
+	if p := f.excludeTopics.Match(change.Topic); p != "" {
+		return fmt.Sprintf("exclude_topics: topic %q matches %q", change.Topic, p)
+	}
+	if len(f.topics) > 0 && f.topics.Match(change.Topic) == "" {
+		return fmt.Sprintf("topics: topic %q does not match", change.Topic)
+	}
+
+	return f.checkFiles(ctx, event)
+}
+
+// checkScope applies the project and branch rules
+func (f *Filter) checkScope(event Event) string {
+	project := event.Project()
+	if project == "" {
+		return "event has no project"
+	}
+
+	if p := f.excludeProjects.Match(project); p != "" {
+		return fmt.Sprintf("exclude: project %q matches %q", project, p)
+	}
+	if len(f.projects) > 0 && f.projects.Match(project) == "" {
+		return fmt.Sprintf("projects: project %q does not match", project)
+	}
+
+	if event.Change == nil {
+		return ""
+	}
+	branch := event.Change.Branch
+	if p := f.excludeBranches.Match(branch); p != "" {
+		return fmt.Sprintf("exclude_branches: branch %q matches %q", branch, p)
+	}
+	if len(f.branches) > 0 && f.branches.Match(branch) == "" {
+		return fmt.Sprintf("branches: branch %q does not match", branch)
+	}
+
+	return ""
+}
+
+// checkFiles applies the changed-file rules. Lookup failures let the
+// patchset through so that a REST hiccup never silently drops a review.
+func (f *Filter) checkFiles(ctx context.Context, event Event) string {
+	if (len(f.files) == 0 && len(f.excludeFiles) == 0) || f.filesLookup == nil {
+		return ""
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, filesLookupTimeout)
+	defer cancel()
+
+	files, err := f.filesLookup(lookupCtx, event)
+	if err != nil {
+		f.log.Warnf("Failed to list changed files, not applying file rules: %v", err)
+		return ""
+	}
+
+	if len(f.files) > 0 && f.files.MatchAny(files) == "" {
+		return "files: no changed file matches"
+	}
+	if len(f.excludeFiles) > 0 && len(files) > 0 && f.excludeFiles.MatchAll(files) {
+		return "exclude_files: every changed file is excluded"
+	}
+	return ""
+}
+
+// checkAccount applies an allow and a deny list to an account
+func checkAccount(allowName, denyName string, allow, deny pattern.List, account *Account) string {
+	ids := accountIDs(account)
+	if p := deny.MatchAny(ids); p != "" {
+		return fmt.Sprintf("%s: account matches %q", denyName, p)
+	}
+	if len(allow) > 0 && allow.MatchAny(ids) == "" {
+		return fmt.Sprintf("%s: account %v does not match", allowName, ids)
+	}
+	return ""
+}
+
+func accountIDs(account *Account) []string {
+	if account == nil {
+		return nil
+	}
+	var ids []string
+	for _, id := range []string{account.Username, account.Email, account.Name} {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,215 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestFilter(t *testing.T, cfg FilterConfig) *Filter {
+	t.Helper()
+
+	f, err := NewFilter(cfg)
+	if err != nil {
+		t.Fatalf("NewFilter failed: %v", err)
+	}
+	return f
+}
+
+func TestFilterShouldProcess(t *testing.T) {
+	filter := newTestFilter(t, FilterConfig{
+		Projects: []string{"platform/app", "platform/lib"},
+		Exclude:  []string{"platform/lib"},
+	})
+
+	tests := []struct {
+		name  string
+		event Event
+		want  bool
+	}{
+		{"watched patchset", Event{Type: TypePatchsetCreated, Change: &Change{Project: "platform/app"}}, true},
+		{"watched comment", Event{Type: TypeCommentAdded, Change: &Change{Project: "platform/app"}}, true},
+		{"watched ref update", Event{Type: TypeRefUpdated, RefUpdate: &RefUpdate{Project: "platform/app"}}, true},
+		{"excluded project", Event{Type: TypePatchsetCreated, Change: &Change{Project: "platform/lib"}}, false},
+		{"unwatched project", Event{Type: TypePatchsetCreated, Change: &Change{Project: "other"}}, false},
+		{"no project", Event{Type: TypePatchsetCreated}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.ShouldProcess(tt.event); got != tt.want {
+				t.Errorf("Expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFilterCheckRules(t *testing.T) {
+	filter := newTestFilter(t, FilterConfig{
+		Projects:         []string{"platform/**", "re:tools-(cli|web)"},
+		Exclude:          []string{"platform/*/legacy"},
+		ExcludeBranches:  []string{"release-*"},
+		ExcludeOwners:    []string{"re:.*-bot"},
+		Uploaders:        []string{"*@example.com"},
+		ExcludeHashtags:  []string{"no-ai"},
+		ExcludeTopics:    []string{"experiment-*"},
+		SkipWIP:          true,
+		SkipPrivate:      true,
+		SkipKinds:        []string{"trivial_rebase", "NO_CODE_CHANGE"},
+		ExcludeUploaders: []string{"mallory"},
+	})
+
+	event := func(mutate func(e *Event)) Event {
+		e := Event{
+			Type: TypePatchsetCreated,
+			Change: &Change{
+				Project: "platform/app",
+				Branch:  "main",
+				Owner:   &Account{Username: "alice"},
+			},
+			PatchSet: &PatchSet{
+				Number:   2,
+				Kind:     "REWORK",
+				Uploader: &Account{Username: "alice", Email: "alice@example.com"},
+			},
+		}
+		if mutate != nil {
+			mutate(&e)
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event Event
+		rule  string // expected rule prefix, "" to pass
+	}{
+		{"passes", event(nil), ""},
+		{"nested glob project", event(func(e *Event) { e.Change.Project = "platform/core/app" }), ""},
+		{"regex project", event(func(e *Event) { e.Change.Project = "tools-web" }), ""},
+		{"unmatched project", event(func(e *Event) { e.Change.Project = "tools-api" }), "projects:"},
+		{"excluded project", event(func(e *Event) { e.Change.Project = "platform/core/legacy" }), "exclude:"},
+		{"excluded branch", event(func(e *Event) { e.Change.Branch = "release-1.0" }), "exclude_branches:"},
+		{"work in progress", event(func(e *Event) { e.Change.WIP = true }), "skip_wip:"},
+		{"private", event(func(e *Event) { e.Change.Private = true }), "skip_private:"},
+		{"trivial rebase", event(func(e *Event) { e.PatchSet.Kind = "TRIVIAL_REBASE" }), "skip_kinds:"},
+		{"commit message only", event(func(e *Event) { e.PatchSet.Kind = "NO_CODE_CHANGE" }), "skip_kinds:"},
+		{"bot owner", event(func(e *Event) { e.Change.Owner = &Account{Username: "deps-bot"} }), "exclude_owners:"},
+		{"outside uploader", event(func(e *Event) { e.PatchSet.Uploader = &Account{Email: "eve@elsewhere.org"} }), "uploaders:"},
+		{"denied uploader", event(func(e *Event) {
+			e.PatchSet.Uploader = &Account{Username: "mallory", Email: "mallory@example.com"}
+		}), "exclude_uploaders:"},
+		{"opt-out hashtag", event(func(e *Event) { e.Change.Hashtags = []string{"feature", "no-ai"} }), "exclude_hashtags:"},
+		{"experiment topic", event(func(e *Event) { e.Change.Topic = "experiment-42" }), "exclude_topics:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := filter.Check(context.Background(), tt.event)
+			if tt.rule == "" && rule != "" {
+				t.Fatalf("Expected event to pass, rejected by %q", rule)
+			}
+			if !strings.HasPrefix(rule, tt.rule) {
+				t.Errorf("Expected rule %q, got %q", tt.rule, rule)
+			}
+		})
+	}
+}
+
+func TestFilterCheckFiles(t *testing.T) {
+	filter := newTestFilter(t, FilterConfig{
+		Files:        []string{"src/**"},
+		ExcludeFiles: []string{"**.md", "docs/**"},
+	})
+
+	var changed []string
+	var lookupErr error
+	filter.SetFilesLookup(func(ctx context.Context, event Event) ([]string, error) {
+		return changed, lookupErr
+	})
+
+	event := Event{Type: TypePatchsetCreated, Change: &Change{Project: "app"}, PatchSet: &PatchSet{Number: 1}}
+
+	changed = []string{"src/main.go", "README.md"}
+	if rule := filter.Check(context.Background(), event); rule != "" {
+		t.Errorf("Expected source change to pass, rejected by %q", rule)
+	}
+
+	changed = []string{"build/Makefile"}
+	if rule := filter.Check(context.Background(), event); !strings.HasPrefix(rule, "files:") {
+		t.Errorf("Expected files rule, got %q", rule)
+	}
+
+	changed = []string{"src/README.md", "src/docs/guide.md"}
+	if rule := filter.Check(context.Background(), event); !strings.HasPrefix(rule, "exclude_files:") {
+		t.Errorf("Expected exclude_files rule, got %q", rule)
+	}
+
+	lookupErr = errors.New("gerrit unavailable")
+	if rule := filter.Check(context.Background(), event); rule != "" {
+		t.Errorf("Expected lookup failure not to reject, got %q", rule)
+	}
+}
+
+func TestFilterCheckFilesTimesOut(t *testing.T) {
+	defer func(timeout time.Duration) { filesLookupTimeout = timeout }(filesLookupTimeout)
+	filesLookupTimeout = 50 * time.Millisecond
+
+	filter := newTestFilter(t, FilterConfig{Files: []string{"src/**"}})
+	filter.SetFilesLookup(func(ctx context.Context, event Event) ([]string, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	event := Event{Type: TypePatchsetCreated, Change: &Change{Project: "app"}, PatchSet: &PatchSet{Number: 1}}
+	start := time.Now()
+	if rule := filter.Check(context.Background(), event); rule != "" {
+		t.Errorf("Expected a timed out lookup not to reject, got %q", rule)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the lookup to be cut short, took %v", elapsed)
+	}
+}
+
+func TestFilterScopedRules(t *testing.T) {
+	filter := newTestFilter(t, FilterConfig{ExcludeHashtags: []string{"no-ai"}})
+	scoped := newTestFilter(t, FilterConfig{SkipWIP: true, ExcludeBranches: []string{"sandbox/*"}})
+	if err := filter.AddScoped("projects[0]", "monorepo", []string{"main", "sandbox/*"}, scoped); err != nil {
+		t.Fatalf("AddScoped failed: %v", err)
+	}
+
+	wip := func(project, branch string) Event {
+		return Event{Type: TypePatchsetCreated, Change: &Change{Project: project, Branch: branch, WIP: true}, PatchSet: &PatchSet{Number: 1}}
+	}
+
+	if rule := filter.Check(context.Background(), wip("service", "main")); rule != "" {
+		t.Errorf("Expected scoped rules not to apply to other projects, rejected by %q", rule)
+	}
+	if rule := filter.Check(context.Background(), wip("monorepo", "dev")); rule != "" {
+		t.Errorf("Expected scoped rules not to apply to other branches, rejected by %q", rule)
+	}
+	if rule := filter.Check(context.Background(), wip("monorepo", "main")); !strings.HasPrefix(rule, "projects[0] skip_wip:") {
+		t.Errorf("Expected scoped skip_wip rule, got %q", rule)
+	}
+
+	event := wip("monorepo", "main")
+	event.Change.Hashtags = []string{"no-ai"}
+	if rule := filter.Check(context.Background(), event); !strings.HasPrefix(rule, "exclude_hashtags:") {
+		t.Errorf("Expected global rules to apply first, got %q", rule)
+	}
+
+	if filter.ShouldProcess(wip("monorepo", "sandbox/x")) {
+		t.Errorf("Expected scoped exclude_branches to apply to ShouldProcess")
+	}
+	if !filter.ShouldProcess(wip("monorepo", "main")) {
+		t.Errorf("Expected scoped project to be processed")
+	}
+}
+
+func TestNewFilterRejectsInvalidRegexp(t *testing.T) {
+	if _, err := NewFilter(FilterConfig{Branches: []string{"re:release-("}}); err == nil {
+		t.Fatalf("expected NewFilter to fail for invalid regexp")
+	}
+}
@@ -5,26 +5,61 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
 )
 
+// streamEventsCommand builds the stream-events command subscribing to types
+func streamEventsCommand(types []string) string {
+	var b strings.Builder
+	b.WriteString("gerrit stream-events")
+	for _, t := range types {
+		b.WriteString(" -s ")
+		b.WriteString(t)
+	}
+	return b.String()
+}
+
 // Listener listens to Gerrit stream-events via SSH
 type Listener struct {
-	sshAlias string
-	log      *logger.Logger
+	transport Transport
+	types     []string
+	watermark *Watermark
+	catchUp   func(ctx context.Context, since time.Time) ([]Event, error)
+	log       *logger.Logger
 }
 
-// NewListener creates a new event listener
+// NewListener creates a new event listener that uses the system ssh binary
 func NewListener(sshAlias string) *Listener {
+	return NewListenerWithTransport(NewExecTransport(sshAlias))
+}
+
+// NewListenerWithTransport creates a new event listener on the given transport
+func NewListenerWithTransport(transport Transport) *Listener {
 	return &Listener{
-		sshAlias: sshAlias,
-		log:      logger.Get(),
+		transport: transport,
+		types:     DefaultTypes,
+		log:       logger.Get(),
 	}
 }
 
+// SetEventTypes sets the event types to subscribe to (default: DefaultTypes)
+func (l *Listener) SetEventTypes(types []string) {
+	l.types = types
+}
+
+// SetCatchUp registers a function run after every (re)connect. It receives
+// the watermark as it was before connecting, so events processed while it
+// runs cannot move its window past the gap. The events it returns are
+// delivered on the event channel alongside the live stream, so patchsets
+// uploaded while disconnected are not lost.
+func (l *Listener) SetCatchUp(watermark *Watermark, fn func(ctx context.Context, since time.Time) ([]Event, error)) {
+	l.watermark = watermark
+	l.catchUp = fn
+}
+
 // StreamEvents opens SSH connection and returns channel of events
 // It automatically reconnects on connection failures
 func (l *Listener) StreamEvents(ctx context.Context) (<-chan Event, error) {
@@ -44,10 +79,13 @@ func (l *Listener) StreamEvents(ctx context.Context) (<-chan Event, error) {
 			}
 
 			if err := l.streamOnce(ctx, eventCh); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
 				retries++
 				waitTime := l.getBackoff(retries)
-				l.log.Warnf("Connection lost (attempt %d/%d), reconnecting in %v",
-					retries, maxRetries, waitTime)
+				l.log.Warnf("Connection lost (attempt %d/%d), reconnecting in %v: %v",
+					retries, maxRetries, waitTime, err)
 
 				select {
 				case <-time.After(waitTime):
@@ -68,29 +106,26 @@ func (l *Listener) StreamEvents(ctx context.Context) (<-chan Event, error) {
 
 // streamOnce establishes one SSH connection and streams events
 func (l *Listener) streamOnce(ctx context.Context, eventCh chan<- Event) error {
-	// Build SSH command
-	// ssh gerrit-review -o ServerAliveInterval=30 -o ServerAliveCountMax=3 gerrit stream-events -s patchset-created
-	cmd := exec.CommandContext(ctx,
-		"ssh", l.sshAlias,
-		"-o", "ServerAliveInterval=30",
-		"-o", "ServerAliveCountMax=3",
-		"gerrit", "stream-events",
-		"-s", "patchset-created",
-	)
-
-	l.log.Infof("Connecting to %s...", l.sshAlias)
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to get stdout pipe: %w", err)
+	l.log.Infof("Connecting to %s...", l.transport)
+
+	// Take the catch-up window before the stream starts delivering events
+	var since time.Time
+	if l.watermark != nil {
+		since = l.watermark.Last()
 	}
 
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start SSH: %w", err)
+	stdout, err := l.transport.Open(ctx, streamEventsCommand(l.types))
+	if err != nil {
+		return err
 	}
+	defer stdout.Close()
 
 	l.log.Infof("🎧 Connected, listening for events...")
 
+	if l.catchUp != nil {
+		go l.runCatchUp(ctx, since, eventCh)
+	}
+
 	// Read events line by line
 	scanner := bufio.NewScanner(stdout)
 	for scanner.Scan() {
@@ -109,18 +144,38 @@ func (l *Listener) streamOnce(ctx context.Context, eventCh chan<- Event) error {
 		select {
 		case eventCh <- event:
 		case <-ctx.Done():
-			if cmd.Process != nil {
-				cmd.Process.Kill()
-			}
 			return ctx.Err()
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return fmt.Errorf("scanner error: %w", err)
 	}
 
-	return cmd.Wait()
+	return nil
+}
+
+// runCatchUp delivers the events missed while disconnected
+func (l *Listener) runCatchUp(ctx context.Context, since time.Time, eventCh chan<- Event) {
+	missed, err := l.catchUp(ctx, since)
+	if err != nil {
+		l.log.Warnf("Catch-up failed: %v", err)
+		return
+	}
+	if len(missed) > 0 {
+		l.log.Infof("Catch-up found %d missed patchset(s)", len(missed))
+	}
+
+	for _, event := range missed {
+		select {
+		case eventCh <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
 }
 
 // getBackoff returns the wait time before next retry
@@ -0,0 +1,280 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHConfig configures the in-process SSH transport
+type SSHConfig struct {
+	Host           string
+	Port           int
+	User           string
+	KeyFile        string        // Private key used for public key authentication
+	KnownHostsFile string        // OpenSSH known_hosts file used to verify the server
+	Keepalive      time.Duration // Interval between keepalive requests; 0 disables them
+	DialTimeout    time.Duration // Timeout for TCP connect and SSH handshake
+}
+
+// keepaliveMaxMissed is how many consecutive keepalives may fail before the
+// connection is considered dead, matching ServerAliveCountMax=3.
+const keepaliveMaxMissed = 3
+
+// sshTransport talks to Gerrit with an in-process SSH client, so serve mode
+// does not depend on an ssh binary or ~/.ssh/config.
+type sshTransport struct {
+	addr        string
+	config      *ssh.ClientConfig
+	keepalive   time.Duration
+	dialTimeout time.Duration
+}
+
+// NewSSHTransport creates an in-process SSH transport. The key and known_hosts
+// files are loaded up front so configuration mistakes surface at startup.
+func NewSSHTransport(cfg SSHConfig) (Transport, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("ssh host is required")
+	}
+	if cfg.User == "" {
+		return nil, fmt.Errorf("ssh user is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 29418
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = 30 * time.Second
+	}
+
+	keyFile, err := expandHome(cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	keyData, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ssh key file: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(keyData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ssh key file %s: %w", keyFile, err)
+	}
+
+	knownHostsFile, err := expandHome(cfg.KnownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known_hosts file: %w", err)
+	}
+
+	return &sshTransport{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		config: &ssh.ClientConfig{
+			User:            cfg.User,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         cfg.DialTimeout,
+		},
+		keepalive:   cfg.Keepalive,
+		dialTimeout: cfg.DialTimeout,
+	}, nil
+}
+
+func (t *sshTransport) String() string {
+	return t.config.User + "@" + t.addr
+}
+
+func (t *sshTransport) Open(ctx context.Context, command string) (io.ReadCloser, error) {
+	client, err := t.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, t.connectError(StageSession, err)
+	}
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+
+	if err := session.Start(command); err != nil {
+		client.Close()
+		return nil, t.connectError(StageSession, err)
+	}
+
+	done := make(chan struct{})
+	var keepaliveErr error
+	var keepaliveMu sync.Mutex
+	if t.keepalive > 0 {
+		go func() {
+			if err := t.runKeepalive(client, done); err != nil {
+				keepaliveMu.Lock()
+				keepaliveErr = err
+				keepaliveMu.Unlock()
+				client.Close()
+			}
+		}()
+	}
+
+	stopCancel := context.AfterFunc(ctx, func() { client.Close() })
+
+	var closeOnce sync.Once
+	stop := func() {
+		closeOnce.Do(func() {
+			stopCancel()
+			close(done)
+			client.Close()
+		})
+	}
+
+	return &commandReader{
+		r: stdout,
+		wait: func() error {
+			err := session.Wait()
+			stop()
+
+			keepaliveMu.Lock()
+			defer keepaliveMu.Unlock()
+			if keepaliveErr != nil {
+				return t.connectError(StageCommand, keepaliveErr)
+			}
+			if err != nil {
+				return t.connectError(StageCommand, err)
+			}
+			return nil
+		},
+		stop: stop,
+	}, nil
+}
+
+func (t *sshTransport) Run(ctx context.Context, command string) ([]byte, error) {
+	client, err := t.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, t.connectError(StageSession, err)
+	}
+	defer session.Close()
+
+	output, err := session.CombinedOutput(command)
+	if err != nil {
+		return output, t.connectError(StageCommand, err)
+	}
+	return output, nil
+}
+
+// dial connects and authenticates, classifying failures by stage
+func (t *sshTransport) dial(ctx context.Context) (*ssh.Client, error) {
+	dialer := net.Dialer{Timeout: t.dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return nil, t.connectError(StageDial, err)
+	}
+
+	// Bound the handshake; ssh.NewClientConn does not take a context
+	conn.SetDeadline(time.Now().Add(t.dialTimeout))
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, t.addr, t.config)
+	stop()
+	if err != nil {
+		conn.Close()
+		return nil, t.connectError(handshakeStage(err), err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// runKeepalive sends keepalive requests until done is closed, returning an
+// error once keepaliveMaxMissed consecutive requests have failed.
+func (t *sshTransport) runKeepalive(client *ssh.Client, done <-chan struct{}) error {
+	ticker := time.NewTicker(t.keepalive)
+	defer ticker.Stop()
+
+	missed := 0
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-ticker.C:
+		}
+
+		if keepaliveOK(client, t.keepalive) {
+			missed = 0
+			continue
+		}
+		missed++
+		if missed >= keepaliveMaxMissed {
+			return fmt.Errorf("no keepalive response after %d attempts", missed)
+		}
+	}
+}
+
+// keepaliveOK sends one keepalive request and waits up to timeout for a reply
+func keepaliveOK(client *ssh.Client, timeout time.Duration) bool {
+	result := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		return err == nil
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (t *sshTransport) connectError(stage string, err error) error {
+	return &ConnectError{Stage: stage, Addr: t.addr, Err: err}
+}
+
+// handshakeStage tells host key and authentication failures apart from other
+// handshake errors.
+func handshakeStage(err error) string {
+	var keyErr *knownhosts.KeyError
+	var revokedErr *knownhosts.RevokedError
+	switch {
+	case errors.As(err, &keyErr), errors.As(err, &revokedErr):
+		return StageHostKey
+	case strings.Contains(err.Error(), "unable to authenticate"):
+		return StageAuth
+	default:
+		return StageHandshake
+	}
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
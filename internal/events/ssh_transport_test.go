@@ -0,0 +1,423 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// fakeSSHServer is an in-process stand-in for Gerrit's SSH daemon. Every exec
+// request is answered by the handler for its command; the handler's second
+// argument is closed when the client disconnects.
+type fakeSSHServer struct {
+	addr    string
+	hostKey ssh.PublicKey
+
+	// ignoreKeepalive stops answering global requests, simulating a dead peer
+	ignoreKeepalive bool
+
+	mu       sync.Mutex
+	commands []string
+	handlers map[string]func(ch ssh.Channel, disconnected <-chan struct{})
+}
+
+func newFakeSSHServer(t *testing.T, authorized ssh.PublicKey) *fakeSSHServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("skipping network-dependent test: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("failed to create host signer: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "reviewer" && string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key for %s", conn.User())
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	s := &fakeSSHServer{
+		addr:     listener.Addr().String(),
+		hostKey:  hostSigner.PublicKey(),
+		handlers: make(map[string]func(ch ssh.Channel, disconnected <-chan struct{})),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveConn(conn, config)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeSSHServer) handle(command string, handler func(ch ssh.Channel, disconnected <-chan struct{})) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[command] = handler
+}
+
+func (s *fakeSSHServer) executed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *fakeSSHServer) serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	disconnected := make(chan struct{})
+	go func() {
+		sshConn.Wait()
+		close(disconnected)
+	}()
+	if s.ignoreKeepalive {
+		go func() {
+			for range reqs {
+			}
+		}()
+	} else {
+		go ssh.DiscardRequests(reqs)
+	}
+
+	for newCh := range chans {
+		if newCh.ChannelType() != "session" {
+			newCh.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		ch, chReqs, err := newCh.Accept()
+		if err != nil {
+			continue
+		}
+		go s.serveSession(ch, chReqs, disconnected)
+	}
+}
+
+func (s *fakeSSHServer) serveSession(ch ssh.Channel, reqs <-chan *ssh.Request, disconnected <-chan struct{}) {
+	defer ch.Close()
+
+	for req := range reqs {
+		if req.Type != "exec" {
+			req.Reply(false, nil)
+			continue
+		}
+
+		var payload struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+			req.Reply(false, nil)
+			return
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, payload.Command)
+		handler := s.handlers[payload.Command]
+		s.mu.Unlock()
+
+		req.Reply(true, nil)
+		status := uint32(0)
+		if handler != nil {
+			handler(ch, disconnected)
+		} else {
+			fmt.Fprintf(ch.Stderr(), "gerrit: %s: not found\n", payload.Command)
+			status = 1
+		}
+		ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		return
+	}
+}
+
+// writeClientKey writes a fresh private key file and returns its path and public key
+func writeClientKey(t *testing.T, dir string) (string, ssh.PublicKey) {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate client key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("failed to marshal client key: %v", err)
+	}
+	path := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("failed to write client key: %v", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("failed to create client signer: %v", err)
+	}
+	return path, signer.PublicKey()
+}
+
+// writeKnownHosts writes a known_hosts file trusting key for addr
+func writeKnownHosts(t *testing.T, dir, addr string, key ssh.PublicKey) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, key) + "\n"
+	if err := os.WriteFile(path, []byte(line), 0600); err != nil {
+		t.Fatalf("failed to write known_hosts: %v", err)
+	}
+	return path
+}
+
+func sshTestConfig(t *testing.T, server *fakeSSHServer, keyFile, knownHosts string) SSHConfig {
+	t.Helper()
+
+	host, portStr, err := net.SplitHostPort(server.addr)
+	if err != nil {
+		t.Fatalf("failed to split server address: %v", err)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	return SSHConfig{
+		Host:           host,
+		Port:           port,
+		User:           "reviewer",
+		KeyFile:        keyFile,
+		KnownHostsFile: knownHosts,
+		DialTimeout:    5 * time.Second,
+	}
+}
+
+// newTestSSHTransport starts a fake server trusting a fresh client key and
+// returns a transport configured for it.
+func newTestSSHTransport(t *testing.T) (*fakeSSHServer, SSHConfig) {
+	t.Helper()
+
+	dir := t.TempDir()
+	keyFile, clientKey := writeClientKey(t, dir)
+	server := newFakeSSHServer(t, clientKey)
+	knownHosts := writeKnownHosts(t, dir, server.addr, server.hostKey)
+
+	return server, sshTestConfig(t, server, keyFile, knownHosts)
+}
+
+func TestSSHTransport_StreamEvents(t *testing.T) {
+	server, cfg := newTestSSHTransport(t)
+	server.handle(streamEventsCommand(DefaultTypes), func(ch ssh.Channel, disconnected <-chan struct{}) {
+		fmt.Fprintln(ch, `{"type":"patchset-created","change":{"project":"platform/app","number":42},"patchSet":{"number":1},"eventCreatedOn":1700000000}`)
+		fmt.Fprintln(ch, `not json`)
+		fmt.Fprintln(ch, `{"type":"change-merged","change":{"project":"platform/app","number":41},"eventCreatedOn":1700000001}`)
+		// Keep the stream open like Gerrit does until the client goes away
+		<-disconnected
+	})
+
+	transport, err := NewSSHTransport(cfg)
+	if err != nil {
+		t.Fatalf("NewSSHTransport failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventCh, err := NewListenerWithTransport(transport).StreamEvents(ctx)
+	if err != nil {
+		t.Fatalf("StreamEvents failed: %v", err)
+	}
+
+	var got []Event
+	for len(got) < 2 {
+		select {
+		case event := <-eventCh:
+			got = append(got, event)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for events, got %d", len(got))
+		}
+	}
+
+	if got[0].Type != "patchset-created" || got[0].Change.Number != 42 || got[0].PatchSet.Number != 1 {
+		t.Errorf("Unexpected first event: %+v", got[0])
+	}
+	if got[1].Type != "change-merged" || got[1].Change.Number != 41 {
+		t.Errorf("Unexpected second event: %+v", got[1])
+	}
+	if commands := server.executed(); len(commands) != 1 || commands[0] != streamEventsCommand(DefaultTypes) {
+		t.Errorf("Expected command %q, got %v", streamEventsCommand(DefaultTypes), commands)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-eventCh:
+		if ok {
+			t.Fatalf("expected event channel to be closed after cancel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for event channel to close")
+	}
+}
+
+func TestSSHTransport_Run(t *testing.T) {
+	server, cfg := newTestSSHTransport(t)
+	server.handle("gerrit version", func(ch ssh.Channel, disconnected <-chan struct{}) {
+		fmt.Fprintln(ch, "gerrit version 3.9.1")
+	})
+
+	transport, err := NewSSHTransport(cfg)
+	if err != nil {
+		t.Fatalf("NewSSHTransport failed: %v", err)
+	}
+
+	output, err := transport.Run(context.Background(), "gerrit version")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if string(output) != "gerrit version 3.9.1\n" {
+		t.Errorf("Expected version output, got %q", output)
+	}
+
+	_, err = transport.Run(context.Background(), "gerrit ls-projects")
+	var connErr *ConnectError
+	if !errors.As(err, &connErr) || connErr.Stage != StageCommand {
+		t.Fatalf("expected command stage ConnectError, got %v", err)
+	}
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 1 {
+		t.Errorf("Expected wrapped exit status 1, got %v", err)
+	}
+}
+
+func TestSSHTransport_ConnectErrors(t *testing.T) {
+	dir := t.TempDir()
+	keyFile, clientKey := writeClientKey(t, dir)
+	server := newFakeSSHServer(t, clientKey)
+
+	otherDir := t.TempDir()
+	otherKeyFile, otherKey := writeClientKey(t, otherDir)
+
+	closed, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("skipping network-dependent test: %v", err)
+	}
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	tests := []struct {
+		name  string
+		cfg   SSHConfig
+		stage string
+	}{
+		{
+			name:  "unknown host key",
+			cfg:   sshTestConfig(t, server, keyFile, writeKnownHosts(t, otherDir, server.addr, otherKey)),
+			stage: StageHostKey,
+		},
+		{
+			name:  "unauthorized key",
+			cfg:   sshTestConfig(t, server, otherKeyFile, writeKnownHosts(t, dir, server.addr, server.hostKey)),
+			stage: StageAuth,
+		},
+		{
+			name:  "connection refused",
+			cfg:   sshTestConfig(t, &fakeSSHServer{addr: closedAddr}, keyFile, filepath.Join(dir, "known_hosts")),
+			stage: StageDial,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := NewSSHTransport(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewSSHTransport failed: %v", err)
+			}
+
+			_, err = transport.Open(context.Background(), streamEventsCommand(DefaultTypes))
+			var connErr *ConnectError
+			if !errors.As(err, &connErr) {
+				t.Fatalf("expected ConnectError, got %v", err)
+			}
+			if connErr.Stage != tt.stage {
+				t.Errorf("Expected stage %s, got %s (%v)", tt.stage, connErr.Stage, err)
+			}
+		})
+	}
+}
+
+func TestSSHTransport_KeepaliveDetectsDeadPeer(t *testing.T) {
+	server, cfg := newTestSSHTransport(t)
+	server.ignoreKeepalive = true
+	server.handle(streamEventsCommand(DefaultTypes), func(ch ssh.Channel, disconnected <-chan struct{}) {
+		<-disconnected
+	})
+	cfg.Keepalive = 50 * time.Millisecond
+
+	transport, err := NewSSHTransport(cfg)
+	if err != nil {
+		t.Fatalf("NewSSHTransport failed: %v", err)
+	}
+
+	stdout, err := transport.Open(context.Background(), streamEventsCommand(DefaultTypes))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer stdout.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+		}
+		errCh <- scanner.Err()
+	}()
+
+	select {
+	case err := <-errCh:
+		var connErr *ConnectError
+		if !errors.As(err, &connErr) {
+			t.Fatalf("expected ConnectError, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for keepalive failure")
+	}
+}
+
+func TestNewSSHTransport_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	keyFile, _ := writeClientKey(t, dir)
+
+	if _, err := NewSSHTransport(SSHConfig{Host: "gerrit", User: "reviewer", KeyFile: filepath.Join(dir, "missing"), KnownHostsFile: keyFile}); err == nil {
+		t.Errorf("Expected error for missing key file")
+	}
+	if _, err := NewSSHTransport(SSHConfig{Host: "gerrit", User: "reviewer", KeyFile: keyFile, KnownHostsFile: filepath.Join(dir, "missing")}); err == nil {
+		t.Errorf("Expected error for missing known_hosts file")
+	}
+	if _, err := NewSSHTransport(SSHConfig{User: "reviewer", KeyFile: keyFile}); err == nil {
+		t.Errorf("Expected error for missing host")
+	}
+}
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Transport runs Gerrit SSH commands for the listener and preflight checks.
+type Transport interface {
+	// Open starts a long-running command and returns its stdout. Reading
+	// returns the command's failure instead of io.EOF when it exits with an
+	// error. Closing the reader ends the command.
+	Open(ctx context.Context, command string) (io.ReadCloser, error)
+	// Run executes a command and returns its combined output.
+	Run(ctx context.Context, command string) ([]byte, error)
+	// String describes the endpoint for logs.
+	String() string
+}
+
+// Connection failure stages reported by ConnectError
+const (
+	StageDial      = "dial"
+	StageHandshake = "handshake"
+	StageHostKey   = "hostkey"
+	StageAuth      = "auth"
+	StageSession   = "session"
+	StageCommand   = "command"
+)
+
+// ConnectError is a structured SSH connection failure.
+type ConnectError struct {
+	Stage string // One of the Stage* constants
+	Addr  string // host:port or SSH alias
+	Err   error
+}
+
+func (e *ConnectError) Error() string {
+	return fmt.Sprintf("ssh %s %s failed: %v", e.Addr, e.Stage, e.Err)
+}
+
+func (e *ConnectError) Unwrap() error {
+	return e.Err
+}
+
+// execTransport shells out to the system ssh binary with a config alias
+type execTransport struct {
+	sshAlias string
+}
+
+// NewExecTransport creates a transport that runs `ssh <alias> <command>`,
+// leaving host, user, keys and host-key checking to ~/.ssh/config.
+func NewExecTransport(sshAlias string) Transport {
+	return &execTransport{sshAlias: sshAlias}
+}
+
+func (t *execTransport) String() string {
+	return t.sshAlias
+}
+
+func (t *execTransport) args(command string) []string {
+	args := []string{
+		t.sshAlias,
+		"-o", "ServerAliveInterval=30",
+		"-o", "ServerAliveCountMax=3",
+	}
+	return append(args, strings.Fields(command)...)
+}
+
+func (t *execTransport) Open(ctx context.Context, command string) (io.ReadCloser, error) {
+	cmd := exec.CommandContext(ctx, "ssh", t.args(command)...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, &ConnectError{Stage: StageDial, Addr: t.sshAlias, Err: fmt.Errorf("failed to start SSH: %w", err)}
+	}
+
+	return &commandReader{
+		r: stdout,
+		wait: func() error {
+			if err := cmd.Wait(); err != nil {
+				return &ConnectError{Stage: StageCommand, Addr: t.sshAlias, Err: err}
+			}
+			return nil
+		},
+		stop: func() {
+			if cmd.Process != nil {
+				cmd.Process.Kill()
+			}
+		},
+	}, nil
+}
+
+func (t *execTransport) Run(ctx context.Context, command string) ([]byte, error) {
+	output, err := exec.CommandContext(ctx, "ssh", t.args(command)...).CombinedOutput()
+	if err != nil {
+		return output, &ConnectError{Stage: StageCommand, Addr: t.sshAlias, Err: err}
+	}
+	return output, nil
+}
+
+// commandReader adapts a command's stdout to Transport.Open semantics
+type commandReader struct {
+	r    io.Reader
+	wait func() error // Waits for the command and returns its failure
+	stop func()       // Terminates the command
+
+	waited  bool
+	waitErr error
+}
+
+func (c *commandReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if errors.Is(err, io.EOF) {
+		if waitErr := c.finish(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (c *commandReader) Close() error {
+	c.stop()
+	c.finish()
+	return nil
+}
+
+func (c *commandReader) finish() error {
+	if !c.waited {
+		c.waited = true
+		c.waitErr = c.wait()
+	}
+	return c.waitErr
+}
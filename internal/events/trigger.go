@@ -0,0 +1,49 @@
+package events
+
+import (
+	"strings"
+)
+
+// Trigger is an on-demand review request found in a comment, such as
+// "/ai-review focus:security incremental".
+type Trigger struct {
+	Focus       []string // Areas to concentrate on, from focus:<area>[,<area>...]
+	Incremental bool     // Review only what changed since the previous patchset
+	Unknown     []string // Options that were not understood
+}
+
+// ParseTrigger looks for a line starting with command in a comment-added
+// message and parses the options following it.
+func ParseTrigger(comment, command string) (Trigger, bool) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return Trigger{}, false
+	}
+
+	for _, line := range strings.Split(comment, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.EqualFold(fields[0], command) {
+			continue
+		}
+
+		var trigger Trigger
+		for _, option := range fields[1:] {
+			name, value, _ := strings.Cut(option, ":")
+			switch strings.ToLower(name) {
+			case "incremental":
+				trigger.Incremental = true
+			case "focus":
+				for _, area := range strings.Split(value, ",") {
+					if area = strings.TrimSpace(area); area != "" {
+						trigger.Focus = append(trigger.Focus, area)
+					}
+				}
+			default:
+				trigger.Unknown = append(trigger.Unknown, option)
+			}
+		}
+		return trigger, true
+	}
+
+	return Trigger{}, false
+}
@@ -1,21 +1,81 @@
 package events
 
+// Gerrit stream-events types handled by serve mode
+const (
+	TypePatchsetCreated     = "patchset-created"
+	TypeCommentAdded        = "comment-added"
+	TypeChangeAbandoned     = "change-abandoned"
+	TypeChangeMerged        = "change-merged"
+	TypeWIPStateChanged     = "wip-state-changed"
+	TypePrivateStateChanged = "private-state-changed"
+	TypeReviewerAdded       = "reviewer-added"
+	TypeRefUpdated          = "ref-updated"
+)
+
+// SupportedTypes lists every event type serve mode can subscribe to
+var SupportedTypes = []string{
+	TypePatchsetCreated,
+	TypeCommentAdded,
+	TypeChangeAbandoned,
+	TypeChangeMerged,
+	TypeWIPStateChanged,
+	TypePrivateStateChanged,
+	TypeReviewerAdded,
+	TypeRefUpdated,
+}
+
+// DefaultTypes are the event types subscribed to when none are configured
+var DefaultTypes = []string{
+	TypePatchsetCreated,
+	TypeCommentAdded,
+	TypeChangeAbandoned,
+	TypeChangeMerged,
+}
+
 // Event represents a Gerrit stream-events JSON line
 type Event struct {
-	Type           string    `json:"type"`
-	Change         *Change   `json:"change,omitempty"`
-	PatchSet       *PatchSet `json:"patchSet,omitempty"`
-	EventCreatedOn int64     `json:"eventCreatedOn"`
+	Type           string     `json:"type"`
+	Change         *Change    `json:"change,omitempty"`
+	PatchSet       *PatchSet  `json:"patchSet,omitempty"`
+	EventCreatedOn int64      `json:"eventCreatedOn"`
+	Author         *Account   `json:"author,omitempty"`    // comment-added
+	Approvals      []Approval `json:"approvals,omitempty"` // comment-added
+	Comment        string     `json:"comment,omitempty"`   // comment-added
+	Abandoner      *Account   `json:"abandoner,omitempty"` // change-abandoned
+	Reason         string     `json:"reason,omitempty"`    // change-abandoned
+	Submitter      *Account   `json:"submitter,omitempty"` // change-merged, ref-updated
+	NewRev         string     `json:"newRev,omitempty"`    // change-merged
+	Changer        *Account   `json:"changer,omitempty"`   // wip-state-changed, private-state-changed
+	Reviewer       *Account   `json:"reviewer,omitempty"`  // reviewer-added
+	Adder          *Account   `json:"adder,omitempty"`     // reviewer-added
+	RefUpdate      *RefUpdate `json:"refUpdate,omitempty"` // ref-updated
+}
+
+// Project returns the project the event belongs to, or "" if it has none
+func (e Event) Project() string {
+	if e.Change != nil {
+		return e.Change.Project
+	}
+	if e.RefUpdate != nil {
+		return e.RefUpdate.Project
+	}
+	return ""
 }
 
 // Change represents change information in an event
 type Change struct {
-	Project string   `json:"project"`
-	Branch  string   `json:"branch"`
-	Number  int      `json:"number"`
-	Subject string   `json:"subject"`
-	Owner   *Account `json:"owner,omitempty"`
-	URL     string   `json:"url,omitempty"`
+	Project  string   `json:"project"`
+	Branch   string   `json:"branch"`
+	ID       string   `json:"id,omitempty"`
+	Number   int      `json:"number"`
+	Subject  string   `json:"subject"`
+	Owner    *Account `json:"owner,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	Topic    string   `json:"topic,omitempty"`
+	Hashtags []string `json:"hashtags,omitempty"`
+	WIP      bool     `json:"wip,omitempty"`
+	Private  bool     `json:"private,omitempty"`
 }
 
 // PatchSet represents patchset information in an event
@@ -24,6 +84,8 @@ type PatchSet struct {
 	Ref      string   `json:"ref"`
 	Revision string   `json:"revision"`
 	Uploader *Account `json:"uploader,omitempty"`
+	Author   *Account `json:"author,omitempty"`
+	Kind     string   `json:"kind,omitempty"` // REWORK, TRIVIAL_REBASE, NO_CODE_CHANGE, ...
 }
 
 // Account represents a Gerrit user account
@@ -32,3 +94,19 @@ type Account struct {
 	Email    string `json:"email,omitempty"`
 	Username string `json:"username,omitempty"`
 }
+
+// Approval is a label vote carried by a comment-added event
+type Approval struct {
+	Type        string `json:"type"` // Label name, e.g. "Code-Review"
+	Description string `json:"description,omitempty"`
+	Value       string `json:"value"`
+	OldValue    string `json:"oldValue,omitempty"`
+}
+
+// RefUpdate describes a ref-updated event
+type RefUpdate struct {
+	OldRev  string `json:"oldRev"`
+	NewRev  string `json:"newRev"`
+	RefName string `json:"refName"`
+	Project string `json:"project"`
+}
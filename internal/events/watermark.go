@@ -0,0 +1,86 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Watermark persists the EventCreatedOn of the last processed event so that
+// catch-up after a restart knows where the stream left off.
+type Watermark struct {
+	path string
+	mu   sync.Mutex
+	last int64
+}
+
+type watermarkFile struct {
+	EventCreatedOn int64 `json:"event_created_on"`
+}
+
+// LoadWatermark reads the watermark stored at path. A missing file yields a
+// zero watermark.
+func LoadWatermark(path string) (*Watermark, error) {
+	w := &Watermark{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermark: %w", err)
+	}
+
+	var f watermarkFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse watermark %s: %w", path, err)
+	}
+	w.last = f.EventCreatedOn
+
+	return w, nil
+}
+
+// Last returns the last processed event time, zero if none was recorded.
+func (w *Watermark) Last() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(w.last, 0)
+}
+
+// Advance moves the watermark forward to eventCreatedOn (unix seconds) and
+// persists it. Older timestamps are ignored.
+func (w *Watermark) Advance(eventCreatedOn int64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if eventCreatedOn <= w.last {
+		return nil
+	}
+
+	data, err := json.Marshal(watermarkFile{EventCreatedOn: eventCreatedOn})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return fmt.Errorf("failed to create watermark directory: %w", err)
+	}
+
+	tmp := w.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write watermark: %w", err)
+	}
+	if err := os.Rename(tmp, w.path); err != nil {
+		return fmt.Errorf("failed to replace watermark: %w", err)
+	}
+
+	w.last = eventCreatedOn
+	return nil
+}
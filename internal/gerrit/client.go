@@ -8,9 +8,11 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
 	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
 )
 
@@ -20,8 +22,15 @@ type Client struct {
 	username   string
 	password   string
 	httpClient *http.Client
+	messages   *locale.Messages
+	voteLabel  string
+	retry      RetryPolicy
+	limiter    *rateLimiter
 }
 
+// DefaultVoteLabel is the label ReviewResult.Vote is posted on by default
+const DefaultVoteLabel = "Code-Review"
+
 // NewClient creates a new Gerrit REST API client
 func NewClient(baseURL, username, password string) *Client {
 	return &Client{
@@ -31,33 +40,91 @@ func NewClient(baseURL, username, password string) *Client {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		messages:  locale.For(locale.English),
+		voteLabel: DefaultVoteLabel,
+		retry:     DefaultRetryPolicy,
+	}
+}
+
+// SetRetryPolicy replaces DefaultRetryPolicy for the requests of the client
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// SetRateLimit limits the client to perSecond requests per second; 0
+// removes the limit
+func (c *Client) SetRateLimit(perSecond float64) {
+	c.limiter = newRateLimiter(perSecond)
+}
+
+// SetVoteLabel selects the label ReviewResult.Vote is posted on; empty
+// keeps DefaultVoteLabel.
+func (c *Client) SetVoteLabel(label string) {
+	if label = strings.TrimSpace(label); label != "" {
+		c.voteLabel = label
 	}
 }
 
+// SetLanguage selects the language of the texts the client adds to posted
+// reviews; an empty or unsupported language keeps English.
+func (c *Client) SetLanguage(language string) {
+	c.messages = locale.For(language)
+}
+
 // ReviewInput represents the JSON payload for posting a review
 type ReviewInput struct {
-	Message  string                    `json:"message"`
-	Labels   map[string]int            `json:"labels,omitempty"`
-	Comments map[string][]CommentInput `json:"comments,omitempty"`
-	Drafts   string                    `json:"drafts,omitempty"`
+	Message       string                         `json:"message"`
+	Labels        map[string]int                 `json:"labels,omitempty"`
+	Comments      map[string][]CommentInput      `json:"comments,omitempty"`
+	RobotComments map[string][]RobotCommentInput `json:"robot_comments,omitempty"`
+	Drafts        string                         `json:"drafts,omitempty"`
+
+	Reviewers         []ReviewerInput     `json:"reviewers,omitempty"`
+	AddToAttentionSet []AttentionSetInput `json:"add_to_attention_set,omitempty"`
 }
 
-// CommentInput represents a single inline comment
+// CommentInput represents a single inline comment; without Line and Range
+// it comments on the whole file
 type CommentInput struct {
-	Line       int    `json:"line,omitempty"`
-	Message    string `json:"message"`
-	Unresolved bool   `json:"unresolved,omitempty"`
+	Line       int           `json:"line,omitempty"`
+	Range      *CommentRange `json:"range,omitempty"`
+	Side       string        `json:"side,omitempty"`
+	Message    string        `json:"message"`
+	Unresolved bool          `json:"unresolved"`
+}
+
+// RobotCommentInput represents a single robot comment. Gerrit shows its fix
+// suggestions with an "apply fix" button.
+type RobotCommentInput struct {
+	CommentInput
+	RobotID        string              `json:"robot_id"`
+	RobotRunID     string              `json:"robot_run_id"`
+	URL            string              `json:"url,omitempty"`
+	Properties     map[string]string   `json:"properties,omitempty"`
+	FixSuggestions []FixSuggestionInfo `json:"fix_suggestions,omitempty"`
 }
 
 // PostReview posts a code review with vote and comments to Gerrit
 func (c *Client) PostReview(ctx context.Context, changeNum, patchsetNum int, result *types.ReviewResult) error {
-	// Build review input
-	input := c.buildReviewInput(result)
+	return c.postReviewInput(ctx, strconv.Itoa(changeNum), strconv.Itoa(patchsetNum), c.buildReviewInput(result))
+}
 
+// PostRobotComments publishes comments as robot comments of robot without
+// voting or publishing the caller's drafts
+func (c *Client) PostRobotComments(ctx context.Context, changeID, revisionID string, robot types.Robot, comments []types.Comment) error {
+	input := &ReviewInput{
+		RobotComments: c.groupRobotCommentsByFile(robot, comments),
+		Drafts:        "KEEP",
+	}
+	return c.postReviewInput(ctx, changeID, revisionID, input)
+}
+
+// postReviewInput posts input to the review endpoint of a revision
+func (c *Client) postReviewInput(ctx context.Context, changeID, revisionID string, input *ReviewInput) error {
 	// Construct API endpoint
 	// Format: /a/changes/{change-id}/revisions/{revision-id}/review
-	url := fmt.Sprintf("%s/a/changes/%d/revisions/%d/review",
-		c.baseURL, changeNum, patchsetNum)
+	url := fmt.Sprintf("%s/a/changes/%s/revisions/%s/review",
+		c.baseURL, changeID, revisionID)
 
 	// Marshal to JSON
 	jsonData, err := json.Marshal(input)
@@ -76,7 +143,7 @@ func (c *Client) PostReview(ctx context.Context, changeNum, patchsetNum int, res
 	req.SetBasicAuth(c.username, c.password)
 
 	// Execute request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -90,27 +157,49 @@ func (c *Client) PostReview(ctx context.Context, changeNum, patchsetNum int, res
 
 	// Check status code
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp.StatusCode, body)
 	}
 
 	return nil
 }
 
+// ReviewLabels returns the votes PostReview casts for result: its labels
+// and its vote on the client's vote label
+func (c *Client) ReviewLabels(result *types.ReviewResult) map[string]int {
+	labels := make(map[string]int, len(result.Labels)+1)
+	for name, value := range result.Labels {
+		labels[name] = value
+	}
+	labels[c.voteLabel] = result.Vote
+	return labels
+}
+
 // buildReviewInput constructs the ReviewInput from ReviewResult
 func (c *Client) buildReviewInput(result *types.ReviewResult) *ReviewInput {
 	input := &ReviewInput{
 		Message: c.formatReviewMessage(result),
-		Labels: map[string]int{
-			"Code-Review": result.Vote,
-		},
-		Drafts: "PUBLISH", // Publish all draft comments when posting the review
+		Labels:  c.ReviewLabels(result),
+		Drafts:  "PUBLISH", // Publish all draft comments when posting the review
 	}
 
 	// Add inline comments if present
-	if len(result.Comments) > 0 {
+	if len(result.Comments) > 0 && result.Robot != nil {
+		input.RobotComments = c.groupRobotCommentsByFile(*result.Robot, result.Comments)
+	} else if len(result.Comments) > 0 {
 		input.Comments = c.groupCommentsByFile(result.Comments)
 	}
 
+	for _, reviewer := range result.Reviewers {
+		state := ReviewerStateReviewer
+		if reviewer.CC {
+			state = ReviewerStateCC
+		}
+		input.Reviewers = append(input.Reviewers, ReviewerInput{Reviewer: reviewer.Account, State: state})
+	}
+	for _, attention := range result.Attention {
+		input.AddToAttentionSet = append(input.AddToAttentionSet, AttentionSetInput{User: attention.Account, Reason: attention.Reason})
+	}
+
 	return input
 }
 
@@ -121,7 +210,7 @@ func (c *Client) formatReviewMessage(result *types.ReviewResult) string {
 	msg.WriteString("🤖 AI Code Review\n\n")
 	msg.WriteString(result.Summary)
 	msg.WriteString("\n\n---\n")
-	msg.WriteString("_Automated review by Gerrit AI Reviewer_")
+	msg.WriteString(c.messages.ReviewFooter)
 
 	return msg.String()
 }
@@ -131,10 +220,25 @@ func (c *Client) groupCommentsByFile(comments []types.Comment) map[string][]Comm
 	grouped := make(map[string][]CommentInput)
 
 	for _, comment := range comments {
-		commentInput := CommentInput{
-			Line:       comment.Line,
-			Message:    comment.Message,
-			Unresolved: true, // Mark all AI comments as unresolved by default
+		grouped[comment.File] = append(grouped[comment.File], newCommentInput(comment))
+	}
+
+	return grouped
+}
+
+// groupRobotCommentsByFile groups comments by file path as robot comments of
+// robot, turning each comment's Fix into a fix suggestion
+func (c *Client) groupRobotCommentsByFile(robot types.Robot, comments []types.Comment) map[string][]RobotCommentInput {
+	grouped := make(map[string][]RobotCommentInput)
+
+	for _, comment := range comments {
+		commentInput := RobotCommentInput{
+			CommentInput: newCommentInput(comment),
+			RobotID:      robot.ID,
+			RobotRunID:   robot.RunID,
+		}
+		if fix := c.fixSuggestion(comment); fix != nil {
+			commentInput.FixSuggestions = []FixSuggestionInfo{*fix}
 		}
 
 		grouped[comment.File] = append(grouped[comment.File], commentInput)
@@ -143,6 +247,83 @@ func (c *Client) groupCommentsByFile(comments []types.Comment) map[string][]Comm
 	return grouped
 }
 
+// newCommentInput converts a comment for the API
+func newCommentInput(comment types.Comment) CommentInput {
+	commentInput := CommentInput{
+		Line:       comment.Line,
+		Side:       comment.Side,
+		Message:    comment.Message,
+		Unresolved: true, // Mark all AI comments as unresolved by default
+	}
+
+	if comment.Unresolved != nil {
+		commentInput.Unresolved = *comment.Unresolved
+	}
+
+	if comment.Range != nil {
+		commentInput.Range = newCommentRange(comment.Range)
+		commentInput.Line = comment.Range.EndLine
+	}
+
+	return commentInput
+}
+
+// NewDraftInput converts a comment into the input creating it as a draft
+func NewDraftInput(comment types.Comment) *DraftInput {
+	input := &DraftInput{
+		Path:       comment.File,
+		Line:       comment.Line,
+		Side:       comment.Side,
+		Message:    comment.Message,
+		Unresolved: comment.Unresolved,
+	}
+
+	if comment.Range != nil {
+		input.Range = newCommentRange(comment.Range)
+		input.Line = comment.Range.EndLine
+	}
+
+	return input
+}
+
+// newCommentRange converts a range for the API
+func newCommentRange(r *types.Range) *CommentRange {
+	return &CommentRange{
+		StartLine:      r.StartLine,
+		StartCharacter: r.StartCharacter,
+		EndLine:        r.EndLine,
+		EndCharacter:   r.EndCharacter,
+	}
+}
+
+// fixSuggestion returns the fix replacing comment.Range, or the whole
+// comment.Line, with comment.Fix; nil when there is no fix, no position or
+// the comment is on the parent, which cannot be edited
+func (c *Client) fixSuggestion(comment types.Comment) *FixSuggestionInfo {
+	if comment.Fix == "" || comment.Side == types.SideParent {
+		return nil
+	}
+
+	replacement := FixReplacementInfo{Path: comment.File, Replacement: comment.Fix}
+	switch {
+	case comment.Range != nil:
+		replacement.Range = *newCommentRange(comment.Range)
+	case comment.Line > 0:
+		// Replace the line including its newline
+		replacement.Range = CommentRange{StartLine: comment.Line, EndLine: comment.Line + 1}
+		if !strings.HasSuffix(replacement.Replacement, "\n") {
+			replacement.Replacement += "\n"
+		}
+	default:
+		return nil
+	}
+
+	return &FixSuggestionInfo{
+		Description:  c.messages.FixSuggested,
+		Replacements: []FixReplacementInfo{replacement},
+	}
+}
+
 // GetChange retrieves information about a change (for future use)
 func (c *Client) GetChange(ctx context.Context, changeNum int) (map[string]interface{}, error) {
 	url := fmt.Sprintf("%s/a/changes/%d", c.baseURL, changeNum)
@@ -154,7 +335,7 @@ func (c *Client) GetChange(ctx context.Context, changeNum int) (map[string]inter
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -166,7 +347,7 @@ func (c *Client) GetChange(ctx context.Context, changeNum int) (map[string]inter
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Gerrit prepends ")]}'" to JSON responses for security
@@ -191,19 +372,18 @@ func (c *Client) Ping(ctx context.Context) error {
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to connect to gerrit: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == 401 {
-		return fmt.Errorf("authentication failed: invalid credentials")
-	}
-
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("gerrit returned status %d: %s", resp.StatusCode, string(body))
+		if resp.StatusCode == 401 {
+			return fmt.Errorf("authentication failed: invalid credentials: %w", newAPIError(resp.StatusCode, body))
+		}
+		return newAPIError(resp.StatusCode, body)
 	}
 
 	return nil
@@ -212,8 +392,56 @@ func (c *Client) Ping(ctx context.Context) error {
 // ListChanges queries for changes matching the given query string
 // query: Gerrit search query (e.g., "status:open project:myproject")
 // options: Additional options like "CURRENT_REVISION", "DETAILED_ACCOUNTS", etc.
-// limit: Maximum number of results to return (0 for default)
+// limit: Maximum number of results to return (0 for all)
+// Results beyond what Gerrit returns per request are fetched page by page.
 func (c *Client) ListChanges(ctx context.Context, query string, options []string, limit int) ([]ChangeInfo, error) {
+	changes := []ChangeInfo{}
+	err := c.ForEachChange(ctx, query, options, limit, limit, func(change ChangeInfo) error {
+		changes = append(changes, change)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// ForEachChange calls fn for every change matching query as soon as its page
+// arrives. Pages of pageSize changes (0 for Gerrit's default) are requested
+// with an increasing start offset while Gerrit reports more changes, until
+// limit changes were passed to fn (0 for all). An error of fn stops the
+// iteration and is returned.
+func (c *Client) ForEachChange(ctx context.Context, query string, options []string, pageSize, limit int, fn func(ChangeInfo) error) error {
+	start := 0
+	for {
+		n := pageSize
+		if limit > 0 && (n <= 0 || n > limit-start) {
+			n = limit - start
+		}
+
+		changes, more, err := c.ListChangesPage(ctx, query, options, n, start)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			change.MoreChanges = false
+			if err := fn(change); err != nil {
+				return err
+			}
+		}
+
+		start += len(changes)
+		if !more || len(changes) == 0 || (limit > 0 && start >= limit) {
+			return nil
+		}
+	}
+}
+
+// ListChangesPage returns at most limit changes (0 for Gerrit's default)
+// matching query, skipping the first start ones, and whether more changes
+// match
+func (c *Client) ListChangesPage(ctx context.Context, query string, options []string, limit, start int) ([]ChangeInfo, bool, error) {
 	// Build URL with query parameters - URL encode the query
 	url := fmt.Sprintf("%s/a/changes/?q=%s", c.baseURL, url.QueryEscape(query))
 
@@ -222,31 +450,34 @@ func (c *Client) ListChanges(ctx context.Context, query string, options []string
 		url += fmt.Sprintf("&o=%s", opt)
 	}
 
-	// Add limit if specified
+	// Add limit and offset if specified
 	if limit > 0 {
 		url += fmt.Sprintf("&n=%d", limit)
 	}
+	if start > 0 {
+		url += fmt.Sprintf("&S=%d", start)
+	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, false, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, false, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, false, newAPIError(resp.StatusCode, body)
 	}
 
 	// Remove Gerrit's XSSI prefix
@@ -254,10 +485,12 @@ func (c *Client) ListChanges(ctx context.Context, query string, options []string
 
 	var changes []ChangeInfo
 	if err := json.Unmarshal([]byte(bodyStr), &changes); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, false, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	return changes, nil
+	// Gerrit flags the last change of a truncated page
+	more := len(changes) > 0 && changes[len(changes)-1].MoreChanges
+	return changes, more, nil
 }
 
 // GetChangeDetail retrieves detailed information about a specific change
@@ -285,7 +518,7 @@ func (c *Client) GetChangeDetail(ctx context.Context, changeID string, options [
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -297,7 +530,7 @@ func (c *Client) GetChangeDetail(ctx context.Context, changeID string, options [
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Remove Gerrit's XSSI prefix
@@ -330,7 +563,7 @@ func (c *Client) GetRevisionFiles(ctx context.Context, changeID, revisionID, bas
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -342,7 +575,7 @@ func (c *Client) GetRevisionFiles(ctx context.Context, changeID, revisionID, bas
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Remove Gerrit's XSSI prefix
@@ -378,7 +611,7 @@ func (c *Client) GetRevisionDiff(ctx context.Context, changeID, revisionID, file
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -390,7 +623,7 @@ func (c *Client) GetRevisionDiff(ctx context.Context, changeID, revisionID, file
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Remove Gerrit's XSSI prefix
@@ -418,7 +651,7 @@ func (c *Client) ListComments(ctx context.Context, changeID, revisionID string)
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -430,7 +663,7 @@ func (c *Client) ListComments(ctx context.Context, changeID, revisionID string)
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Remove Gerrit's XSSI prefix
@@ -444,6 +677,46 @@ func (c *Client) ListComments(ctx context.Context, changeID, revisionID string)
 	return comments, nil
 }
 
+// ListRobotComments retrieves all robot comments for a specific revision
+// changeID: Change identifier
+// revisionID: Revision identifier (e.g., "current", "1", "2", or commit SHA)
+// Returns a map of file paths to their robot comments
+func (c *Client) ListRobotComments(ctx context.Context, changeID, revisionID string) (map[string][]RobotCommentInfo, error) {
+	apiURL := fmt.Sprintf("%s/a/changes/%s/revisions/%s/robotcomments/", c.baseURL, changeID, revisionID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	// Remove Gerrit's XSSI prefix
+	bodyStr := strings.TrimPrefix(string(body), ")]}'")
+
+	var comments map[string][]RobotCommentInfo
+	if err := json.Unmarshal([]byte(bodyStr), &comments); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return comments, nil
+}
+
 // CreateDraft creates a new draft comment
 // changeID: Change identifier
 // revisionID: Revision identifier (e.g., "current", "1", "2", or commit SHA)
@@ -466,7 +739,7 @@ func (c *Client) CreateDraft(ctx context.Context, changeID, revisionID string, i
 	req.Header.Set("Content-Type", "application/json")
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -478,7 +751,7 @@ func (c *Client) CreateDraft(ctx context.Context, changeID, revisionID string, i
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Remove Gerrit's XSSI prefix
@@ -506,7 +779,7 @@ func (c *Client) ListDrafts(ctx context.Context, changeID, revisionID string) (m
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -518,7 +791,7 @@ func (c *Client) ListDrafts(ctx context.Context, changeID, revisionID string) (m
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Remove Gerrit's XSSI prefix
@@ -546,7 +819,7 @@ func (c *Client) GetDraft(ctx context.Context, changeID, revisionID, draftID str
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -558,7 +831,7 @@ func (c *Client) GetDraft(ctx context.Context, changeID, revisionID, draftID str
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Remove Gerrit's XSSI prefix
@@ -594,7 +867,7 @@ func (c *Client) UpdateDraft(ctx context.Context, changeID, revisionID, draftID
 	req.Header.Set("Content-Type", "application/json")
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -606,7 +879,7 @@ func (c *Client) UpdateDraft(ctx context.Context, changeID, revisionID, draftID
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Remove Gerrit's XSSI prefix
@@ -634,7 +907,7 @@ func (c *Client) DeleteDraft(ctx context.Context, changeID, revisionID, draftID
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -646,7 +919,7 @@ func (c *Client) DeleteDraft(ctx context.Context, changeID, revisionID, draftID
 	}
 
 	if resp.StatusCode != 204 && resp.StatusCode != 200 {
-		return fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp.StatusCode, body)
 	}
 
 	return nil
@@ -665,7 +938,7 @@ func (c *Client) ListAllComments(ctx context.Context, changeID string) (map[stri
 
 	req.SetBasicAuth(c.username, c.password)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -677,7 +950,7 @@ func (c *Client) ListAllComments(ctx context.Context, changeID string) (map[stri
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("gerrit API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp.StatusCode, body)
 	}
 
 	// Remove Gerrit's XSSI prefix
@@ -690,3 +963,56 @@ func (c *Client) ListAllComments(ctx context.Context, changeID string) (map[stri
 
 	return comments, nil
 }
+
+// doJSON sends input as JSON, or no body when input is nil, to path under
+// the REST API and decodes the response into out, unless out is nil
+func (c *Client) doJSON(ctx context.Context, method, path string, input, out interface{}) error {
+	apiURL := c.baseURL + path
+
+	var reqBody io.Reader
+	if input != nil {
+		jsonData, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request input: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp.StatusCode, body)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	// Remove Gerrit's XSSI prefix
+	bodyStr := strings.TrimPrefix(string(body), ")]}'")
+
+	if err := json.Unmarshal([]byte(bodyStr), out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
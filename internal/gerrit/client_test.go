@@ -3,8 +3,10 @@ package gerrit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net"
 	"net/http"
+	"strconv"
 	"testing"
 	"time"
 
@@ -123,6 +125,133 @@ func TestBuildReviewInput(t *testing.T) {
 	}
 }
 
+func TestBuildReviewInputLabels(t *testing.T) {
+	client := NewClient("https://gerrit.example.com", "user", "pass")
+	client.SetVoteLabel("AI-Review")
+
+	input := client.buildReviewInput(&types.ReviewResult{
+		Summary: "Build and review passed",
+		Vote:    -1,
+		Labels:  map[string]int{"Verified": 1},
+	})
+
+	if len(input.Labels) != 2 || input.Labels["AI-Review"] != -1 || input.Labels["Verified"] != 1 {
+		t.Errorf("Expected AI-Review -1 and Verified +1, got %v", input.Labels)
+	}
+	if _, ok := input.Labels["Code-Review"]; ok {
+		t.Errorf("Expected no Code-Review vote, got %v", input.Labels)
+	}
+}
+
+func TestBuildReviewInputReviewers(t *testing.T) {
+	client := NewClient("https://gerrit.example.com", "user", "pass")
+
+	input := client.buildReviewInput(&types.ReviewResult{
+		Summary:   "Needs a security review",
+		Reviewers: []types.Reviewer{{Account: "security-owner@example.com"}, {Account: "jdoe", CC: true}},
+		Attention: []types.Attention{{Account: "security-owner@example.com", Reason: "Token handling changed"}},
+	})
+
+	want := []ReviewerInput{
+		{Reviewer: "security-owner@example.com", State: ReviewerStateReviewer},
+		{Reviewer: "jdoe", State: ReviewerStateCC},
+	}
+	if len(input.Reviewers) != len(want) || input.Reviewers[0] != want[0] || input.Reviewers[1] != want[1] {
+		t.Errorf("Expected reviewers %v, got %v", want, input.Reviewers)
+	}
+	if len(input.AddToAttentionSet) != 1 || input.AddToAttentionSet[0] != (AttentionSetInput{User: "security-owner@example.com", Reason: "Token handling changed"}) {
+		t.Errorf("Expected the owner in the attention set, got %v", input.AddToAttentionSet)
+	}
+
+	if input := client.buildReviewInput(&types.ReviewResult{Summary: "LGTM"}); input.Reviewers != nil || input.AddToAttentionSet != nil {
+		t.Errorf("Expected no reviewers or attention, got %v and %v", input.Reviewers, input.AddToAttentionSet)
+	}
+}
+
+func TestBuildReviewInputRobotComments(t *testing.T) {
+	client := NewClient("https://gerrit.example.com", "user", "pass")
+
+	input := client.buildReviewInput(&types.ReviewResult{
+		Summary: "Found issues",
+		Vote:    -1,
+		Robot:   &types.Robot{ID: "gerrit-ai-review", RunID: "run-1"},
+		Comments: []types.Comment{
+			{File: "main.go", Line: 42, Message: "[P1] Missing error check", Fix: "if err != nil {\n\treturn err\n}"},
+			{File: "util.go", Range: &types.Range{StartLine: 3, StartCharacter: 4, EndLine: 3, EndCharacter: 9}, Message: "[P2] Rename", Fix: "count"},
+			{File: "doc.go", Line: 1, Message: "[P3] Nice"},
+		},
+	})
+
+	if len(input.Comments) != 0 {
+		t.Errorf("Expected no human comments, got %v", input.Comments)
+	}
+	if len(input.RobotComments) != 3 {
+		t.Fatalf("Expected robot comments on 3 files, got %v", input.RobotComments)
+	}
+
+	lineFix := input.RobotComments["main.go"][0]
+	if lineFix.RobotID != "gerrit-ai-review" || lineFix.RobotRunID != "run-1" || lineFix.Line != 42 {
+		t.Errorf("Unexpected robot comment: %+v", lineFix)
+	}
+	if len(lineFix.FixSuggestions) != 1 || len(lineFix.FixSuggestions[0].Replacements) != 1 {
+		t.Fatalf("Expected one fix suggestion, got %+v", lineFix.FixSuggestions)
+	}
+	replacement := lineFix.FixSuggestions[0].Replacements[0]
+	if replacement.Path != "main.go" || replacement.Range != (CommentRange{StartLine: 42, EndLine: 43}) {
+		t.Errorf("Expected the whole line 42 to be replaced, got %+v", replacement)
+	}
+	if replacement.Replacement != "if err != nil {\n\treturn err\n}\n" {
+		t.Errorf("Expected the replacement to end with a newline, got %q", replacement.Replacement)
+	}
+
+	rangeFix := input.RobotComments["util.go"][0].FixSuggestions[0].Replacements[0]
+	if rangeFix.Range != (CommentRange{StartLine: 3, StartCharacter: 4, EndLine: 3, EndCharacter: 9}) || rangeFix.Replacement != "count" {
+		t.Errorf("Expected the range to be replaced as is, got %+v", rangeFix)
+	}
+
+	if fixes := input.RobotComments["doc.go"][0].FixSuggestions; fixes != nil {
+		t.Errorf("Expected no fix suggestion without a fix, got %+v", fixes)
+	}
+}
+
+func TestChangeInfoCheckLabels(t *testing.T) {
+	change := &ChangeInfo{
+		Number: 12345,
+		Labels: map[string]*LabelInfo{"Code-Review": {}, "Verified": {}},
+		PermittedLabels: map[string][]string{
+			"Code-Review": {"-1", " 0", "+1"},
+			"Verified":    {" 0"},
+		},
+	}
+
+	if err := change.CheckLabels(map[string]int{"Code-Review": 1, "Verified": 0}); err != nil {
+		t.Errorf("Expected permitted votes to pass, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]int
+		want   string
+	}{
+		{"unknown label", map[string]int{"AI-Review": 1}, "does not exist"},
+		{"value not permitted", map[string]int{"Verified": 1}, "Verified +1 is not permitted"},
+		{"out of range", map[string]int{"Code-Review": 2}, "Code-Review +2 is not permitted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := change.CheckLabels(tt.labels)
+			if !errors.Is(err, ErrLabelNotPermitted) || !contains(err.Error(), tt.want) {
+				t.Errorf("Expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	change.PermittedLabels = nil
+	if err := change.CheckLabels(map[string]int{"Verified": 1}); err != nil {
+		t.Errorf("Expected only existence to be checked without permitted labels, got %v", err)
+	}
+}
+
 func TestFormatReviewMessage(t *testing.T) {
 	client := NewClient("https://gerrit.example.com", "user", "pass")
 
@@ -149,6 +278,21 @@ func TestFormatReviewMessage(t *testing.T) {
 	}
 }
 
+func TestFormatReviewMessageLanguage(t *testing.T) {
+	client := NewClient("https://gerrit.example.com", "user", "pass")
+	client.SetLanguage("zh-TW")
+
+	msg := client.formatReviewMessage(&types.ReviewResult{Summary: "LGTM"})
+	if !contains(msg, "由 Gerrit AI Reviewer 自動審查") {
+		t.Errorf("Expected Traditional Chinese signature, got %q", msg)
+	}
+
+	client.SetLanguage("unknown")
+	if msg := client.formatReviewMessage(&types.ReviewResult{Summary: "LGTM"}); !contains(msg, "Automated review by Gerrit AI Reviewer") {
+		t.Errorf("Expected English signature for an unsupported language, got %q", msg)
+	}
+}
+
 func TestGroupCommentsByFile(t *testing.T) {
 	client := NewClient("https://gerrit.example.com", "user", "pass")
 
@@ -173,6 +317,56 @@ func TestGroupCommentsByFile(t *testing.T) {
 	}
 }
 
+func TestGroupCommentsByFilePositions(t *testing.T) {
+	client := NewClient("https://gerrit.example.com", "user", "pass")
+
+	grouped := client.groupCommentsByFile([]types.Comment{
+		{File: "a.go", Range: &types.Range{StartLine: 3, StartCharacter: 4, EndLine: 5, EndCharacter: 1}, Message: "range"},
+		{File: "a.go", Line: 7, Side: types.SideParent, Message: "deleted line"},
+		{File: "a.go", Message: "whole file"},
+	})
+
+	got := grouped["a.go"]
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 comments for a.go, got %d", len(got))
+	}
+	if got[0].Range == nil || *got[0].Range != (CommentRange{StartLine: 3, StartCharacter: 4, EndLine: 5, EndCharacter: 1}) || got[0].Line != 5 {
+		t.Errorf("Expected the range ending on line 5, got %+v", got[0])
+	}
+	if got[1].Side != "PARENT" || got[1].Line != 7 {
+		t.Errorf("Expected a comment on line 7 of the parent, got %+v", got[1])
+	}
+
+	data, err := json.Marshal(got[2])
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	if string(data) != `{"message":"whole file","unresolved":true}` {
+		t.Errorf("Expected a file comment without position or side, got %s", data)
+	}
+}
+
+func TestNewDraftInput(t *testing.T) {
+	resolved := false
+	input := NewDraftInput(types.Comment{
+		File:       "a.go",
+		Range:      &types.Range{StartLine: 2, StartCharacter: 0, EndLine: 4, EndCharacter: 3},
+		Side:       types.SideParent,
+		Message:    "[P2] msg",
+		Unresolved: &resolved,
+	})
+
+	if input.Path != "a.go" || input.Line != 4 || input.Side != "PARENT" || input.Message != "[P2] msg" {
+		t.Errorf("Unexpected draft input: %+v", input)
+	}
+	if input.Range == nil || input.Range.StartLine != 2 || input.Range.EndCharacter != 3 {
+		t.Errorf("Expected the range to be carried over, got %+v", input.Range)
+	}
+	if input.Unresolved == nil || *input.Unresolved {
+		t.Errorf("Expected the draft to be resolved")
+	}
+}
+
 func TestPostReview(t *testing.T) {
 	// Create a test server
 	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -259,6 +453,39 @@ func TestPostReview_ErrorResponse(t *testing.T) {
 	}
 }
 
+func TestPostRobotComments(t *testing.T) {
+	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/a/changes/12345/revisions/current/review" {
+			t.Errorf("Expected path /a/changes/12345/revisions/current/review, got %s", r.URL.Path)
+		}
+
+		var input ReviewInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if len(input.Labels) != 0 {
+			t.Errorf("Expected no votes, got %v", input.Labels)
+		}
+		if input.Drafts != "KEEP" {
+			t.Errorf("Expected drafts to be kept, got %q", input.Drafts)
+		}
+		if got := input.RobotComments["main.go"]; len(got) != 1 || got[0].RobotID != "lint" || got[0].RobotRunID != "7" {
+			t.Errorf("Unexpected robot comments: %+v", input.RobotComments)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-user", "test-pass")
+	err := client.PostRobotComments(context.Background(), "12345", "current", types.Robot{ID: "lint", RunID: "7"},
+		[]types.Comment{{File: "main.go", Line: 3, Message: "Unused variable"}})
+	if err != nil {
+		t.Errorf("PostRobotComments() failed: %v", err)
+	}
+}
+
 func TestPing(t *testing.T) {
 	// Create a test server that simulates /a/accounts/self endpoint
 	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -365,6 +592,78 @@ func TestListChanges(t *testing.T) {
 	}
 }
 
+func TestForEachChange(t *testing.T) {
+	// 5 matching changes, of which Gerrit returns at most 2 per request
+	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("S"))
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		if n <= 0 || n > 2 {
+			n = 2
+		}
+
+		var page []ChangeInfo
+		for i := start; i < 5 && len(page) < n; i++ {
+			page = append(page, ChangeInfo{Number: i + 1})
+		}
+		if len(page) > 0 && start+len(page) < 5 {
+			page[len(page)-1].MoreChanges = true
+		}
+
+		w.Write([]byte(")]}'\n"))
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-user", "test-pass")
+
+	tests := []struct {
+		name     string
+		pageSize int
+		limit    int
+		want     int
+	}{
+		{"all", 100, 0, 5},
+		{"limit across pages", 100, 3, 3},
+		{"limit within a page", 1, 3, 3},
+		{"default page size", 0, 0, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var numbers []int
+			err := client.ForEachChange(context.Background(), "status:open", nil, tt.pageSize, tt.limit, func(change ChangeInfo) error {
+				if change.MoreChanges {
+					t.Errorf("Expected _more_changes to be cleared on change %d", change.Number)
+				}
+				numbers = append(numbers, change.Number)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ForEachChange() failed: %v", err)
+			}
+			if len(numbers) != tt.want {
+				t.Fatalf("Expected %d changes, got %v", tt.want, numbers)
+			}
+			for i, number := range numbers {
+				if number != i+1 {
+					t.Errorf("Expected changes in order without duplicates, got %v", numbers)
+					break
+				}
+			}
+		})
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := client.ForEachChange(context.Background(), "status:open", nil, 2, 0, func(ChangeInfo) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Expected the callback error to stop the iteration, got %v after %d calls", err, calls)
+	}
+}
+
 func TestGetChangeDetail(t *testing.T) {
 	// Create a test server
 	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -677,3 +976,31 @@ func TestListComments(t *testing.T) {
 		t.Errorf("Expected message 'Test comment', got '%s'", mainComments[0].Message)
 	}
 }
+
+func TestListRobotComments(t *testing.T) {
+	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/a/changes/12345/revisions/2/robotcomments/" {
+			t.Errorf("Expected path /a/changes/12345/revisions/2/robotcomments/, got %s", r.URL.Path)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`)]}'
+{"main.go": [{"id": "rc1", "line": 42, "message": "[P1] Missing error check", "robot_id": "gerrit-ai-review", "robot_run_id": "run-1",
+  "fix_suggestions": [{"fix_id": "f1", "description": "Suggested fix", "replacements": [{"path": "main.go", "range": {"start_line": 42, "start_character": 0, "end_line": 43, "end_character": 0}, "replacement": "x\n"}]}]}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-user", "test-pass")
+	comments, err := client.ListRobotComments(context.Background(), "12345", "2")
+	if err != nil {
+		t.Fatalf("ListRobotComments() failed: %v", err)
+	}
+
+	got := comments["main.go"]
+	if len(got) != 1 || got[0].RobotID != "gerrit-ai-review" || got[0].Line != 42 {
+		t.Fatalf("Unexpected robot comments: %+v", comments)
+	}
+	if len(got[0].FixSuggestions) != 1 || got[0].FixSuggestions[0].FixID != "f1" {
+		t.Errorf("Expected the fix suggestion to be decoded, got %+v", got[0].FixSuggestions)
+	}
+}
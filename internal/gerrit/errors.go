@@ -0,0 +1,50 @@
+package gerrit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors matched by an *APIError with errors.Is, by response status
+var (
+	ErrUnauthorized = errors.New("not authenticated")      // 401
+	ErrForbidden    = errors.New("not permitted")          // 403
+	ErrNotFound     = errors.New("not found")              // 404
+	ErrConflict     = errors.New("conflict")               // 409
+	ErrRateLimited  = errors.New("rate limited by gerrit") // 429
+)
+
+// APIError is returned when the Gerrit REST API answers with an unexpected
+// status. It matches ErrUnauthorized, ErrForbidden, ErrNotFound, ErrConflict
+// or ErrRateLimited depending on the status.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func newAPIError(statusCode int, body []byte) *APIError {
+	return &APIError{StatusCode: statusCode, Body: string(body)}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gerrit API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// Unwrap returns the error matching the status, if any
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+package gerrit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Abandon abandons a change, posting message as the reason if not empty.
+// Returns the abandoned change.
+func (c *Client) Abandon(ctx context.Context, changeID, message string) (*ChangeInfo, error) {
+	var change ChangeInfo
+	if err := c.changeAction(ctx, http.MethodPost, changeID, "abandon", &MessageInput{Message: message}, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// Restore restores an abandoned change, posting message if not empty.
+// Returns the restored change.
+func (c *Client) Restore(ctx context.Context, changeID, message string) (*ChangeInfo, error) {
+	var change ChangeInfo
+	if err := c.changeAction(ctx, http.MethodPost, changeID, "restore", &MessageInput{Message: message}, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// Rebase rebases the current patchset of a change onto base: a change
+// number, a change number with patchset ("12345~2") or a commit SHA. An
+// empty base rebases onto the tip of the target branch or the current
+// patchset of the parent change. Returns the rebased change.
+func (c *Client) Rebase(ctx context.Context, changeID, base string) (*ChangeInfo, error) {
+	var change ChangeInfo
+	if err := c.changeAction(ctx, http.MethodPost, changeID, "rebase", &RebaseInput{Base: base}, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// Submit submits a change. Returns the change, MERGED unless Gerrit merges
+// it asynchronously.
+func (c *Client) Submit(ctx context.Context, changeID string) (*ChangeInfo, error) {
+	var change ChangeInfo
+	if err := c.changeAction(ctx, http.MethodPost, changeID, "submit", struct{}{}, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// Revert creates a change reverting a merged change, with message as its
+// commit message if not empty. Returns the new change.
+func (c *Client) Revert(ctx context.Context, changeID, message string) (*ChangeInfo, error) {
+	var change ChangeInfo
+	if err := c.changeAction(ctx, http.MethodPost, changeID, "revert", &MessageInput{Message: message}, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// SetWorkInProgress marks a change as work in progress, posting message if
+// not empty
+func (c *Client) SetWorkInProgress(ctx context.Context, changeID, message string) error {
+	return c.changeAction(ctx, http.MethodPost, changeID, "wip", &MessageInput{Message: message}, nil)
+}
+
+// SetReadyForReview marks a work-in-progress change as ready for review,
+// posting message if not empty
+func (c *Client) SetReadyForReview(ctx context.Context, changeID, message string) error {
+	return c.changeAction(ctx, http.MethodPost, changeID, "ready", &MessageInput{Message: message}, nil)
+}
+
+// SetPrivate marks a change as private, or public when private is false,
+// posting message if not empty
+func (c *Client) SetPrivate(ctx context.Context, changeID string, private bool, message string) error {
+	method := http.MethodPost
+	if !private {
+		method = http.MethodDelete
+	}
+	return c.changeAction(ctx, method, changeID, "private", &MessageInput{Message: message}, nil)
+}
+
+// changeAction sends input to an endpoint of a change
+// (/a/changes/{change-id}/{endpoint}), see doJSON
+func (c *Client) changeAction(ctx context.Context, method, changeID, endpoint string, input, out interface{}) error {
+	return c.doJSON(ctx, method, fmt.Sprintf("/a/changes/%s/%s", changeID, endpoint), input, out)
+}
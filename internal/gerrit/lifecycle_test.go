@@ -0,0 +1,155 @@
+package gerrit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestChangeActions(t *testing.T) {
+	type request struct {
+		method string
+		path   string
+		body   map[string]string
+	}
+
+	var got request
+	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "test-user" || password != "test-pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		got = request{method: r.Method, path: r.URL.Path}
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &got.body); err != nil {
+			t.Errorf("%s %s: body is not a JSON object: %q", r.Method, r.URL.Path, data)
+		}
+
+		switch r.URL.Path {
+		case "/a/changes/12345/wip", "/a/changes/12345/ready", "/a/changes/12345/private":
+			w.WriteHeader(http.StatusOK)
+		case "/a/changes/12345/revert":
+			w.Write([]byte(")]}'\n{\"_number\": 12346, \"status\": \"NEW\"}"))
+		default:
+			w.Write([]byte(")]}'\n{\"_number\": 12345, \"status\": \"ABANDONED\"}"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-user", "test-pass")
+	ctx := context.Background()
+
+	changeResult := func(change *ChangeInfo, err error) (int, error) {
+		if err != nil {
+			return 0, err
+		}
+		return change.Number, nil
+	}
+	noResult := func(err error) (int, error) {
+		return 0, err
+	}
+
+	tests := []struct {
+		name       string
+		call       func() (int, error)
+		want       request
+		wantNumber int
+	}{
+		{
+			name:       "abandon",
+			call:       func() (int, error) { return changeResult(client.Abandon(ctx, "12345", "Obsolete")) },
+			want:       request{http.MethodPost, "/a/changes/12345/abandon", map[string]string{"message": "Obsolete"}},
+			wantNumber: 12345,
+		},
+		{
+			name:       "restore without message",
+			call:       func() (int, error) { return changeResult(client.Restore(ctx, "12345", "")) },
+			want:       request{http.MethodPost, "/a/changes/12345/restore", map[string]string{}},
+			wantNumber: 12345,
+		},
+		{
+			name:       "rebase onto base",
+			call:       func() (int, error) { return changeResult(client.Rebase(ctx, "12345", "12300~2")) },
+			want:       request{http.MethodPost, "/a/changes/12345/rebase", map[string]string{"base": "12300~2"}},
+			wantNumber: 12345,
+		},
+		{
+			name:       "submit",
+			call:       func() (int, error) { return changeResult(client.Submit(ctx, "12345")) },
+			want:       request{http.MethodPost, "/a/changes/12345/submit", map[string]string{}},
+			wantNumber: 12345,
+		},
+		{
+			name:       "revert",
+			call:       func() (int, error) { return changeResult(client.Revert(ctx, "12345", "Revert: breaks the build")) },
+			want:       request{http.MethodPost, "/a/changes/12345/revert", map[string]string{"message": "Revert: breaks the build"}},
+			wantNumber: 12346,
+		},
+		{
+			name: "work in progress",
+			call: func() (int, error) { return noResult(client.SetWorkInProgress(ctx, "12345", "Needs tests")) },
+			want: request{http.MethodPost, "/a/changes/12345/wip", map[string]string{"message": "Needs tests"}},
+		},
+		{
+			name: "ready for review",
+			call: func() (int, error) { return noResult(client.SetReadyForReview(ctx, "12345", "")) },
+			want: request{http.MethodPost, "/a/changes/12345/ready", map[string]string{}},
+		},
+		{
+			name: "private",
+			call: func() (int, error) { return noResult(client.SetPrivate(ctx, "12345", true, "")) },
+			want: request{http.MethodPost, "/a/changes/12345/private", map[string]string{}},
+		},
+		{
+			name: "public",
+			call: func() (int, error) { return noResult(client.SetPrivate(ctx, "12345", false, "Ready to share")) },
+			want: request{http.MethodDelete, "/a/changes/12345/private", map[string]string{"message": "Ready to share"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = request{}
+			number, err := tt.call()
+			if err != nil {
+				t.Fatalf("call failed: %v", err)
+			}
+			if got.method != tt.want.method || got.path != tt.want.path {
+				t.Errorf("Expected %s %s, got %s %s", tt.want.method, tt.want.path, got.method, got.path)
+			}
+			if len(got.body) != len(tt.want.body) {
+				t.Errorf("Expected body %v, got %v", tt.want.body, got.body)
+			}
+			for key, value := range tt.want.body {
+				if got.body[key] != value {
+					t.Errorf("Expected %s %q, got %q", key, value, got.body[key])
+				}
+			}
+			if number != tt.wantNumber {
+				t.Errorf("Expected change %d, got %d", tt.wantNumber, number)
+			}
+		})
+	}
+}
+
+func TestChangeActionConflict(t *testing.T) {
+	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte("change is merged"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-user", "test-pass")
+
+	_, err := client.Abandon(context.Background(), "12345", "")
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("Expected ErrConflict, got %v", err)
+	}
+	if !contains(err.Error(), "change is merged") {
+		t.Errorf("Expected Gerrit's reason in the error, got %v", err)
+	}
+}
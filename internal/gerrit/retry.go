@@ -0,0 +1,177 @@
+package gerrit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/backoff"
+)
+
+// RetryPolicy controls how often a failed request is retried. Requests
+// answered with 429 or 503 were not processed by Gerrit and are retried
+// whatever their method; network errors, 502 and 504 are only retried for
+// idempotent methods.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first; <= 1 disables retries
+	BaseDelay   time.Duration // Delay before the first retry, doubled per attempt
+	MaxDelay    time.Duration // Upper bound of a delay; a longer Retry-After is not waited for
+}
+
+// DefaultRetryPolicy is the retry policy of a new Client
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+// delay returns how long to wait before retrying after the given number of
+// failed attempts, honoring a Retry-After header of resp; false when the
+// server asks to wait longer than MaxDelay.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if wait, ok := retryAfter(resp); ok {
+		if p.MaxDelay > 0 && wait > p.MaxDelay {
+			return 0, false
+		}
+		return wait, true
+	}
+	return backoff.Exponential(attempt, p.BaseDelay, p.MaxDelay), true
+}
+
+// retryAfter parses the Retry-After header of resp, in seconds or as an
+// HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// shouldRetry reports whether a request that got resp or err may be sent again
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false // the body cannot be sent again
+	}
+
+	if err != nil {
+		return req.Context().Err() == nil && idempotent(req.Method)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+// idempotent reports whether sending a request with method twice has the
+// same effect as sending it once
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// do sends req like http.Client.Do, waiting for the rate limiter before every
+// attempt and retrying as described by the client's RetryPolicy
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if attempt >= c.retry.MaxAttempts || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+		delay, ok := c.retry.delay(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rateLimiter spaces requests evenly so that at most a given number are
+// sent per second. A nil rateLimiter does not limit.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newRateLimiter returns a limiter for perSecond requests per second; nil
+// (unlimited) when perSecond <= 0
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the next request may be sent or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	return sleep(ctx, slot.Sub(now))
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,201 @@
+package gerrit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
+)
+
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+func TestRetryRateLimited(t *testing.T) {
+	var calls atomic.Int32
+	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(")]}'\n{}"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-user", "test-pass")
+	client.SetRetryPolicy(fastRetry)
+
+	if _, err := client.ListComments(context.Background(), "12345", "current"); err != nil {
+		t.Fatalf("ListComments() failed: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-user", "test-pass")
+	client.SetRetryPolicy(fastRetry)
+
+	_, err := client.ListComments(context.Background(), "12345", "current")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetryAfterBeyondMaxDelay(t *testing.T) {
+	var calls atomic.Int32
+	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-user", "test-pass")
+	client.SetRetryPolicy(fastRetry)
+
+	_, err := client.ListComments(context.Background(), "12345", "current")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected no retry when Retry-After exceeds MaxDelay, got %d attempts", got)
+	}
+}
+
+func TestRetryPostReview(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		calls  int32
+	}{
+		{"unavailable is retried", http.StatusServiceUnavailable, 2},
+		{"bad gateway is not retried", http.StatusBadGateway, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			var bodies []string
+			server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if calls.Add(1) == 1 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte("{}"))
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "test-user", "test-pass")
+			client.SetRetryPolicy(fastRetry)
+
+			err := client.PostReview(context.Background(), 12345, 3, &types.ReviewResult{Summary: "Test", Vote: 1})
+			if got := calls.Load(); got != tt.calls {
+				t.Fatalf("Expected %d attempts, got %d (err %v)", tt.calls, got, err)
+			}
+			if tt.calls == 1 && err == nil {
+				t.Errorf("Expected an error")
+			}
+			if tt.calls == 2 && (err != nil || bodies[0] != bodies[1] || bodies[1] == "") {
+				t.Errorf("Expected the same body to be posted again, got %q (err %v)", bodies, err)
+			}
+		})
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		status int
+		target error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusTooManyRequests, ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		err := error(newAPIError(tt.status, []byte("body")))
+		if !errors.Is(err, tt.target) {
+			t.Errorf("status %d: expected %v", tt.status, tt.target)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+			t.Errorf("status %d: expected an *APIError", tt.status)
+		}
+	}
+
+	if err := newAPIError(http.StatusInternalServerError, nil); errors.Unwrap(err) != nil {
+		t.Errorf("Expected no sentinel for status 500, got %v", errors.Unwrap(err))
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	if _, ok := retryAfter(resp); ok {
+		t.Errorf("Expected no Retry-After")
+	}
+
+	resp.Header.Set("Retry-After", "7")
+	if got, ok := retryAfter(resp); !ok || got != 7*time.Second {
+		t.Errorf("Expected 7s, got %v", got)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if got, ok := retryAfter(resp); !ok || got < 59*time.Minute || got > time.Hour {
+		t.Errorf("Expected about an hour, got %v", got)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 4 * time.Second} {
+		got, ok := policy.delay(attempt, nil)
+		if !ok || got < want/2 || got > want {
+			t.Errorf("delay(%d) = %v, %v, want between %v and %v", attempt, got, ok, want/2, want)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	if newRateLimiter(0) != nil {
+		t.Fatalf("Expected no limiter for rate 0")
+	}
+
+	limiter := newRateLimiter(50) // one request every 20ms
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.wait(context.Background()); err != nil {
+			t.Fatalf("wait() failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected 3 requests to take at least 40ms, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter.wait(ctx) // reserves the next slot
+	if err := limiter.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package gerrit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrReviewerNotAdded is returned when Gerrit answers a request to add a
+// reviewer with the reason it could not
+var ErrReviewerNotAdded = errors.New("reviewer not added")
+
+// ListReviewers retrieves the reviewers and CCs of a change with their votes
+func (c *Client) ListReviewers(ctx context.Context, changeID string) ([]ReviewerInfo, error) {
+	var reviewers []ReviewerInfo
+	if err := c.changeAction(ctx, http.MethodGet, changeID, "reviewers/", nil, &reviewers); err != nil {
+		return nil, err
+	}
+	return reviewers, nil
+}
+
+// AddReviewer adds an account or group as reviewer, or CC with
+// ReviewerStateCC. A reviewer Gerrit refuses to add gives an error wrapping
+// ErrReviewerNotAdded together with the result.
+func (c *Client) AddReviewer(ctx context.Context, changeID string, input *ReviewerInput) (*ReviewerResult, error) {
+	var result ReviewerResult
+	if err := c.changeAction(ctx, http.MethodPost, changeID, "reviewers", input, &result); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case result.Error != "":
+		return &result, fmt.Errorf("%w: %s", ErrReviewerNotAdded, result.Error)
+	case result.Confirm:
+		return &result, fmt.Errorf("%w: adding group %s needs confirmation", ErrReviewerNotAdded, input.Reviewer)
+	}
+	return &result, nil
+}
+
+// RemoveReviewer removes a reviewer or CC, identified by account ID, email
+// or username, from a change
+func (c *Client) RemoveReviewer(ctx context.Context, changeID, account string) error {
+	return c.changeAction(ctx, http.MethodDelete, changeID, "reviewers/"+url.PathEscape(account), nil, nil)
+}
+
+// SuggestReviewers returns at most limit accounts and groups matching query
+// that could review a change (0 for Gerrit's default limit)
+func (c *Client) SuggestReviewers(ctx context.Context, changeID, query string, limit int) ([]SuggestedReviewerInfo, error) {
+	endpoint := "suggest_reviewers?q=" + url.QueryEscape(query)
+	if limit > 0 {
+		endpoint += fmt.Sprintf("&n=%d", limit)
+	}
+
+	var suggestions []SuggestedReviewerInfo
+	if err := c.changeAction(ctx, http.MethodGet, changeID, endpoint, nil, &suggestions); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// AddToAttentionSet asks user to act on a change, giving reason. Returns
+// the account added.
+func (c *Client) AddToAttentionSet(ctx context.Context, changeID, user, reason string) (*AccountInfo, error) {
+	var account AccountInfo
+	input := &AttentionSetInput{User: user, Reason: reason}
+	if err := c.changeAction(ctx, http.MethodPost, changeID, "attention", input, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// RemoveFromAttentionSet removes user from the attention set of a change,
+// giving reason
+func (c *Client) RemoveFromAttentionSet(ctx context.Context, changeID, user, reason string) error {
+	input := &AttentionSetInput{Reason: reason}
+	return c.changeAction(ctx, http.MethodDelete, changeID, "attention/"+url.PathEscape(user), input, nil)
+}
+
+// ResolveAccount looks up an account by account ID, email or username.
+// An unknown or ambiguous account gives an error matching ErrNotFound.
+func (c *Client) ResolveAccount(ctx context.Context, account string) (*AccountInfo, error) {
+	var info AccountInfo
+	if err := c.doJSON(ctx, http.MethodGet, "/a/accounts/"+url.PathEscape(account), nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -0,0 +1,116 @@
+package gerrit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestReviewers(t *testing.T) {
+	var method, path, query string
+	var body map[string]string
+	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, query = r.Method, r.URL.EscapedPath(), r.URL.RawQuery
+		body = nil
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			json.Unmarshal(data, &body)
+		}
+
+		w.Write([]byte(")]}'\n"))
+		switch {
+		case r.Method == http.MethodGet && path == "/a/changes/12345/reviewers/":
+			w.Write([]byte(`[{"_account_id": 1000, "email": "jdoe@example.com", "approvals": {"Code-Review": "+1"}}]`))
+		case r.Method == http.MethodPost && path == "/a/changes/12345/reviewers":
+			if body["reviewer"] == "nobody" {
+				w.Write([]byte(`{"input": "nobody", "error": "nobody does not identify a registered user or group"}`))
+				return
+			}
+			w.Write([]byte(`{"input": "jdoe", "ccs": [{"_account_id": 1000, "username": "jdoe"}]}`))
+		case r.Method == http.MethodGet && path == "/a/changes/12345/suggest_reviewers":
+			w.Write([]byte(`[{"account": {"_account_id": 1000, "name": "John Doe"}}, {"group": {"id": "abc", "name": "security"}, "count": 4}]`))
+		case r.Method == http.MethodPost && path == "/a/changes/12345/attention":
+			w.Write([]byte(`{"_account_id": 1000}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-user", "test-pass")
+	ctx := context.Background()
+
+	reviewers, err := client.ListReviewers(ctx, "12345")
+	if err != nil || len(reviewers) != 1 || reviewers[0].Email != "jdoe@example.com" || reviewers[0].Approvals["Code-Review"] != "+1" {
+		t.Errorf("ListReviewers() = %+v, %v", reviewers, err)
+	}
+
+	result, err := client.AddReviewer(ctx, "12345", &ReviewerInput{Reviewer: "jdoe", State: ReviewerStateCC})
+	if err != nil || len(result.CCs) != 1 || result.CCs[0].Username != "jdoe" {
+		t.Errorf("AddReviewer() = %+v, %v", result, err)
+	}
+	if body["reviewer"] != "jdoe" || body["state"] != "CC" {
+		t.Errorf("Expected jdoe as CC, got %v", body)
+	}
+
+	result, err = client.AddReviewer(ctx, "12345", &ReviewerInput{Reviewer: "nobody"})
+	if !errors.Is(err, ErrReviewerNotAdded) || result == nil || !contains(err.Error(), "registered user") {
+		t.Errorf("Expected ErrReviewerNotAdded with Gerrit's reason, got %+v, %v", result, err)
+	}
+
+	if err := client.RemoveReviewer(ctx, "12345", "jdoe@example.com"); err != nil {
+		t.Errorf("RemoveReviewer() failed: %v", err)
+	}
+	if method != http.MethodDelete || path != "/a/changes/12345/reviewers/jdoe@example.com" {
+		t.Errorf("Expected DELETE of the reviewer, got %s %s", method, path)
+	}
+
+	suggestions, err := client.SuggestReviewers(ctx, "12345", "sec urity", 5)
+	if err != nil || len(suggestions) != 2 || suggestions[0].Account.Name != "John Doe" || suggestions[1].Group.Name != "security" {
+		t.Errorf("SuggestReviewers() = %+v, %v", suggestions, err)
+	}
+	if query != "q=sec+urity&n=5" {
+		t.Errorf("Expected the query and limit, got %q", query)
+	}
+
+	account, err := client.AddToAttentionSet(ctx, "12345", "jdoe", "Please check the migration")
+	if err != nil || account.AccountID != 1000 {
+		t.Errorf("AddToAttentionSet() = %+v, %v", account, err)
+	}
+	if body["user"] != "jdoe" || body["reason"] != "Please check the migration" {
+		t.Errorf("Expected user and reason, got %v", body)
+	}
+
+	if err := client.RemoveFromAttentionSet(ctx, "12345", "jdoe", "Checked"); err != nil {
+		t.Errorf("RemoveFromAttentionSet() failed: %v", err)
+	}
+	if method != http.MethodDelete || path != "/a/changes/12345/attention/jdoe" || body["reason"] != "Checked" || body["user"] != "" {
+		t.Errorf("Expected DELETE with the reason, got %s %s %v", method, path, body)
+	}
+}
+
+func TestResolveAccount(t *testing.T) {
+	server := newLocalHTTPTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a/accounts/jdoe@example.com", "/a/accounts/jdoe":
+			w.Write([]byte(")]}'\n{\"_account_id\": 1000, \"name\": \"John Doe\", \"email\": \"jdoe@example.com\", \"username\": \"jdoe\"}"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("Account 'nobody' not found"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-user", "test-pass")
+
+	for _, id := range []string{"jdoe@example.com", "jdoe"} {
+		account, err := client.ResolveAccount(context.Background(), id)
+		if err != nil || account.AccountID != 1000 || account.Username != "jdoe" {
+			t.Errorf("ResolveAccount(%q) = %+v, %v", id, account, err)
+		}
+	}
+
+	if _, err := client.ResolveAccount(context.Background(), "nobody"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -2,10 +2,19 @@ package gerrit
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// ErrLabelNotPermitted is returned for a vote on a label that does not
+// exist on the change or with a value the account may not cast
+var ErrLabelNotPermitted = errors.New("label not permitted")
+
 // GerritTime is a custom time type that handles Gerrit's various time formats
 type GerritTime struct {
 	time.Time
@@ -51,29 +60,57 @@ func (gt GerritTime) MarshalJSON() ([]byte, error) {
 
 // ChangeInfo represents information about a Gerrit change
 type ChangeInfo struct {
-	ID                     string                   `json:"id"`
-	Project                string                   `json:"project"`
-	Branch                 string                   `json:"branch"`
-	ChangeID               string                   `json:"change_id"`
-	Subject                string                   `json:"subject"`
-	Status                 string                   `json:"status"`
-	Created                GerritTime               `json:"created"`
-	Updated                GerritTime               `json:"updated"`
-	Submitted              *GerritTime              `json:"submitted,omitempty"`
-	Submitter              *AccountInfo             `json:"submitter,omitempty"`
-	Owner                  AccountInfo              `json:"owner"`
-	Topic                  string                   `json:"topic,omitempty"`
-	Hashtags               []string                 `json:"hashtags,omitempty"`
-	Labels                 map[string]*LabelInfo    `json:"labels,omitempty"`
-	Messages               []ChangeMessageInfo      `json:"messages,omitempty"`
-	CurrentRevision        string                   `json:"current_revision,omitempty"`
-	Revisions              map[string]*RevisionInfo `json:"revisions,omitempty"`
-	Number                 int                      `json:"_number"`
-	Mergeable              bool                     `json:"mergeable,omitempty"`
-	Insertions             int                      `json:"insertions,omitempty"`
-	Deletions              int                      `json:"deletions,omitempty"`
-	UnresolvedCommentCount int                      `json:"unresolved_comment_count,omitempty"`
-	TotalCommentCount      int                      `json:"total_comment_count,omitempty"`
+	ID                     string                      `json:"id"`
+	Project                string                      `json:"project"`
+	Branch                 string                      `json:"branch"`
+	ChangeID               string                      `json:"change_id"`
+	Subject                string                      `json:"subject"`
+	Status                 string                      `json:"status"`
+	Created                GerritTime                  `json:"created"`
+	Updated                GerritTime                  `json:"updated"`
+	Submitted              *GerritTime                 `json:"submitted,omitempty"`
+	Submitter              *AccountInfo                `json:"submitter,omitempty"`
+	Owner                  AccountInfo                 `json:"owner"`
+	Topic                  string                      `json:"topic,omitempty"`
+	Hashtags               []string                    `json:"hashtags,omitempty"`
+	Labels                 map[string]*LabelInfo       `json:"labels,omitempty"`
+	PermittedLabels        map[string][]string         `json:"permitted_labels,omitempty"` // Values the caller may vote, with DETAILED_LABELS
+	Messages               []ChangeMessageInfo         `json:"messages,omitempty"`
+	CurrentRevision        string                      `json:"current_revision,omitempty"`
+	Revisions              map[string]*RevisionInfo    `json:"revisions,omitempty"`
+	Number                 int                         `json:"_number"`
+	Mergeable              bool                        `json:"mergeable,omitempty"`
+	Insertions             int                         `json:"insertions,omitempty"`
+	Deletions              int                         `json:"deletions,omitempty"`
+	UnresolvedCommentCount int                         `json:"unresolved_comment_count,omitempty"`
+	TotalCommentCount      int                         `json:"total_comment_count,omitempty"`
+	WorkInProgress         bool                        `json:"work_in_progress,omitempty"`
+	IsPrivate              bool                        `json:"is_private,omitempty"`
+	Submittable            bool                        `json:"submittable,omitempty"`         // With SUBMITTABLE
+	SubmitRequirements     []SubmitRequirementResult   `json:"submit_requirements,omitempty"` // With SUBMIT_REQUIREMENTS
+	Reviewers              map[string][]AccountInfo    `json:"reviewers,omitempty"`           // By state: REVIEWER, CC or REMOVED
+	AttentionSet           map[string]AttentionSetInfo `json:"attention_set,omitempty"`       // By account ID
+	MoreChanges            bool                        `json:"_more_changes,omitempty"`       // Set on the last change of a truncated query page
+}
+
+// SubmitRequirementResult represents the result of a submit requirement
+// on a change
+type SubmitRequirementResult struct {
+	Name                           string                           `json:"name"`
+	Description                    string                           `json:"description,omitempty"`
+	Status                         string                           `json:"status"` // SATISFIED, UNSATISFIED, OVERRIDDEN, NOT_APPLICABLE, ERROR or FORCED
+	IsLegacy                       bool                             `json:"is_legacy,omitempty"`
+	SubmittabilityExpressionResult *SubmitRequirementExpressionInfo `json:"submittability_expression_result,omitempty"`
+}
+
+// SubmitRequirementExpressionInfo represents the evaluation of a submit
+// requirement expression
+type SubmitRequirementExpressionInfo struct {
+	Expression   string   `json:"expression"`
+	Fulfilled    bool     `json:"fulfilled"`
+	Status       string   `json:"status,omitempty"`
+	PassingAtoms []string `json:"passing_atoms,omitempty"`
+	FailingAtoms []string `json:"failing_atoms,omitempty"`
 }
 
 // AccountInfo represents a Gerrit user account
@@ -86,15 +123,16 @@ type AccountInfo struct {
 
 // LabelInfo represents information about a label (e.g., Code-Review)
 type LabelInfo struct {
-	Optional     bool           `json:"optional,omitempty"`
-	Approved     *AccountInfo   `json:"approved,omitempty"`
-	Rejected     *AccountInfo   `json:"rejected,omitempty"`
-	Recommended  *AccountInfo   `json:"recommended,omitempty"`
-	Disliked     *AccountInfo   `json:"disliked,omitempty"`
-	Blocking     bool           `json:"blocking,omitempty"`
-	Value        int            `json:"value,omitempty"`
-	DefaultValue int            `json:"default_value,omitempty"`
-	All          []ApprovalInfo `json:"all,omitempty"`
+	Optional     bool              `json:"optional,omitempty"`
+	Approved     *AccountInfo      `json:"approved,omitempty"`
+	Rejected     *AccountInfo      `json:"rejected,omitempty"`
+	Recommended  *AccountInfo      `json:"recommended,omitempty"`
+	Disliked     *AccountInfo      `json:"disliked,omitempty"`
+	Blocking     bool              `json:"blocking,omitempty"`
+	Value        int               `json:"value,omitempty"`
+	DefaultValue int               `json:"default_value,omitempty"`
+	All          []ApprovalInfo    `json:"all,omitempty"`
+	Values       map[string]string `json:"values,omitempty"` // Value (e.g. "+1", " 0") to description, with DETAILED_LABELS
 }
 
 // ApprovalInfo represents a single vote/approval on a label
@@ -106,11 +144,12 @@ type ApprovalInfo struct {
 
 // ChangeMessageInfo represents a message on a change
 type ChangeMessageInfo struct {
-	ID      string       `json:"id"`
-	Author  *AccountInfo `json:"author,omitempty"`
-	Date    GerritTime   `json:"date"`
-	Message string       `json:"message"`
-	Tag     string       `json:"tag,omitempty"`
+	ID             string       `json:"id"`
+	Author         *AccountInfo `json:"author,omitempty"`
+	Date           GerritTime   `json:"date"`
+	Message        string       `json:"message"`
+	Tag            string       `json:"tag,omitempty"`
+	RevisionNumber int          `json:"_revision_number,omitempty"`
 }
 
 // RevisionInfo represents information about a patchset/revision
@@ -212,6 +251,48 @@ type CommentInfo struct {
 	Unresolved bool          `json:"unresolved,omitempty"`
 }
 
+// CheckLabels reports an error wrapping ErrLabelNotPermitted if the caller
+// may not vote value on a label of labels. The change must have been
+// fetched with DETAILED_LABELS for the caller's permitted values to be
+// checked; otherwise only the existence of the labels is.
+func (c *ChangeInfo) CheckLabels(labels map[string]int) error {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := labels[name]
+		if _, ok := c.Labels[name]; !ok {
+			return fmt.Errorf("%w: label %s does not exist on change %d", ErrLabelNotPermitted, name, c.Number)
+		}
+		if c.PermittedLabels == nil {
+			continue
+		}
+		if !slices.ContainsFunc(c.PermittedLabels[name], func(v string) bool {
+			permitted, err := strconv.Atoi(strings.TrimSpace(v))
+			return err == nil && permitted == value
+		}) {
+			return fmt.Errorf("%w: %s %+d is not permitted (allowed: %s)", ErrLabelNotPermitted,
+				name, value, strings.Join(c.PermittedLabels[name], ", "))
+		}
+	}
+	return nil
+}
+
+// CommentMessages returns the messages of comments grouped by file, as
+// returned by ListComments and ListDrafts
+func CommentMessages(comments map[string][]CommentInfo) []string {
+	var messages []string
+	for _, fileComments := range comments {
+		for _, comment := range fileComments {
+			messages = append(messages, comment.Message)
+		}
+	}
+	return messages
+}
+
 // CommentRange represents a range of text in a comment
 type CommentRange struct {
 	StartLine      int `json:"start_line"`
@@ -231,7 +312,7 @@ type RobotCommentInfo struct {
 
 // FixSuggestionInfo represents a suggested fix
 type FixSuggestionInfo struct {
-	FixID        string               `json:"fix_id"`
+	FixID        string               `json:"fix_id,omitempty"` // Assigned by Gerrit
 	Description  string               `json:"description"`
 	Replacements []FixReplacementInfo `json:"replacements"`
 }
@@ -248,7 +329,74 @@ type DraftInput struct {
 	Path       string        `json:"path"`                  // File path
 	Line       int           `json:"line,omitempty"`        // Line number (for line comments)
 	Range      *CommentRange `json:"range,omitempty"`       // Character range (for range comments)
+	Side       string        `json:"side,omitempty"`        // "PARENT" to comment on the base (e.g. deleted lines)
 	Message    string        `json:"message"`               // Comment text
 	Unresolved *bool         `json:"unresolved,omitempty"`  // Mark as unresolved (pointer to distinguish false from unset)
 	InReplyTo  string        `json:"in_reply_to,omitempty"` // Reply to another comment ID
 }
+
+// MessageInput represents input for change actions that take an optional
+// message: abandon, restore, revert, work in progress, ready and private
+type MessageInput struct {
+	Message string `json:"message,omitempty"`
+}
+
+// RebaseInput represents input for rebasing a change
+type RebaseInput struct {
+	Base string `json:"base,omitempty"` // Change, change~patchset or commit to rebase onto; empty for the branch tip
+}
+
+// Reviewer states of ReviewerInput
+const (
+	ReviewerStateReviewer = "REVIEWER"
+	ReviewerStateCC       = "CC"
+)
+
+// ReviewerInfo represents a reviewer of a change and its votes
+type ReviewerInfo struct {
+	AccountInfo
+	Approvals map[string]string `json:"approvals,omitempty"` // Vote per label, e.g. "+1"
+}
+
+// ReviewerInput represents input for adding a reviewer or CC to a change
+type ReviewerInput struct {
+	Reviewer string `json:"reviewer"`        // Account ID, email, username or group
+	State    string `json:"state,omitempty"` // ReviewerStateReviewer (default) or ReviewerStateCC
+}
+
+// ReviewerResult represents the outcome of adding a reviewer or CC
+type ReviewerResult struct {
+	Input     string         `json:"input"`
+	Reviewers []ReviewerInfo `json:"reviewers,omitempty"`
+	CCs       []ReviewerInfo `json:"ccs,omitempty"`
+	Error     string         `json:"error,omitempty"`   // Why the reviewer was not added
+	Confirm   bool           `json:"confirm,omitempty"` // A large group needs confirming
+}
+
+// SuggestedReviewerInfo represents an account or group suggested as
+// reviewer of a change
+type SuggestedReviewerInfo struct {
+	Account *AccountInfo   `json:"account,omitempty"`
+	Group   *GroupBaseInfo `json:"group,omitempty"`
+	Count   int            `json:"count,omitempty"` // Members of the group
+}
+
+// GroupBaseInfo represents a Gerrit group
+type GroupBaseInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// AttentionSetInput represents input for adding an account to, or
+// removing it from, the attention set of a change
+type AttentionSetInput struct {
+	User   string `json:"user,omitempty"` // Account ID, email or username
+	Reason string `json:"reason"`
+}
+
+// AttentionSetInfo represents an account in the attention set of a change
+type AttentionSetInfo struct {
+	Account    AccountInfo `json:"account"`
+	LastUpdate GerritTime  `json:"last_update"`
+	Reason     string      `json:"reason,omitempty"`
+}
@@ -0,0 +1,26 @@
+package git
+
+import "fmt"
+
+// CommandError is returned when a git command run by this package fails
+type CommandError struct {
+	Op     string // The failed operation, e.g. "clone" or "fetch patchset"
+	Err    error
+	Output string // Combined output of the command, if captured
+}
+
+func newCommandError(op string, err error, output []byte) *CommandError {
+	return &CommandError{Op: op, Err: err, Output: string(output)}
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("git %s failed: %v", e.Op, e.Err)
+	if e.Output != "" {
+		msg += "\nOutput: " + e.Output
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
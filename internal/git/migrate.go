@@ -0,0 +1,167 @@
+package git
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// RelocatedClone describes a legacy clone handled by MigrateLegacyClones
+type RelocatedClone struct {
+	Project string `json:"project"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Skipped string `json:"skipped,omitempty"` // Reason the clone was left in place
+}
+
+// migratingSuffix marks a legacy clone moved aside by MigrateLegacyClones.
+// It is not hidden, so a run interrupted between the two phases picks the
+// clone up again as a legacy clone.
+const migratingSuffix = ".migrating"
+
+// MigrateLegacyClones relocates clones created by the old flat layout
+// (<basePath>/<basename of project>) to the path returned by resolve.
+//
+// The owning project is derived from each clone's origin URL rather than its
+// directory name, so a clone that was shared by two projects with the same
+// basename ends up under the project it was actually cloned from.
+//
+// Clones move in two phases: all of them are first renamed aside, then into
+// place. A legacy clone such as <basePath>/platform therefore never becomes
+// the parent directory of another project's new path, e.g.
+// <basePath>/platform/foo.repo.
+func MigrateLegacyClones(ctx context.Context, basePath string, resolve func(project string) string) ([]RelocatedClone, error) {
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var results []RelocatedClone
+	var moving []RelocatedClone // From is the clone's name while moved aside
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		legacyPath := filepath.Join(basePath, name)
+		if _, err := os.Stat(filepath.Join(legacyPath, ".git")); err != nil {
+			// Not a clone (e.g. a parent directory of the new layout)
+			continue
+		}
+
+		originURL, err := originURL(ctx, legacyPath)
+		if err != nil {
+			results = append(results, RelocatedClone{From: legacyPath, Skipped: "cannot read origin URL"})
+			continue
+		}
+
+		project := ProjectFromGitURL(originURL)
+		if project == "" {
+			results = append(results, RelocatedClone{From: legacyPath, Skipped: "cannot derive project from origin " + originURL})
+			continue
+		}
+
+		target := resolve(project)
+		if target == legacyPath {
+			continue
+		}
+
+		aside := strings.TrimSuffix(legacyPath, migratingSuffix) + migratingSuffix
+		if aside != legacyPath {
+			if err := os.Rename(legacyPath, aside); err != nil {
+				return results, err
+			}
+		}
+		moving = append(moving, RelocatedClone{Project: project, From: aside, To: target})
+	}
+
+	for _, m := range moving {
+		legacyPath := strings.TrimSuffix(m.From, migratingSuffix)
+		result := RelocatedClone{Project: m.Project, From: legacyPath, To: m.To}
+
+		if reason := blockedTarget(basePath, m.To); reason != "" {
+			// Put the clone back where it was
+			if err := os.Rename(m.From, legacyPath); err != nil {
+				return results, err
+			}
+			result.Skipped = reason
+			results = append(results, result)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(m.To), 0755); err != nil {
+			return results, err
+		}
+		if err := os.Rename(m.From, m.To); err != nil {
+			return results, err
+		}
+
+		// Linked worktrees record absolute paths; drop any that went stale.
+		prune := exec.CommandContext(ctx, "git", "worktree", "prune")
+		prune.Dir = m.To
+		prune.Run()
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// blockedTarget returns why a clone cannot be moved to target: target
+// exists, or a directory between basePath and target is itself a clone
+func blockedTarget(basePath, target string) string {
+	if _, err := os.Stat(target); err == nil {
+		return "target already exists"
+	}
+	for dir := filepath.Dir(target); dir != basePath && strings.HasPrefix(dir, basePath+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "target is inside the clone " + dir
+		}
+	}
+	return ""
+}
+
+// ProjectFromGitURL extracts the Gerrit project name from a clone URL.
+// Supports scp-like ("alias:project") and URL ("ssh://host:29418/project") forms.
+func ProjectFromGitURL(gitURL string) string {
+	gitURL = strings.TrimSpace(gitURL)
+
+	var project string
+	if strings.Contains(gitURL, "://") {
+		u, err := url.Parse(gitURL)
+		if err != nil {
+			return ""
+		}
+		project = strings.TrimPrefix(u.Path, "/")
+		// Gerrit serves authenticated HTTP clones under /a/
+		if u.Scheme == "http" || u.Scheme == "https" {
+			project = strings.TrimPrefix(project, "a/")
+		}
+	} else {
+		idx := strings.Index(gitURL, ":")
+		if idx < 0 {
+			return ""
+		}
+		project = gitURL[idx+1:]
+	}
+
+	project = strings.Trim(project, "/")
+	return strings.TrimSuffix(project, ".git")
+}
+
+func originURL(ctx context.Context, repoPath string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
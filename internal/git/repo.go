@@ -27,6 +27,10 @@ func NewRepoManager(repoPath, gitURL string) *RepoManager {
 
 // CloneOrUpdate clones the repository if it doesn't exist, or updates it if it does
 func (r *RepoManager) CloneOrUpdate(ctx context.Context) error {
+	// Concurrent workers may share the same project clone
+	unlock := lockRepo(r.repoPath)
+	defer unlock()
+
 	// Check if repo already exists
 	if _, err := os.Stat(filepath.Join(r.repoPath, ".git")); err == nil {
 		// Repo exists, fetch latest
@@ -47,7 +51,7 @@ func (r *RepoManager) clone(ctx context.Context) error {
 	cmd := exec.CommandContext(ctx, "git", "clone", r.gitURL, r.repoPath)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("git clone failed: %w\nOutput: %s", err, string(output))
+		return newCommandError("clone", err, output)
 	}
 
 	return nil
@@ -59,7 +63,7 @@ func (r *RepoManager) fetch(ctx context.Context) error {
 	cmd.Dir = r.repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("git fetch failed: %w\nOutput: %s", err, string(output))
+		return newCommandError("fetch", err, output)
 	}
 
 	return nil
@@ -72,7 +76,7 @@ func (r *RepoManager) FetchPatchset(ctx context.Context, ref string) error {
 	cmd.Dir = r.repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("git fetch patchset failed: %w\nOutput: %s", err, string(output))
+		return newCommandError("fetch patchset", err, output)
 	}
 
 	return nil
@@ -89,7 +93,7 @@ func (r *RepoManager) CheckoutPatchset(ctx context.Context, changeNum, patchsetN
 	cmd.Dir = r.repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("git checkout failed: %w\nOutput: %s", err, string(output))
+		return "", newCommandError("checkout", err, output)
 	}
 
 	return branchName, nil
@@ -103,7 +107,7 @@ func (r *RepoManager) GetDiffStats(ctx context.Context) (int, string, error) {
 	cmd.Dir = r.repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return 0, "", fmt.Errorf("git diff failed: %w\nOutput: %s", err, string(output))
+		return 0, "", newCommandError("diff", err, output)
 	}
 
 	files := strings.TrimSpace(string(output))
@@ -119,12 +123,25 @@ func (r *RepoManager) GetDiffStats(ctx context.Context) (int, string, error) {
 	cmd.Dir = r.repoPath
 	statsOutput, err := cmd.CombinedOutput()
 	if err != nil {
-		return 0, "", fmt.Errorf("git diff --stat failed: %w\nOutput: %s", err, string(statsOutput))
+		return 0, "", newCommandError("diff --stat", err, statsOutput)
 	}
 
 	return changedFiles, string(statsOutput), nil
 }
 
+// GetNumstat returns exact per-file line counts of the current commit.
+// Renames are reported as a deletion and an addition.
+func (r *RepoManager) GetNumstat(ctx context.Context) ([]DiffStat, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--numstat", "--no-renames", "HEAD^")
+	cmd.Dir = r.repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, newCommandError("diff --numstat", err, nil)
+	}
+
+	return ParseNumstat(string(output)), nil
+}
+
 // Cleanup removes the temporary review branch and returns to master/main
 func (r *RepoManager) Cleanup(ctx context.Context, branchName string) error {
 	// Try to checkout main branch (try both 'main' and 'master')
@@ -142,7 +159,7 @@ func (r *RepoManager) Cleanup(ctx context.Context, branchName string) error {
 	cmd.Dir = r.repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("git branch -D failed: %w\nOutput: %s", err, string(output))
+		return newCommandError("branch -D", err, output)
 	}
 
 	return nil
@@ -161,7 +178,7 @@ func (r *RepoManager) GetCommitMessage(ctx context.Context) (string, error) {
 	cmd.Dir = r.repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("git log failed: %w\nOutput: %s", err, string(output))
+		return "", newCommandError("log", err, output)
 	}
 
 	return string(output), nil
@@ -173,7 +190,7 @@ func (r *RepoManager) GetChangedFiles(ctx context.Context) ([]string, error) {
 	cmd.Dir = r.repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("git diff failed: %w\nOutput: %s", err, string(output))
+		return nil, newCommandError("diff", err, output)
 	}
 
 	files := strings.TrimSpace(string(output))
@@ -190,7 +207,7 @@ func (r *RepoManager) GetFileDiff(ctx context.Context, filePath string) (string,
 	cmd.Dir = r.repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("git diff failed: %w\nOutput: %s", err, string(output))
+		return "", newCommandError("diff", err, output)
 	}
 
 	return string(output), nil
@@ -231,6 +248,28 @@ func ParseDiffStats(statsOutput string) map[string]DiffStat {
 	return result
 }
 
+// ParseNumstat parses git diff --numstat output. Binary files count as zero
+// changed lines.
+func ParseNumstat(output string) []DiffStat {
+	var stats []DiffStat
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		additions, _ := strconv.Atoi(fields[0])
+		deletions, _ := strconv.Atoi(fields[1])
+		stats = append(stats, DiffStat{
+			File:      fields[2],
+			Changes:   additions + deletions,
+			Additions: additions,
+			Deletions: deletions,
+		})
+	}
+	return stats
+}
+
 // DiffStat represents statistics for a single file's changes
 type DiffStat struct {
 	File      string
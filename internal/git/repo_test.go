@@ -2,6 +2,7 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -81,6 +82,26 @@ func TestParseDiffStats(t *testing.T) {
 	}
 }
 
+func TestParseNumstat(t *testing.T) {
+	output := "10\t2\tsrc/main.go\n-\t-\tassets/logo.png\n0\t7\tdocs/old name.md\n"
+
+	stats := ParseNumstat(output)
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 stats, got %d", len(stats))
+	}
+
+	want := []DiffStat{
+		{File: "src/main.go", Changes: 12, Additions: 10, Deletions: 2},
+		{File: "assets/logo.png"},
+		{File: "docs/old name.md", Changes: 7, Deletions: 7},
+	}
+	for i, w := range want {
+		if stats[i] != w {
+			t.Errorf("Expected %+v, got %+v", w, stats[i])
+		}
+	}
+}
+
 func TestRepoManager_Clone(t *testing.T) {
 	// Skip if git is not available
 	if _, err := exec.LookPath("git"); err != nil {
@@ -171,6 +192,28 @@ func TestRepoManager_CloneOrUpdate_ExistingRepo(t *testing.T) {
 	}
 }
 
+func TestRepoManager_CloneFailure(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tmpDir := t.TempDir()
+	rm := NewRepoManager(filepath.Join(tmpDir, "test-repo"), filepath.Join(tmpDir, "missing"))
+
+	err := rm.CloneOrUpdate(context.Background())
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("Expected a *CommandError, got %v", err)
+	}
+	if cmdErr.Op != "clone" || cmdErr.Output == "" {
+		t.Errorf("Expected the clone output, got %+v", cmdErr)
+	}
+	if !strings.HasPrefix(err.Error(), "git clone failed: ") {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+}
+
 func TestDiffStat(t *testing.T) {
 	stat := DiffStat{
 		File:      "test.go",
@@ -268,3 +311,312 @@ func TestCheckoutPatchset_ReusesExistingBranch(t *testing.T) {
 		t.Fatalf("expected current branch review-10722-4, got %q", got)
 	}
 }
+
+func TestAddWorktree_ConcurrentPatchsetsAreIsolated(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	repoPath := filepath.Join(tmpDir, "test-repo")
+	sourceRepo := filepath.Join(tmpDir, "source")
+	worktreeBase := filepath.Join(tmpDir, ".worktrees")
+
+	if err := os.MkdirAll(sourceRepo, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	setup := [][]string{
+		{"git", "init"},
+		{"git", "config", "user.email", "test@example.com"},
+		{"git", "config", "user.name", "Test User"},
+		{"git", "commit", "--allow-empty", "-m", "Initial commit"},
+		{"git", "checkout", "-b", "ps1"},
+		{"sh", "-c", "echo one > file.txt && git add file.txt && git commit -m ps1"},
+		{"git", "checkout", "-b", "ps2", "HEAD^"},
+		{"sh", "-c", "echo two > file.txt && git add file.txt && git commit -m ps2"},
+	}
+	for _, args := range setup {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = sourceRepo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Failed to setup source repo: %v, output=%s", err, string(output))
+		}
+	}
+
+	rm := NewRepoManager(repoPath, sourceRepo)
+	if err := rm.CloneOrUpdate(ctx); err != nil {
+		t.Fatalf("CloneOrUpdate() failed: %v", err)
+	}
+
+	wt1, err := rm.AddWorktree(ctx, worktreeBase, "refs/heads/ps1", 100, 1)
+	if err != nil {
+		t.Fatalf("AddWorktree(ps1) failed: %v", err)
+	}
+	wt2, err := rm.AddWorktree(ctx, worktreeBase, "refs/heads/ps2", 100, 2)
+	if err != nil {
+		t.Fatalf("AddWorktree(ps2) failed: %v", err)
+	}
+
+	for wt, want := range map[*Worktree]string{wt1: "one", wt2: "two"} {
+		data, err := os.ReadFile(filepath.Join(wt.Path, "file.txt"))
+		if err != nil {
+			t.Fatalf("failed to read file in %s: %v", wt.Path, err)
+		}
+		if got := strings.TrimSpace(string(data)); got != want {
+			t.Fatalf("worktree %s: expected %q, got %q", wt.Path, want, got)
+		}
+
+		files, err := wt.Repo().GetChangedFiles(ctx)
+		if err != nil {
+			t.Fatalf("GetChangedFiles() failed: %v", err)
+		}
+		if len(files) != 1 || files[0] != "file.txt" {
+			t.Fatalf("unexpected changed files in %s: %v", wt.Path, files)
+		}
+	}
+
+	if err := rm.RemoveWorktree(ctx, wt1); err != nil {
+		t.Fatalf("RemoveWorktree() failed: %v", err)
+	}
+	if _, err := os.Stat(wt1.Path); !os.IsNotExist(err) {
+		t.Fatalf("expected worktree directory to be removed, stat err=%v", err)
+	}
+
+	refCmd := exec.Command("git", "show-ref", "--verify", "--quiet", wt1.Ref)
+	refCmd.Dir = repoPath
+	if err := refCmd.Run(); err == nil {
+		t.Fatalf("expected ref %s to be deleted", wt1.Ref)
+	}
+
+	if err := rm.RemoveWorktree(ctx, wt2); err != nil {
+		t.Fatalf("RemoveWorktree() failed: %v", err)
+	}
+}
+
+func TestPruneWorktrees_RemovesStaleWorktrees(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	repoPath := filepath.Join(tmpDir, "test-repo")
+	sourceRepo := filepath.Join(tmpDir, "source")
+	worktreeBase := filepath.Join(tmpDir, ".worktrees")
+
+	if err := os.MkdirAll(sourceRepo, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	setup := [][]string{
+		{"git", "init"},
+		{"git", "config", "user.email", "test@example.com"},
+		{"git", "config", "user.name", "Test User"},
+		{"git", "commit", "--allow-empty", "-m", "Initial commit"},
+	}
+	for _, args := range setup {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = sourceRepo
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("Failed to setup source repo: %v", err)
+		}
+	}
+
+	rm := NewRepoManager(repoPath, sourceRepo)
+	if err := rm.CloneOrUpdate(ctx); err != nil {
+		t.Fatalf("CloneOrUpdate() failed: %v", err)
+	}
+
+	if _, err := rm.AddWorktree(ctx, worktreeBase, "HEAD", 200, 1); err != nil {
+		t.Fatalf("AddWorktree() failed: %v", err)
+	}
+
+	removed, err := PruneWorktrees(ctx, worktreeBase)
+	if err != nil {
+		t.Fatalf("PruneWorktrees() failed: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected 1 worktree removed, got %d", removed)
+	}
+
+	listCmd := exec.Command("git", "worktree", "list", "--porcelain")
+	listCmd.Dir = repoPath
+	output, err := listCmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git worktree list failed: %v, output=%s", err, string(output))
+	}
+	if strings.Count(string(output), "worktree ") != 1 {
+		t.Fatalf("expected only the main worktree after prune, got:\n%s", string(output))
+	}
+
+	refCmd := exec.Command("git", "for-each-ref", reviewRefPrefix)
+	refCmd.Dir = repoPath
+	refs, _ := refCmd.Output()
+	if strings.TrimSpace(string(refs)) != "" {
+		t.Fatalf("expected review refs to be pruned, got %s", string(refs))
+	}
+
+	// Missing directory is not an error
+	if _, err := PruneWorktrees(ctx, filepath.Join(tmpDir, "missing")); err != nil {
+		t.Fatalf("PruneWorktrees() on missing dir failed: %v", err)
+	}
+}
+
+func TestProjectFromGitURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"gerrit-review:platform/foo", "platform/foo"},
+		{"gerrit-review:tools/foo.git", "tools/foo"},
+		{"ssh://user@gerrit.example.com:29418/platform/foo", "platform/foo"},
+		{"https://gerrit.example.com/a/platform/foo", "platform/foo"},
+		{"/local/path/without/scheme", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := ProjectFromGitURL(tt.url); got != tt.want {
+				t.Errorf("ProjectFromGitURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyClones_RelocatesByOriginURL(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	ctx := context.Background()
+	basePath := t.TempDir()
+
+	// Legacy layout: tools/foo was cloned into <base>/foo
+	legacyPath := filepath.Join(basePath, "foo")
+	setup := [][]string{
+		{"git", "init", legacyPath},
+		{"git", "-C", legacyPath, "remote", "add", "origin", "gerrit-review:tools/foo"},
+	}
+	for _, args := range setup {
+		if output, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			t.Fatalf("Failed to setup legacy clone: %v, output=%s", err, string(output))
+		}
+	}
+
+	resolve := func(project string) string {
+		return filepath.Join(basePath, filepath.FromSlash(project)+".repo")
+	}
+
+	results, err := MigrateLegacyClones(ctx, basePath, resolve)
+	if err != nil {
+		t.Fatalf("MigrateLegacyClones() failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Project != "tools/foo" || results[0].Skipped != "" {
+		t.Fatalf("unexpected migration results: %+v", results)
+	}
+
+	if _, err := os.Stat(filepath.Join(basePath, "tools", "foo.repo", ".git")); err != nil {
+		t.Fatalf("expected clone at new location: %v", err)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Fatalf("expected legacy path to be gone, stat err=%v", err)
+	}
+
+	// Second run is a no-op
+	results, err = MigrateLegacyClones(ctx, basePath, resolve)
+	if err != nil {
+		t.Fatalf("second MigrateLegacyClones() failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected no-op on second run, got %+v", results)
+	}
+}
+
+func TestMigrateLegacyClones_NestedProjectOfLegacyClone(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	ctx := context.Background()
+	basePath := t.TempDir()
+
+	// <base>/platform is the legacy clone of project platform, and
+	// <base>/foo of platform/foo, whose new path is under <base>/platform
+	for name, project := range map[string]string{"platform": "platform", "foo": "platform/foo"} {
+		legacyPath := filepath.Join(basePath, name)
+		setup := [][]string{
+			{"git", "init", legacyPath},
+			{"git", "-C", legacyPath, "remote", "add", "origin", "gerrit-review:" + project},
+		}
+		for _, args := range setup {
+			if output, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+				t.Fatalf("Failed to setup legacy clone: %v, output=%s", err, string(output))
+			}
+		}
+	}
+
+	resolve := func(project string) string {
+		return filepath.Join(basePath, filepath.FromSlash(project)+".repo")
+	}
+
+	results, err := MigrateLegacyClones(ctx, basePath, resolve)
+	if err != nil {
+		t.Fatalf("MigrateLegacyClones() failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Skipped != "" || results[1].Skipped != "" {
+		t.Fatalf("unexpected migration results: %+v", results)
+	}
+
+	for _, path := range []string{"platform.repo", filepath.Join("platform", "foo.repo")} {
+		if _, err := os.Stat(filepath.Join(basePath, path, ".git")); err != nil {
+			t.Errorf("expected clone at %s: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(basePath, "platform.repo", "foo.repo")); !os.IsNotExist(err) {
+		t.Errorf("expected platform/foo not to be moved into the platform clone, stat err=%v", err)
+	}
+}
+
+func TestMigrateLegacyClones_TargetInsideClone(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	ctx := context.Background()
+	basePath := t.TempDir()
+
+	// A clone that is not a legacy clone occupies the parent of the target
+	legacyPath := filepath.Join(basePath, "foo")
+	setup := [][]string{
+		{"git", "init", filepath.Join(basePath, ".shared", "tools")},
+		{"git", "init", legacyPath},
+		{"git", "-C", legacyPath, "remote", "add", "origin", "gerrit-review:tools/foo"},
+	}
+	for _, args := range setup {
+		if output, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			t.Fatalf("Failed to setup clones: %v, output=%s", err, string(output))
+		}
+	}
+
+	resolve := func(project string) string {
+		return filepath.Join(basePath, ".shared", filepath.FromSlash(project)+".repo")
+	}
+
+	results, err := MigrateLegacyClones(ctx, basePath, resolve)
+	if err != nil {
+		t.Fatalf("MigrateLegacyClones() failed: %v", err)
+	}
+	if len(results) != 1 || !strings.HasPrefix(results[0].Skipped, "target is inside the clone") {
+		t.Fatalf("expected the move to be refused, got %+v", results)
+	}
+	if _, err := os.Stat(filepath.Join(legacyPath, ".git")); err != nil {
+		t.Errorf("expected the legacy clone to stay in place: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// reviewRefPrefix is the local ref namespace used to pin fetched patchsets.
+// Fetching into a dedicated ref (instead of FETCH_HEAD) keeps concurrent
+// fetches for the same project from clobbering each other.
+const reviewRefPrefix = "refs/gerrit-reviewer"
+
+// repoLocks serializes operations that mutate a shared clone (clone, fetch,
+// worktree add/remove). Keyed by absolute repository path.
+var repoLocks sync.Map
+
+func lockRepo(repoPath string) func() {
+	key := repoPath
+	if abs, err := filepath.Abs(repoPath); err == nil {
+		key = abs
+	}
+	v, _ := repoLocks.LoadOrStore(key, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// Worktree is an isolated checkout of a single patchset.
+// It shares the object store of the project clone it was created from.
+type Worktree struct {
+	Path string // Working tree directory
+	Ref  string // Local ref pinning the patchset commit
+
+	parent *RepoManager
+}
+
+// Repo returns a RepoManager operating on the worktree directory.
+func (w *Worktree) Repo() *RepoManager {
+	return NewRepoManager(w.Path, w.parent.gitURL)
+}
+
+// AddWorktree fetches a patchset into a private ref and checks it out into
+// a new worktree under baseDir. The caller must call RemoveWorktree when done.
+func (r *RepoManager) AddWorktree(ctx context.Context, baseDir, patchsetRef string, changeNum, patchsetNum int) (*Worktree, error) {
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create worktree base directory: %w", err)
+	}
+
+	wtPath, err := os.MkdirTemp(baseDir, fmt.Sprintf("%s-%d-%d-", filepath.Base(r.repoPath), changeNum, patchsetNum))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	localRef := fmt.Sprintf("%s/%s", reviewRefPrefix, filepath.Base(wtPath))
+
+	unlock := lockRepo(r.repoPath)
+	defer unlock()
+
+	cmd := exec.CommandContext(ctx, "git", "fetch", "origin", fmt.Sprintf("+%s:%s", patchsetRef, localRef))
+	cmd.Dir = r.repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		os.RemoveAll(wtPath)
+		return nil, newCommandError("fetch patchset", err, output)
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "worktree", "add", "--detach", wtPath, localRef)
+	cmd.Dir = r.repoPath
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		os.RemoveAll(wtPath)
+		r.deleteRef(ctx, localRef)
+		return nil, newCommandError("worktree add", err, output)
+	}
+
+	return &Worktree{
+		Path:   wtPath,
+		Ref:    localRef,
+		parent: r,
+	}, nil
+}
+
+// RemoveWorktree deletes a worktree created by AddWorktree and its pinning ref.
+func (r *RepoManager) RemoveWorktree(ctx context.Context, wt *Worktree) error {
+	unlock := lockRepo(r.repoPath)
+	defer unlock()
+
+	cmd := exec.CommandContext(ctx, "git", "worktree", "remove", "--force", wt.Path)
+	cmd.Dir = r.repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// Fall back to deleting the directory and letting git forget it.
+		if rmErr := os.RemoveAll(wt.Path); rmErr != nil {
+			return newCommandError("worktree remove", err, output)
+		}
+		prune := exec.CommandContext(ctx, "git", "worktree", "prune")
+		prune.Dir = r.repoPath
+		prune.Run()
+	}
+
+	r.deleteRef(ctx, wt.Ref)
+	return nil
+}
+
+func (r *RepoManager) deleteRef(ctx context.Context, ref string) {
+	cmd := exec.CommandContext(ctx, "git", "update-ref", "-d", ref)
+	cmd.Dir = r.repoPath
+	cmd.Run()
+}
+
+// PruneWorktrees removes every worktree left under baseDir (e.g. by a crashed
+// process) and prunes the stale worktree metadata and refs in their parent
+// repositories. Returns the number of worktrees removed.
+func PruneWorktrees(ctx context.Context, baseDir string) (int, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read worktree directory: %w", err)
+	}
+
+	parents := make(map[string]bool)
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		wtPath := filepath.Join(baseDir, entry.Name())
+
+		if commonDir := worktreeCommonDir(wtPath); commonDir != "" {
+			parents[commonDir] = true
+		}
+
+		if err := os.RemoveAll(wtPath); err != nil {
+			return removed, fmt.Errorf("failed to remove worktree %s: %w", wtPath, err)
+		}
+		removed++
+	}
+
+	for gitDir := range parents {
+		unlock := lockRepo(filepath.Dir(gitDir))
+
+		cmd := exec.CommandContext(ctx, "git", "--git-dir", gitDir, "worktree", "prune")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			unlock()
+			return removed, newCommandError("worktree prune", err, output)
+		}
+
+		cmd = exec.CommandContext(ctx, "git", "--git-dir", gitDir, "for-each-ref", "--format=%(refname)", reviewRefPrefix)
+		output, err := cmd.Output()
+		if err == nil {
+			for _, ref := range strings.Fields(string(output)) {
+				del := exec.CommandContext(ctx, "git", "--git-dir", gitDir, "update-ref", "-d", ref)
+				del.Run()
+			}
+		}
+		unlock()
+	}
+
+	return removed, nil
+}
+
+// worktreeCommonDir resolves the .git directory of the repository owning a
+// linked worktree by reading its ".git" file ("gitdir: <repo>/.git/worktrees/<name>").
+func worktreeCommonDir(wtPath string) string {
+	data, err := os.ReadFile(filepath.Join(wtPath, ".git"))
+	if err != nil {
+		return ""
+	}
+
+	gitDir := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(data)), "gitdir:"))
+	if gitDir == "" {
+		return ""
+	}
+
+	// <repo>/.git/worktrees/<name> -> <repo>/.git
+	return filepath.Dir(filepath.Dir(gitDir))
+}
@@ -0,0 +1,124 @@
+// Package locale holds the languages review output can be written in and
+// the bot-authored Gerrit messages for each of them.
+package locale
+
+import (
+	"sort"
+	"strings"
+)
+
+// Supported review languages
+const (
+	English            = "en"
+	TraditionalChinese = "zh-TW"
+
+	// Default is the language of the embedded review skill
+	Default = TraditionalChinese
+)
+
+// Messages holds the texts the reviewer posts to Gerrit in one language.
+// Format verbs are documented per field.
+type Messages struct {
+	Name         string // Language name as used in prompt instructions, e.g. "English"
+	ReviewFooter string // Appended to every posted review message
+	FixSuggested string // Description of the fix suggestion on robot comments
+
+	SizeSkipped           string // Default size skip message; supports {{reason}}, {{files}}, {{insertions}}
+	SizeOnlyIgnored       string // Reason: only ignored files changed
+	SizeTooManyFiles      string // Reason: %d files changed, %d limit
+	SizeTooManyInsertions string // Reason: %d lines inserted, %d limit
+
+	RateLimited string // Rate-limit failure notice: %s backend, %s error
+
+	VoteAdjusted       string // Vote changed by the policy: %+d requested, %+d posted, %s reason
+	LabelAdjusted      string // Other label changed by the policy: %s label, %+d requested, %+d posted, %s reason
+	VoteReasonMax      string // Reason: %+d account maximum
+	VoteReasonMin      string // Reason: %+d account minimum
+	VoteReasonSeverity string // Reason: %d comments of severity %s, %+d allowed
+}
+
+var catalog = map[string]*Messages{
+	English: {
+		Name:         "English",
+		ReviewFooter: "_Automated review by Gerrit AI Reviewer_",
+		FixSuggested: "Suggested fix",
+
+		SizeSkipped:           "Automated review skipped: {{reason}}.",
+		SizeOnlyIgnored:       "only generated, vendored or lock files changed",
+		SizeTooManyFiles:      "%d files changed, the limit is %d",
+		SizeTooManyInsertions: "%d lines inserted, the limit is %d",
+
+		RateLimited: "Automated review started but could not finish because the AI backend hit a rate limit.\n\n" +
+			"Backend: %s\n" +
+			"Result: no review comments were produced.\n" +
+			"Error: %s\n" +
+			"\nPlease retry this patchset later.",
+
+		VoteAdjusted:       "Vote changed from %+d to %+d by the review policy: %s.",
+		LabelAdjusted:      "%s changed from %+d to %+d by the review policy: %s.",
+		VoteReasonMax:      "the bot may vote at most %+d",
+		VoteReasonMin:      "the bot may vote at least %+d",
+		VoteReasonSeverity: "%d %s comment(s) allow at most %+d",
+	},
+	TraditionalChinese: {
+		Name:         "Traditional Chinese (繁體中文)",
+		ReviewFooter: "_由 Gerrit AI Reviewer 自動審查_",
+		FixSuggested: "建議修正",
+
+		SizeSkipped:           "已略過自動審查：{{reason}}。",
+		SizeOnlyIgnored:       "僅變更了產生的、vendored 或 lock 檔案",
+		SizeTooManyFiles:      "變更了 %d 個檔案，上限為 %d",
+		SizeTooManyInsertions: "新增了 %d 行，上限為 %d",
+
+		RateLimited: "自動審查已開始，但 AI 後端達到速率限制，未能完成。\n\n" +
+			"後端：%s\n" +
+			"結果：未產生任何審查評論。\n" +
+			"錯誤：%s\n" +
+			"\n請稍後再重試此 patchset。",
+
+		VoteAdjusted:       "審查政策已將投票由 %+d 調整為 %+d：%s。",
+		LabelAdjusted:      "審查政策已將 %s 由 %+d 調整為 %+d：%s。",
+		VoteReasonMax:      "機器人最高只能投 %+d",
+		VoteReasonMin:      "機器人最低只能投 %+d",
+		VoteReasonSeverity: "有 %d 則 %s 評論，最高只能投 %+d",
+	},
+}
+
+// aliases maps lower-cased language tags to a supported language
+var aliases = map[string]string{
+	"en":      English,
+	"en-us":   English,
+	"en-gb":   English,
+	"english": English,
+	"zh-tw":   TraditionalChinese,
+	"zh-hant": TraditionalChinese,
+	"zh-hk":   TraditionalChinese,
+}
+
+// Normalize returns the supported language lang refers to, accepting
+// common tag variants such as "en-US", "zh_TW" or "zh-Hant". ok is false
+// for an unsupported language.
+func Normalize(lang string) (string, bool) {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+	supported, ok := aliases[key]
+	return supported, ok
+}
+
+// Supported returns the sorted list of supported languages
+func Supported() []string {
+	langs := make([]string, 0, len(catalog))
+	for lang := range catalog {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// For returns the messages of lang, falling back to English for an empty
+// or unsupported language.
+func For(lang string) *Messages {
+	if supported, ok := Normalize(lang); ok {
+		return catalog[supported]
+	}
+	return catalog[English]
+}
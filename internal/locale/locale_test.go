@@ -0,0 +1,75 @@
+package locale
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+		ok   bool
+	}{
+		{"en", English, true},
+		{"en-US", English, true},
+		{" EN ", English, true},
+		{"zh-TW", TraditionalChinese, true},
+		{"zh_tw", TraditionalChinese, true},
+		{"zh-Hant", TraditionalChinese, true},
+		{"zh-CN", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Normalize(tt.lang)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Normalize(%q): expected (%q, %t), got (%q, %t)", tt.lang, tt.want, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestForFallsBackToEnglish(t *testing.T) {
+	if got := For("fr"); got != For(English) {
+		t.Errorf("Expected English messages for an unsupported language, got %q", got.Name)
+	}
+	if got := For("zh-hant"); got != For(TraditionalChinese) {
+		t.Errorf("Expected Traditional Chinese messages, got %q", got.Name)
+	}
+}
+
+func TestCatalogComplete(t *testing.T) {
+	for _, lang := range Supported() {
+		m := For(lang)
+		fields := map[string]string{
+			"Name":                  m.Name,
+			"ReviewFooter":          m.ReviewFooter,
+			"FixSuggested":          m.FixSuggested,
+			"SizeSkipped":           m.SizeSkipped,
+			"SizeOnlyIgnored":       m.SizeOnlyIgnored,
+			"SizeTooManyFiles":      m.SizeTooManyFiles,
+			"SizeTooManyInsertions": m.SizeTooManyInsertions,
+			"RateLimited":           m.RateLimited,
+			"VoteAdjusted":          m.VoteAdjusted,
+			"LabelAdjusted":         m.LabelAdjusted,
+			"VoteReasonMax":         m.VoteReasonMax,
+			"VoteReasonMin":         m.VoteReasonMin,
+			"VoteReasonSeverity":    m.VoteReasonSeverity,
+		}
+		for name, text := range fields {
+			if text == "" {
+				t.Errorf("%s: %s is empty", lang, name)
+			}
+		}
+		if !strings.Contains(m.SizeSkipped, "{{reason}}") {
+			t.Errorf("%s: SizeSkipped must contain {{reason}}", lang)
+		}
+		if got := fmt.Sprintf(m.SizeTooManyFiles, 3, 2); strings.Contains(got, "%!") {
+			t.Errorf("%s: bad SizeTooManyFiles verbs: %q", lang, got)
+		}
+		if got := fmt.Sprintf(m.RateLimited, "codex", "boom"); strings.Contains(got, "%!") || !strings.Contains(got, "codex") {
+			t.Errorf("%s: bad RateLimited verbs: %q", lang, got)
+		}
+	}
+}
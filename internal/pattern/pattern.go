@@ -0,0 +1,111 @@
+// Package pattern matches names and paths against glob or regular
+// expression patterns from the configuration.
+//
+// Globs use "*" and "?" within one path segment, "**" across segments and
+// "**/" for zero or more directories. A pattern without wildcards matches
+// exactly. Patterns prefixed with "re:" are regular expressions, anchored
+// on both ends.
+package pattern
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pattern is one compiled pattern
+type Pattern struct {
+	Source string
+	re     *regexp.Regexp
+}
+
+// List is a list of patterns; the zero value matches nothing
+type List []Pattern
+
+// Compile compiles patterns, skipping blank ones
+func Compile(sources []string) (List, error) {
+	var list List
+	for _, source := range sources {
+		source = strings.TrimSpace(source)
+		if source == "" {
+			continue
+		}
+
+		expr := globToRegexp(source)
+		if re, ok := strings.CutPrefix(source, "re:"); ok {
+			expr = "^(?:" + re + ")$"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", source, err)
+		}
+		list = append(list, Pattern{Source: source, re: re})
+	}
+	return list, nil
+}
+
+// MustCompile is like Compile but panics on invalid patterns
+func MustCompile(sources ...string) List {
+	list, err := Compile(sources)
+	if err != nil {
+		panic(err)
+	}
+	return list
+}
+
+// globToRegexp converts a glob to an anchored regular expression
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			switch {
+			case strings.HasPrefix(glob[i:], "**/"):
+				b.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(glob[i:], "**"):
+				b.WriteString(".*")
+				i++
+			default:
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// Match returns the source of the first pattern matching value, or ""
+func (l List) Match(value string) string {
+	for _, p := range l {
+		if p.re.MatchString(value) {
+			return p.Source
+		}
+	}
+	return ""
+}
+
+// MatchAny returns the source of the first pattern matching any of values, or ""
+func (l List) MatchAny(values []string) string {
+	for _, value := range values {
+		if p := l.Match(value); p != "" {
+			return p
+		}
+	}
+	return ""
+}
+
+// MatchAll reports whether every value matches some pattern
+func (l List) MatchAll(values []string) bool {
+	for _, value := range values {
+		if l.Match(value) == "" {
+			return false
+		}
+	}
+	return true
+}
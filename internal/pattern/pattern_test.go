@@ -0,0 +1,62 @@
+package pattern
+
+import (
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"platform/app", "platform/app", true},
+		{"platform/app", "platform/app2", false},
+		{"platform/*", "platform/app", true},
+		{"platform/*", "platform/core/app", false},
+		{"platform/**", "platform/core/app", true},
+		{"**/vendor/**", "vendor/github.com/x/y.go", true},
+		{"**/vendor/**", "src/vendor/lib.go", true},
+		{"**/go.sum", "go.sum", true},
+		{"**/go.sum", "tools/go.sum", true},
+		{"**/go.sum", "notgo.sum", false},
+		{"**.lock", "frontend/yarn.lock", true},
+		{"release-?.?", "release-1.2", true},
+		{"a.b", "axb", false},
+		{"re:release-[0-9]+", "release-10", true},
+		{"re:release-[0-9]+", "release-10-rc", false},
+		{"re:main|dev", "dev", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"~"+tt.value, func(t *testing.T) {
+			got := MustCompile(tt.pattern).Match(tt.value) != ""
+			if got != tt.want {
+				t.Errorf("Expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchAnyAndAll(t *testing.T) {
+	list := MustCompile("**.md", "docs/**")
+
+	if got := list.MatchAny([]string{"main.go", "README.md"}); got != "**.md" {
+		t.Errorf("Expected **.md, got %q", got)
+	}
+	if list.MatchAll([]string{"main.go", "README.md"}) {
+		t.Errorf("expected MatchAll to fail when one value does not match")
+	}
+	if !list.MatchAll([]string{"docs/guide.txt", "README.md"}) {
+		t.Errorf("expected MatchAll to succeed when every value matches")
+	}
+	if (List{}).Match("anything") != "" {
+		t.Errorf("expected empty list to match nothing")
+	}
+}
+
+func TestCompileInvalidRegexp(t *testing.T) {
+	if _, err := Compile([]string{"re:(unclosed"}); err == nil {
+		t.Fatalf("expected Compile to fail for invalid regexp")
+	}
+}
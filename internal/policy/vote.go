@@ -0,0 +1,197 @@
+// Package policy decides which votes the bot may cast on a
+// change, based on the severities of its comments and the configured
+// range of the bot account.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
+	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
+)
+
+// Severity of a review comment, taken from its "[P0]".."[P3]" prefix
+type Severity string
+
+// Comment severities, most severe first
+const (
+	SeverityNone Severity = ""
+	P0           Severity = "P0" // Critical, blocks merge
+	P1           Severity = "P1" // High, blocks merge
+	P2           Severity = "P2" // Medium, suggestion
+	P3           Severity = "P3" // Praise
+)
+
+// severities lists the severities in order, most severe first
+var severities = []Severity{P0, P1, P2, P3}
+
+// ParseSeverity returns the severity prefix of a comment message
+func ParseSeverity(message string) Severity {
+	for _, s := range severities {
+		if strings.HasPrefix(message, "["+string(s)+"]") {
+			return s
+		}
+	}
+	return SeverityNone
+}
+
+// Blocking reports whether comments of severity s block the merge
+func (s Severity) Blocking() bool {
+	return s == P0 || s == P1
+}
+
+// severityCeiling is the highest vote allowed with a comment of a severity
+var severityCeiling = map[Severity]int{
+	P0: -1,
+	P1: -1,
+	P2: 0,
+}
+
+// HardMax is the highest vote the bot can ever cast. +2 approves a change
+// for submission, which is always left to humans.
+const HardMax = 1
+
+// Enforcement modes for votes outside the allowed range
+const (
+	Clamp  = "clamp"  // Post the nearest allowed vote instead
+	Reject = "reject" // Refuse to post the review
+)
+
+// ErrVoteRejected is returned by Evaluate for a vote outside the allowed
+// range when the policy rejects instead of clamping
+var ErrVoteRejected = errors.New("vote rejected by policy")
+
+// VotePolicy is the vote range of the bot account and how it is enforced
+type VotePolicy struct {
+	Max     int    // Highest vote the account may cast; capped at HardMax
+	Min     int    // Lowest vote the account may cast
+	Enforce string // Clamp (default) or Reject
+}
+
+// Decision is the outcome of applying a VotePolicy to a review
+type Decision struct {
+	Requested int              `json:"requested"`
+	Vote      int              `json:"vote"`    // Vote to post
+	Ceiling   int              `json:"ceiling"` // Highest allowed vote
+	Floor     int              `json:"floor"`   // Lowest allowed vote
+	Counts    map[Severity]int `json:"severities,omitempty"`
+	Rule      string           `json:"rule,omitempty"`     // Rule that changed the vote: "max", "min" or "severity"
+	Severity  Severity         `json:"severity,omitempty"` // Severity behind Ceiling when Rule is "severity"
+}
+
+// Adjusted reports whether the policy changed the requested vote
+func (d Decision) Adjusted() bool {
+	return d.Vote != d.Requested
+}
+
+// Explain describes why the vote was changed, in the language of m. It
+// returns "" when the vote was not changed.
+func (d Decision) Explain(m *locale.Messages) string {
+	if !d.Adjusted() {
+		return ""
+	}
+	return fmt.Sprintf(m.VoteAdjusted, d.Requested, d.Vote, d.reason(m))
+}
+
+func (d Decision) reason(m *locale.Messages) string {
+	switch d.Rule {
+	case "severity":
+		return fmt.Sprintf(m.VoteReasonSeverity, d.Counts[d.Severity], d.Severity, d.Ceiling)
+	case "min":
+		return fmt.Sprintf(m.VoteReasonMin, d.Floor)
+	default:
+		return fmt.Sprintf(m.VoteReasonMax, d.Ceiling)
+	}
+}
+
+// Evaluate decides the vote to post for a requested vote and the messages
+// of the comments published with it. A P0 or P1 comment allows at most -1
+// and a P2 comment at most 0, within the account's range. A vote outside
+// the allowed range is clamped, or rejected with ErrVoteRejected.
+func (p VotePolicy) Evaluate(requested int, messages []string) (Decision, error) {
+	d := Decision{
+		Requested: requested,
+		Ceiling:   min(p.Max, HardMax),
+		Floor:     p.Min,
+		Rule:      "max",
+	}
+
+	for _, message := range messages {
+		if s := ParseSeverity(message); s != SeverityNone {
+			if d.Counts == nil {
+				d.Counts = make(map[Severity]int)
+			}
+			d.Counts[s]++
+		}
+	}
+	for _, s := range severities {
+		if ceiling, ok := severityCeiling[s]; ok && d.Counts[s] > 0 && ceiling < d.Ceiling {
+			d.Ceiling, d.Rule, d.Severity = ceiling, "severity", s
+		}
+	}
+	// The account may not be allowed to vote as low as a severity asks.
+	d.Ceiling = max(d.Ceiling, d.Floor)
+
+	switch {
+	case requested > d.Ceiling:
+		d.Vote = d.Ceiling
+	case requested < d.Floor:
+		d.Vote, d.Rule, d.Severity = d.Floor, "min", SeverityNone
+	default:
+		d.Vote, d.Rule, d.Severity = requested, "", SeverityNone
+	}
+
+	if d.Adjusted() && p.Enforce == Reject {
+		return d, fmt.Errorf("%w: %s", ErrVoteRejected, d.reason(locale.For(locale.English)))
+	}
+	return d, nil
+}
+
+// ApplyToReview enforces p on a review before it is posted. drafts are the
+// messages of the draft comments the review publishes besides its inline
+// comments. The decided vote replaces result.Vote. Votes on other labels
+// get the same ceiling and floor, so a blocking comment cannot be bypassed
+// by voting on Code-Review through a label when the vote label is another
+// one. Every change is explained at the end of the summary in the language
+// of m.
+func (p VotePolicy) ApplyToReview(result *types.ReviewResult, drafts []string, m *locale.Messages) (Decision, error) {
+	messages := append([]string(nil), drafts...)
+	for _, c := range result.Comments {
+		messages = append(messages, c.Message)
+	}
+
+	d, err := p.Evaluate(result.Vote, messages)
+	if err != nil {
+		return d, err
+	}
+	var notes []string
+	if d.Adjusted() {
+		result.Vote = d.Vote
+		notes = append(notes, d.Explain(m))
+	}
+
+	labels := make([]string, 0, len(result.Labels))
+	for name := range result.Labels {
+		labels = append(labels, name)
+	}
+	sort.Strings(labels)
+	for _, name := range labels {
+		value := result.Labels[name]
+		ld, err := p.Evaluate(value, messages)
+		if err != nil {
+			return d, fmt.Errorf("%s %+d: %w", name, value, err)
+		}
+		if ld.Adjusted() {
+			result.Labels[name] = ld.Vote
+			notes = append(notes, fmt.Sprintf(m.LabelAdjusted, name, value, ld.Vote, ld.reason(m)))
+		}
+	}
+
+	if len(notes) > 0 {
+		result.Summary = strings.TrimRight(result.Summary, "\n") + "\n\n" + strings.Join(notes, "\n")
+	}
+	return d, nil
+}
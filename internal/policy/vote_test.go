@@ -0,0 +1,157 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
+	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
+)
+
+func TestParseSeverity(t *testing.T) {
+	tests := map[string]Severity{
+		"[P0] SQL injection":     P0,
+		"[P1] missing err check": P1,
+		"[P2] rename this":       P2,
+		"[P3] nice":              P3,
+		"no prefix":              SeverityNone,
+		" [P1] leading space":    SeverityNone,
+		"[P9] unknown":           SeverityNone,
+	}
+	for message, want := range tests {
+		if got := ParseSeverity(message); got != want {
+			t.Errorf("ParseSeverity(%q): expected %q, got %q", message, want, got)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	standard := VotePolicy{Max: 1, Min: -1, Enforce: Clamp}
+
+	tests := []struct {
+		name      string
+		policy    VotePolicy
+		requested int
+		messages  []string
+		want      int
+		rule      string
+	}{
+		{"clean +1", standard, 1, []string{"[P3] nice"}, 1, ""},
+		{"+2 capped", VotePolicy{Max: 2, Min: -1}, 2, nil, 1, "max"},
+		{"account max 0", VotePolicy{Max: 0, Min: -1}, 1, nil, 0, "max"},
+		{"P1 forces -1", standard, 1, []string{"[P2] a", "[P1] b"}, -1, "severity"},
+		{"P2 caps at 0", standard, 1, []string{"[P2] a", "[P3] b"}, 0, "severity"},
+		{"P0 with 0", standard, 0, []string{"[P0] a"}, -1, "severity"},
+		{"-1 allowed without findings", standard, -1, nil, -1, ""},
+		{"-2 raised to min", standard, -2, nil, -1, "min"},
+		{"severity within account range", VotePolicy{Max: 1, Min: 0}, 1, []string{"[P1] a"}, 0, "severity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := tt.policy.Evaluate(tt.requested, tt.messages)
+			if err != nil {
+				t.Fatalf("Evaluate failed: %v", err)
+			}
+			if d.Vote != tt.want || d.Rule != tt.rule {
+				t.Errorf("Expected vote %+d by rule %q, got %+d by %q", tt.want, tt.rule, d.Vote, d.Rule)
+			}
+		})
+	}
+}
+
+func TestEvaluateReject(t *testing.T) {
+	p := VotePolicy{Max: 1, Min: -1, Enforce: Reject}
+
+	_, err := p.Evaluate(1, []string{"[P1] bug", "[P1] another"})
+	if !errors.Is(err, ErrVoteRejected) {
+		t.Fatalf("Expected ErrVoteRejected, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "2 P1 comment(s) allow at most -1") {
+		t.Errorf("Expected the reason in the error, got %v", err)
+	}
+
+	if d, err := p.Evaluate(-1, []string{"[P1] bug"}); err != nil || d.Vote != -1 {
+		t.Errorf("Expected compliant vote to pass, got %+v, %v", d, err)
+	}
+}
+
+func TestDecisionExplain(t *testing.T) {
+	d, _ := VotePolicy{Max: 1, Min: -1}.Evaluate(1, []string{"[P1] bug"})
+
+	want := "Vote changed from +1 to -1 by the review policy: 1 P1 comment(s) allow at most -1."
+	if got := d.Explain(locale.For(locale.English)); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	d, _ = VotePolicy{Max: 1, Min: -1}.Evaluate(0, nil)
+	if got := d.Explain(locale.For(locale.English)); got != "" {
+		t.Errorf("Expected no explanation for an unchanged vote, got %q", got)
+	}
+}
+
+func TestApplyToReview(t *testing.T) {
+	p := VotePolicy{Max: 1, Min: -1, Enforce: Clamp}
+	result := &types.ReviewResult{
+		Summary:  "Looks fine.",
+		Vote:     1,
+		Comments: []types.Comment{{File: "a.go", Line: 1, Message: "[P2] rename"}},
+	}
+
+	d, err := p.ApplyToReview(result, []string{"[P1] nil dereference"}, locale.For(locale.English))
+	if err != nil {
+		t.Fatalf("ApplyToReview failed: %v", err)
+	}
+	if result.Vote != -1 || d.Severity != P1 {
+		t.Errorf("Expected the P1 draft to force -1, got vote %+d (%+v)", result.Vote, d)
+	}
+	if !strings.HasPrefix(result.Summary, "Looks fine.\n\nVote changed from +1 to -1") {
+		t.Errorf("Expected the adjustment in the summary, got %q", result.Summary)
+	}
+}
+
+func TestApplyToReviewCapsOtherLabels(t *testing.T) {
+	result := &types.ReviewResult{
+		Summary: "Done.",
+		Vote:    0,
+		Labels:  map[string]int{"Verified": 1, "Code-Review": 2},
+	}
+
+	if _, err := (VotePolicy{Max: 1, Min: -1, Enforce: Reject}).ApplyToReview(result, nil, locale.For(locale.English)); !errors.Is(err, ErrVoteRejected) {
+		t.Fatalf("Expected +2 on another label to be rejected, got %v", err)
+	}
+
+	if _, err := (VotePolicy{Max: 1, Min: -1}).ApplyToReview(result, nil, locale.For(locale.English)); err != nil {
+		t.Fatalf("ApplyToReview failed: %v", err)
+	}
+	if result.Labels["Code-Review"] != 1 || result.Labels["Verified"] != 1 {
+		t.Errorf("Expected Code-Review capped at +1, got %v", result.Labels)
+	}
+	if !strings.Contains(result.Summary, "Code-Review changed from +2 to +1") {
+		t.Errorf("Expected the cap in the summary, got %q", result.Summary)
+	}
+}
+
+func TestApplyToReviewAppliesSeverityToOtherLabels(t *testing.T) {
+	result := &types.ReviewResult{
+		Summary: "Done.",
+		Vote:    0,
+		Labels:  map[string]int{"Code-Review": 1},
+	}
+	drafts := []string{"[P0] data loss"}
+
+	if _, err := (VotePolicy{Max: 1, Min: -2, Enforce: Reject}).ApplyToReview(result, drafts, locale.For(locale.English)); !errors.Is(err, ErrVoteRejected) {
+		t.Fatalf("Expected Code-Review +1 with a P0 draft to be rejected, got %v", err)
+	}
+
+	if _, err := (VotePolicy{Max: 1, Min: -2}).ApplyToReview(result, drafts, locale.For(locale.English)); err != nil {
+		t.Fatalf("ApplyToReview failed: %v", err)
+	}
+	if result.Labels["Code-Review"] != -1 {
+		t.Errorf("Expected Code-Review clamped to -1, got %v", result.Labels)
+	}
+	if !strings.Contains(result.Summary, "Code-Review changed from +1 to -1") {
+		t.Errorf("Expected the adjustment in the summary, got %q", result.Summary)
+	}
+}
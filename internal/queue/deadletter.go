@@ -0,0 +1,167 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead-lettered task not found")
+
+// DeadLetter is a task that failed on every allowed attempt
+type DeadLetter struct {
+	Task       Task      `json:"task"`        // Task.Errors holds the error of every attempt
+	ErrorClass string    `json:"error_class"` // Class of the final error (see worker.ClassifyError)
+	FailedAt   time.Time `json:"failed_at"`
+}
+
+// DeadLetterStore keeps dead-lettered tasks as one JSON file each, so the
+// serve process and the CLI can share it without coordination.
+//
+// Layout under dir:
+//
+//	dead/<task-id>.json     tasks waiting for inspection
+//	requeue/<task-id>.json  tasks handed back to serve mode by Requeue
+type DeadLetterStore struct {
+	dir string
+}
+
+// NewDeadLetterStore creates a store rooted at dir
+func NewDeadLetterStore(dir string) *DeadLetterStore {
+	return &DeadLetterStore{dir: dir}
+}
+
+func (s *DeadLetterStore) deadDir() string    { return filepath.Join(s.dir, "dead") }
+func (s *DeadLetterStore) requeueDir() string { return filepath.Join(s.dir, "requeue") }
+
+func entryFile(dir, taskID string) string {
+	return filepath.Join(dir, url.PathEscape(taskID)+".json")
+}
+
+// Add stores a dead-lettered task, replacing an earlier entry for the same task
+func (s *DeadLetterStore) Add(entry DeadLetter) error {
+	return writeEntry(entryFile(s.deadDir(), entry.Task.ID), entry)
+}
+
+// List returns all dead-lettered tasks, oldest failure first
+func (s *DeadLetterStore) List() ([]DeadLetter, error) {
+	entries, err := readEntries(s.deadDir())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FailedAt.Before(entries[j].FailedAt)
+	})
+	return entries, nil
+}
+
+// Get returns the dead-lettered task with the given ID
+func (s *DeadLetterStore) Get(taskID string) (*DeadLetter, error) {
+	entry, err := readEntry(entryFile(s.deadDir(), taskID))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrDeadLetterNotFound, taskID)
+	}
+	return entry, err
+}
+
+// Requeue hands a dead-lettered task back to serve mode, which picks it up
+// via TakeRequeued.
+func (s *DeadLetterStore) Requeue(taskID string) (*DeadLetter, error) {
+	entry, err := s.Get(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.requeueDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create requeue directory: %w", err)
+	}
+	if err := os.Rename(entryFile(s.deadDir(), taskID), entryFile(s.requeueDir(), taskID)); err != nil {
+		return nil, fmt.Errorf("failed to requeue %s: %w", taskID, err)
+	}
+
+	return entry, nil
+}
+
+// TakeRequeued removes and returns all tasks handed back by Requeue, with
+// their attempt count reset.
+func (s *DeadLetterStore) TakeRequeued() ([]Task, error) {
+	entries, err := readEntries(s.requeueDir())
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(entries))
+	for _, entry := range entries {
+		if err := os.Remove(entryFile(s.requeueDir(), entry.Task.ID)); err != nil {
+			return tasks, fmt.Errorf("failed to take requeued %s: %w", entry.Task.ID, err)
+		}
+		task := entry.Task
+		task.Attempt = 0
+		task.Errors = nil
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+func writeEntry(path string, entry DeadLetter) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode dead-letter entry: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write dead-letter entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write dead-letter entry: %w", err)
+	}
+	return nil
+}
+
+func readEntry(path string) (*DeadLetter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry DeadLetter
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse dead-letter entry %s: %w", path, err)
+	}
+	return &entry, nil
+}
+
+func readEntries(dir string) ([]DeadLetter, error) {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []DeadLetter{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	entries := make([]DeadLetter, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		entry, err := readEntry(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,188 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Journal operations
+const (
+	journalPush = "push"
+	journalDone = "done"
+)
+
+// journalRecord is one line of the append-only queue journal
+type journalRecord struct {
+	Op   string `json:"op"`
+	Task *Task  `json:"task,omitempty"`
+	ID   string `json:"id,omitempty"`
+}
+
+// journal persists queue operations as JSON lines.
+//
+// A task is pending from its "push" record until a matching "done" record.
+// The file is rewritten with only the pending tasks when it is opened and
+// truncated whenever the queue drains, so it never grows without bound.
+type journal struct {
+	path string
+	file *os.File
+}
+
+// openJournal opens (or creates) the journal at path and returns the tasks
+// that were pushed but never marked done, in push order.
+func openJournal(path string) (*journal, []Task, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create queue journal directory: %w", err)
+	}
+
+	pending, err := readJournal(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	j := &journal{path: path}
+	if err := j.rewrite(pending); err != nil {
+		return nil, nil, err
+	}
+
+	return j, pending, nil
+}
+
+// readJournal replays the journal file. A truncated trailing line (from a
+// crash mid-write) is ignored.
+func readJournal(path string) ([]Task, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open queue journal: %w", err)
+	}
+	defer f.Close()
+
+	var order []string
+	tasks := make(map[string]Task)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+
+		switch rec.Op {
+		case journalPush:
+			if rec.Task == nil {
+				continue
+			}
+			if _, ok := tasks[rec.Task.ID]; !ok {
+				order = append(order, rec.Task.ID)
+			}
+			tasks[rec.Task.ID] = *rec.Task
+		case journalDone:
+			delete(tasks, rec.ID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read queue journal: %w", err)
+	}
+
+	pending := make([]Task, 0, len(tasks))
+	for _, id := range order {
+		if task, ok := tasks[id]; ok {
+			pending = append(pending, task)
+			delete(tasks, id)
+		}
+	}
+
+	return pending, nil
+}
+
+// rewrite atomically replaces the journal with push records for tasks and
+// reopens it for appending.
+func (j *journal) rewrite(tasks []Task) error {
+	if j.file != nil {
+		j.file.Close()
+		j.file = nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), ".journal-*")
+	if err != nil {
+		return fmt.Errorf("failed to create queue journal: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for i := range tasks {
+		if err := writeRecord(w, journalRecord{Op: journalPush, Task: &tasks[i]}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write queue journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync queue journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write queue journal: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		return fmt.Errorf("failed to replace queue journal: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open queue journal: %w", err)
+	}
+	j.file = f
+
+	return nil
+}
+
+func (j *journal) push(task Task) error {
+	return j.append(journalRecord{Op: journalPush, Task: &task})
+}
+
+func (j *journal) done(id string) error {
+	return j.append(journalRecord{Op: journalDone, ID: id})
+}
+
+func (j *journal) append(rec journalRecord) error {
+	if err := writeRecord(j.file, rec); err != nil {
+		return err
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync queue journal: %w", err)
+	}
+	return nil
+}
+
+func (j *journal) close() error {
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+func writeRecord(w io.Writer, rec journalRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode queue journal record: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write queue journal: %w", err)
+	}
+	return nil
+}
@@ -6,24 +6,39 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
 )
 
 var (
 	ErrDuplicateTask = errors.New("task already in queue")
 	ErrQueueFull     = errors.New("queue full")
 	ErrObsoleteTask  = errors.New("obsolete task")
+
+	// Cancellation causes of running tasks, see Queue.Start
+	ErrSuperseded   = errors.New("superseded by a newer patchset")
+	ErrChangeClosed = errors.New("change abandoned or merged")
 )
 
 // Task represents a review task
 //
 // ChangeNumber + PatchsetNumber identifies the revision being reviewed.
 type Task struct {
-	ID             string
-	Project        string
-	ChangeNumber   int
-	PatchsetNumber int
-	Subject        string
-	CreatedAt      time.Time
+	ID             string    `json:"id"`
+	Project        string    `json:"project"`
+	Branch         string    `json:"branch,omitempty"`
+	ChangeNumber   int       `json:"change_number"`
+	PatchsetNumber int       `json:"patchset_number"`
+	Subject        string    `json:"subject,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	Attempt        int       `json:"attempt,omitempty"` // Failed attempts so far
+	Errors         []string  `json:"errors,omitempty"`  // Error of each failed attempt
+
+	// Set for reviews requested with a comment trigger
+	OnDemand    bool     `json:"on_demand,omitempty"`
+	RequestedBy string   `json:"requested_by,omitempty"`
+	Focus       []string `json:"focus,omitempty"`       // Areas to concentrate on
+	Incremental bool     `json:"incremental,omitempty"` // Review only changes since the previous patchset
 }
 
 // QueueConfig configures queue behavior.
@@ -31,55 +46,178 @@ type QueueConfig struct {
 	LazyMode bool // Keep only latest patchset per change
 }
 
-// Queue is an in-memory task queue
+// Queue is a task queue. It is in-memory unless created with
+// NewDurableQueue, which also records every task in an on-disk journal.
 type Queue struct {
 	tasks          chan Task
-	inflight       map[string]bool
+	inflight       map[string]string // change key by task ID
+	pending        map[string]int    // in-flight tasks by change key
 	latestByChange map[string]int
+	running        map[string]runningTask // by task ID
+	closedChanges  map[string]bool        // changes abandoned or merged with tasks in flight, by change key
 	lazyMode       bool
+	journal        *journal
+	closed         bool
 	mu             sync.RWMutex
 }
 
+// runningTask is a task handed to a worker by Start
+type runningTask struct {
+	task   Task
+	cancel context.CancelCauseFunc
+}
+
 // NewQueue creates a new task queue with the given capacity.
 func NewQueue(size int, cfg QueueConfig) *Queue {
 	return &Queue{
 		tasks:          make(chan Task, size),
-		inflight:       make(map[string]bool),
+		inflight:       make(map[string]string),
+		pending:        make(map[string]int),
 		latestByChange: make(map[string]int),
+		running:        make(map[string]runningTask),
+		closedChanges:  make(map[string]bool),
 		lazyMode:       cfg.LazyMode,
 	}
 }
 
+// NewDurableQueue creates a queue backed by the journal at journalPath.
+//
+// Tasks that were queued or in flight when the previous process stopped are
+// re-enqueued in their original order and returned. LazyMode supersession
+// applies to them as if they had just been pushed. The queue grows beyond
+// size if more tasks than that were pending.
+func NewDurableQueue(size int, cfg QueueConfig, journalPath string) (*Queue, []Task, error) {
+	j, pending, err := openJournal(journalPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q := NewQueue(max(size, len(pending)), cfg)
+
+	restored := make([]Task, 0, len(pending))
+	for _, task := range pending {
+		if err := q.Push(task); err != nil {
+			// Superseded by a newer patchset of the same change
+			continue
+		}
+		restored = append(restored, task)
+	}
+
+	if len(restored) != len(pending) {
+		if err := j.rewrite(restored); err != nil {
+			j.close()
+			return nil, nil, err
+		}
+	}
+	q.journal = j
+
+	return q, restored, nil
+}
+
 func changeKey(project string, changeNumber int) string {
 	return fmt.Sprintf("%s-%d", project, changeNumber)
 }
 
 // Push adds a task to the queue.
 // Returns typed errors for duplicate, full, or obsolete tasks.
+//
+// On-demand tasks carry their own ID, so they are queued even while an
+// automatic review of the same patchset is pending, and in lazy mode they
+// may re-review the latest patchset.
 func (q *Queue) Push(task Task) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
 	// Duplicate detection by unique task ID.
-	if q.inflight[task.ID] {
+	if _, ok := q.inflight[task.ID]; ok {
 		return fmt.Errorf("%w: %s", ErrDuplicateTask, task.ID)
 	}
 
 	if q.lazyMode {
 		key := changeKey(task.Project, task.ChangeNumber)
-		if latestPatchset, ok := q.latestByChange[key]; ok && task.PatchsetNumber <= latestPatchset {
+		if latestPatchset, ok := q.latestByChange[key]; ok && (task.PatchsetNumber < latestPatchset ||
+			task.PatchsetNumber == latestPatchset && !task.OnDemand) {
 			return fmt.Errorf("%w: %s (incoming=%d, latest=%d)", ErrObsoleteTask, key, task.PatchsetNumber, latestPatchset)
 		}
 		q.latestByChange[key] = task.PatchsetNumber
 	}
 
-	select {
-	case q.tasks <- task:
-		q.inflight[task.ID] = true
-		return nil
-	default:
+	// Every send to the channel holds q.mu, so a free slot checked here is
+	// still free below.
+	if len(q.tasks) >= cap(q.tasks) {
 		return ErrQueueFull
 	}
+
+	if q.journal != nil {
+		if err := q.journal.push(task); err != nil {
+			return err
+		}
+	}
+
+	q.tasks <- task
+	q.addInflightLocked(task)
+
+	// A new patchset reopens a change seen as abandoned, and in lazy mode
+	// makes reviews of older patchsets pointless.
+	delete(q.closedChanges, changeKey(task.Project, task.ChangeNumber))
+	if q.lazyMode {
+		q.cancelRunningLocked(task.Project, task.ChangeNumber, task.PatchsetNumber, ErrSuperseded)
+	}
+
+	return nil
+}
+
+// Start marks a popped task as running and returns the context to run it
+// with. The context is cancelled with cause ErrSuperseded when a newer
+// patchset of the change is pushed in lazy mode, or ErrChangeClosed when
+// CancelChange is called for the change. MarkDone or Retry releases it.
+func (q *Queue) Start(ctx context.Context, task Task) context.Context {
+	taskCtx, cancel := context.WithCancelCause(ctx)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running[task.ID] = runningTask{task: task, cancel: cancel}
+
+	return taskCtx
+}
+
+// CancelChange cancels running tasks of a change that was abandoned or
+// merged and drops its queued tasks. It returns the number of cancelled
+// running tasks.
+func (q *Queue) CancelChange(project string, changeNumber int) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// The change is only remembered until its tasks in flight drain
+	if key := changeKey(project, changeNumber); q.pending[key] > 0 {
+		q.closedChanges[key] = true
+	}
+	return q.cancelRunningLocked(project, changeNumber, 0, ErrChangeClosed)
+}
+
+// cancelRunningLocked cancels running tasks of the change with a patchset
+// older than before (all patchsets when before is 0).
+func (q *Queue) cancelRunningLocked(project string, changeNumber, before int, cause error) int {
+	cancelled := 0
+	for _, r := range q.running {
+		if r.task.Project != project || r.task.ChangeNumber != changeNumber {
+			continue
+		}
+		if before > 0 && r.task.PatchsetNumber >= before {
+			continue
+		}
+		r.cancel(cause)
+		cancelled++
+	}
+	return cancelled
+}
+
+// releaseLocked forgets the running state of a task
+func (q *Queue) releaseLocked(taskID string) {
+	if r, ok := q.running[taskID]; ok {
+		r.cancel(nil)
+		delete(q.running, taskID)
+	}
 }
 
 // Pop retrieves a task from the queue.
@@ -88,18 +226,21 @@ func (q *Queue) Pop(ctx context.Context) (Task, error) {
 	for {
 		select {
 		case task := <-q.tasks:
-			if q.lazyMode {
-				q.mu.Lock()
-				key := changeKey(task.Project, task.ChangeNumber)
-				latestPatchset := q.latestByChange[key]
-				if task.PatchsetNumber < latestPatchset {
-					// This task has been superseded by a newer patchset for same change.
-					delete(q.inflight, task.ID)
-					q.mu.Unlock()
-					continue
-				}
+			q.mu.Lock()
+			key := changeKey(task.Project, task.ChangeNumber)
+			if q.closedChanges[key] {
+				// The change was abandoned or merged while queued.
+				q.markDoneLocked(task.ID)
 				q.mu.Unlock()
+				continue
 			}
+			if q.lazyMode && task.PatchsetNumber < q.latestByChange[key] {
+				// This task has been superseded by a newer patchset for same change.
+				q.markDoneLocked(task.ID)
+				q.mu.Unlock()
+				continue
+			}
+			q.mu.Unlock()
 
 			return task, nil
 		case <-ctx.Done():
@@ -112,7 +253,94 @@ func (q *Queue) Pop(ctx context.Context) (Task, error) {
 func (q *Queue) MarkDone(taskID string) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	delete(q.inflight, taskID)
+	q.markDoneLocked(taskID)
+}
+
+func (q *Queue) markDoneLocked(taskID string) {
+	q.releaseLocked(taskID)
+	if key, ok := q.inflight[taskID]; ok {
+		delete(q.inflight, taskID)
+		if q.pending[key]--; q.pending[key] <= 0 {
+			delete(q.pending, key)
+			delete(q.closedChanges, key)
+		}
+	}
+
+	if q.journal == nil {
+		return
+	}
+
+	var err error
+	if len(q.inflight) == 0 {
+		// Queue drained: start the journal over.
+		err = q.journal.rewrite(nil)
+	} else {
+		err = q.journal.done(taskID)
+	}
+	if err != nil {
+		logger.Get().Warnf("Failed to record completion of %s in queue journal: %v", taskID, err)
+	}
+}
+
+// Retry re-enqueues an in-flight task after delay. The task stays in flight
+// while it waits, so pushes of the same task ID are still rejected as
+// duplicates. A durable queue records the updated task so the retry
+// survives a restart (it then runs without waiting).
+func (q *Queue) Retry(task Task, delay time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.journal != nil {
+		if err := q.journal.push(task); err != nil {
+			return err
+		}
+	}
+	q.releaseLocked(task.ID)
+	if _, ok := q.inflight[task.ID]; !ok {
+		q.addInflightLocked(task)
+	}
+	q.schedule(task, delay)
+
+	return nil
+}
+
+// addInflightLocked tracks task as in flight until markDoneLocked
+func (q *Queue) addInflightLocked(task Task) {
+	key := changeKey(task.Project, task.ChangeNumber)
+	q.inflight[task.ID] = key
+	q.pending[key]++
+}
+
+// schedule sends task to the channel after delay, retrying shortly if the
+// queue is full at that time.
+func (q *Queue) schedule(task Task, delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		if _, ok := q.inflight[task.ID]; q.closed || !ok {
+			return
+		}
+
+		select {
+		case q.tasks <- task:
+		default:
+			q.schedule(task, time.Second)
+		}
+	})
+}
+
+// Close releases the journal of a durable queue. Pending tasks stay in the
+// journal and are restored by the next NewDurableQueue.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	if q.journal == nil {
+		return nil
+	}
+	return q.journal.close()
 }
 
 // Size returns the current number of tasks in the queue.
@@ -3,6 +3,9 @@ package queue
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -39,6 +42,28 @@ func TestLazyModeRejectsOlderOrEqualPatchset(t *testing.T) {
 	}
 }
 
+func TestLazyModeAcceptsOnDemandReviewOfLatestPatchset(t *testing.T) {
+	q := NewQueue(10, QueueConfig{LazyMode: true})
+
+	if err := q.Push(Task{ID: "proj-100-2", Project: "proj", ChangeNumber: 100, PatchsetNumber: 2}); err != nil {
+		t.Fatalf("push patchset 2 failed: %v", err)
+	}
+
+	onDemand := Task{ID: "proj-100-2-ondemand-1", Project: "proj", ChangeNumber: 100, PatchsetNumber: 2, OnDemand: true}
+	if err := q.Push(onDemand); err != nil {
+		t.Fatalf("expected on-demand review of latest patchset to be queued, got: %v", err)
+	}
+
+	err := q.Push(Task{ID: "proj-100-1-ondemand-1", Project: "proj", ChangeNumber: 100, PatchsetNumber: 1, OnDemand: true})
+	if !errors.Is(err, ErrObsoleteTask) {
+		t.Fatalf("expected ErrObsoleteTask for on-demand review of older patchset, got: %v", err)
+	}
+
+	if q.Size() != 2 {
+		t.Fatalf("expected 2 queued tasks, got %d", q.Size())
+	}
+}
+
 func TestLazyModePopSkipsSupersededQueuedTask(t *testing.T) {
 	q := NewQueue(10, QueueConfig{LazyMode: true})
 
@@ -90,3 +115,287 @@ func TestNonLazyModePopsInOrder(t *testing.T) {
 		t.Fatalf("expected second patchset 2, got: %d", second.PatchsetNumber)
 	}
 }
+
+func TestDurableQueueRestoresUnfinishedTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue", "journal.jsonl")
+
+	q, restored, err := NewDurableQueue(10, QueueConfig{}, path)
+	if err != nil {
+		t.Fatalf("open durable queue failed: %v", err)
+	}
+	if len(restored) != 0 {
+		t.Fatalf("expected empty queue on first open, got %d tasks", len(restored))
+	}
+
+	for ps := 1; ps <= 3; ps++ {
+		task := Task{ID: fmt.Sprintf("proj-100-%d", ps), Project: "proj", ChangeNumber: 100, PatchsetNumber: ps}
+		if err := q.Push(task); err != nil {
+			t.Fatalf("push patchset %d failed: %v", ps, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// First task completes, second is in flight when the process stops.
+	first, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("pop failed: %v", err)
+	}
+	q.MarkDone(first.ID)
+	if _, err := q.Pop(ctx); err != nil {
+		t.Fatalf("pop failed: %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	q, restored, err = NewDurableQueue(10, QueueConfig{}, path)
+	if err != nil {
+		t.Fatalf("reopen durable queue failed: %v", err)
+	}
+	defer q.Close()
+
+	if len(restored) != 2 || restored[0].PatchsetNumber != 2 || restored[1].PatchsetNumber != 3 {
+		t.Fatalf("expected patchsets 2 and 3 restored in order, got: %+v", restored)
+	}
+	if q.Size() != 2 {
+		t.Fatalf("expected 2 queued tasks, got %d", q.Size())
+	}
+}
+
+func TestDurableQueueLazyModeSupersedesRestoredTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	q, _, err := NewDurableQueue(10, QueueConfig{}, path)
+	if err != nil {
+		t.Fatalf("open durable queue failed: %v", err)
+	}
+	for ps := 1; ps <= 2; ps++ {
+		task := Task{ID: fmt.Sprintf("proj-100-%d", ps), Project: "proj", ChangeNumber: 100, PatchsetNumber: ps}
+		if err := q.Push(task); err != nil {
+			t.Fatalf("push patchset %d failed: %v", ps, err)
+		}
+	}
+	q.Close()
+
+	q, _, err = NewDurableQueue(10, QueueConfig{LazyMode: true}, path)
+	if err != nil {
+		t.Fatalf("reopen durable queue failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	task, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("pop failed: %v", err)
+	}
+	if task.PatchsetNumber != 2 {
+		t.Fatalf("expected latest patchset (2), got: %d", task.PatchsetNumber)
+	}
+	q.MarkDone(task.ID)
+	q.Close()
+
+	_, restored, err := NewDurableQueue(10, QueueConfig{LazyMode: true}, path)
+	if err != nil {
+		t.Fatalf("reopen durable queue failed: %v", err)
+	}
+	if len(restored) != 0 {
+		t.Fatalf("expected drained journal, got: %+v", restored)
+	}
+}
+
+func TestDurableQueueIgnoresTruncatedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	content := `{"op":"push","task":{"id":"proj-1-1","project":"proj","change_number":1,"patchset_number":1}}
+{"op":"push","task":{"id":"proj-2-1","pro`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write journal failed: %v", err)
+	}
+
+	q, restored, err := NewDurableQueue(10, QueueConfig{}, path)
+	if err != nil {
+		t.Fatalf("open durable queue failed: %v", err)
+	}
+	defer q.Close()
+
+	if len(restored) != 1 || restored[0].ID != "proj-1-1" {
+		t.Fatalf("expected only the complete record restored, got: %+v", restored)
+	}
+}
+
+func TestRetryKeepsTaskInFlightUntilRequeued(t *testing.T) {
+	q := NewQueue(10, QueueConfig{})
+	task := Task{ID: "proj-100-1", Project: "proj", ChangeNumber: 100, PatchsetNumber: 1}
+
+	if err := q.Push(task); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	popped, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("pop failed: %v", err)
+	}
+
+	popped.Attempt = 1
+	if err := q.Retry(popped, 50*time.Millisecond); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+
+	if err := q.Push(task); !errors.Is(err, ErrDuplicateTask) {
+		t.Fatalf("expected ErrDuplicateTask while retry is pending, got: %v", err)
+	}
+
+	retried, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("pop of retried task failed: %v", err)
+	}
+	if retried.ID != task.ID || retried.Attempt != 1 {
+		t.Fatalf("expected retried task with attempt 1, got: %+v", retried)
+	}
+}
+
+func TestDeadLetterStoreRequeue(t *testing.T) {
+	store := NewDeadLetterStore(t.TempDir())
+	task := Task{ID: "platform/app-100-1", Project: "platform/app", ChangeNumber: 100, PatchsetNumber: 1,
+		Attempt: 3, Errors: []string{"a", "b", "c"}}
+
+	if err := store.Add(DeadLetter{Task: task, ErrorClass: "git", FailedAt: time.Now()}); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Task.ID != task.ID || len(entries[0].Task.Errors) != 3 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	if _, err := store.Get("missing"); !errors.Is(err, ErrDeadLetterNotFound) {
+		t.Fatalf("expected ErrDeadLetterNotFound, got: %v", err)
+	}
+
+	if _, err := store.Requeue(task.ID); err != nil {
+		t.Fatalf("requeue failed: %v", err)
+	}
+	if entries, _ := store.List(); len(entries) != 0 {
+		t.Fatalf("expected requeued task to leave the dead-letter list, got: %+v", entries)
+	}
+
+	tasks, err := store.TakeRequeued()
+	if err != nil {
+		t.Fatalf("take requeued failed: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID || tasks[0].Attempt != 0 || tasks[0].Errors != nil {
+		t.Fatalf("expected requeued task with reset attempts, got: %+v", tasks)
+	}
+
+	if tasks, _ := store.TakeRequeued(); len(tasks) != 0 {
+		t.Fatalf("expected requeued tasks to be taken once, got: %+v", tasks)
+	}
+}
+
+func TestLazyModePushCancelsRunningOlderPatchset(t *testing.T) {
+	q := NewQueue(10, QueueConfig{LazyMode: true})
+
+	if err := q.Push(Task{ID: "proj-100-3", Project: "proj", ChangeNumber: 100, PatchsetNumber: 3}); err != nil {
+		t.Fatalf("push patchset 3 failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	task, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("pop failed: %v", err)
+	}
+	taskCtx := q.Start(ctx, task)
+
+	// Another change must not affect the running task
+	if err := q.Push(Task{ID: "proj-200-1", Project: "proj", ChangeNumber: 200, PatchsetNumber: 1}); err != nil {
+		t.Fatalf("push other change failed: %v", err)
+	}
+	if taskCtx.Err() != nil {
+		t.Fatalf("running task cancelled by unrelated push")
+	}
+
+	if err := q.Push(Task{ID: "proj-100-4", Project: "proj", ChangeNumber: 100, PatchsetNumber: 4}); err != nil {
+		t.Fatalf("push patchset 4 failed: %v", err)
+	}
+	if !errors.Is(context.Cause(taskCtx), ErrSuperseded) {
+		t.Fatalf("expected running task cancelled with ErrSuperseded, got: %v", context.Cause(taskCtx))
+	}
+}
+
+func TestCancelChangeStopsRunningAndDropsQueued(t *testing.T) {
+	q := NewQueue(10, QueueConfig{})
+
+	for _, task := range []Task{
+		{ID: "proj-100-1", Project: "proj", ChangeNumber: 100, PatchsetNumber: 1},
+		{ID: "proj-100-2", Project: "proj", ChangeNumber: 100, PatchsetNumber: 2},
+		{ID: "proj-200-1", Project: "proj", ChangeNumber: 200, PatchsetNumber: 1},
+	} {
+		if err := q.Push(task); err != nil {
+			t.Fatalf("push %s failed: %v", task.ID, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	running, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("pop failed: %v", err)
+	}
+	taskCtx := q.Start(ctx, running)
+
+	if n := q.CancelChange("proj", 100); n != 1 {
+		t.Fatalf("expected 1 cancelled running task, got %d", n)
+	}
+	if !errors.Is(context.Cause(taskCtx), ErrChangeClosed) {
+		t.Fatalf("expected ErrChangeClosed, got: %v", context.Cause(taskCtx))
+	}
+	q.MarkDone(running.ID)
+
+	next, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("pop failed: %v", err)
+	}
+	if next.ChangeNumber != 200 {
+		t.Fatalf("expected queued task of closed change to be dropped, got: %+v", next)
+	}
+	q.MarkDone(next.ID)
+
+	if q.InFlight() != 0 {
+		t.Fatalf("expected nothing in flight, got %d", q.InFlight())
+	}
+	if len(q.closedChanges) != 0 || len(q.pending) != 0 {
+		t.Fatalf("expected closed change forgotten once drained, got closed %v pending %v", q.closedChanges, q.pending)
+	}
+}
+
+func TestCancelChangeWithoutTasksIsNotRemembered(t *testing.T) {
+	q := NewQueue(10, QueueConfig{})
+
+	for i := 1; i <= 100; i++ {
+		q.CancelChange("proj", i)
+	}
+	if len(q.closedChanges) != 0 {
+		t.Fatalf("expected no closed changes without tasks, got %d", len(q.closedChanges))
+	}
+
+	// A later patchset of a change closed without tasks is still reviewed
+	if err := q.Push(Task{ID: "proj-1-2", Project: "proj", ChangeNumber: 1, PatchsetNumber: 2}); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if task, err := q.Pop(ctx); err != nil || task.ID != "proj-1-2" {
+		t.Fatalf("expected proj-1-2, got %+v, %v", task, err)
+	}
+}
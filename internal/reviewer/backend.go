@@ -0,0 +1,140 @@
+package reviewer
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
+)
+
+// Backend adapts an AI CLI to the review executor.
+//
+// A Backend instance is created per review run, so implementations may keep
+// per-run state (counters, temp files) on the receiver. The executor owns the
+// process lifecycle: it calls BuildArgs, starts Executable, feeds every stdout
+// line to ParseStreamLine, then calls FinalMessage on success or
+// ClassifyError on failure.
+type Backend interface {
+	// Name is the backend identifier used in logs and config (review.cli).
+	Name() string
+	// Executable is the program to run.
+	Executable() string
+	// VersionArgs are passed to Executable by preflight checks; nil skips the check.
+	VersionArgs() []string
+	// BuildArgs returns the command arguments for the given run.
+	// It may set run.Stdin and allocate temp files via run.TempFile.
+	BuildArgs(run *BackendRun) ([]string, error)
+	// ParseStreamLine consumes one line of the CLI's stdout.
+	ParseStreamLine(run *BackendRun, line string)
+	// FinalMessage returns the review output after the CLI exited successfully.
+	FinalMessage(run *BackendRun) (string, error)
+	// ClassifyError maps a failed CLI exit into an error, wrapping
+	// ErrRateLimited when the failure was caused by rate limiting.
+	ClassifyError(run *BackendRun, err error) error
+}
+
+// BackendFactory creates a Backend for a single review run.
+type BackendFactory func(cfg *config.Config, log *logger.Logger) (Backend, error)
+
+// BackendRun carries the I/O of one backend invocation.
+type BackendRun struct {
+	Prompt  string
+	WorkDir string
+	Stdin   string          // Written to the CLI's stdin when non-empty
+	Stdout  strings.Builder // Raw stdout, filled by the executor
+	Stderr  string          // Raw stderr, available after the CLI exited
+
+	tempFiles []string
+}
+
+// TempFile creates an empty temp file that is removed when the run ends.
+func (r *BackendRun) TempFile(pattern string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	r.tempFiles = append(r.tempFiles, f.Name())
+	return f.Name(), nil
+}
+
+func (r *BackendRun) cleanup() {
+	for _, path := range r.tempFiles {
+		os.Remove(path)
+	}
+	r.tempFiles = nil
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = make(map[string]BackendFactory)
+)
+
+func init() {
+	RegisterBackend("claude", newClaudeBackend)
+	RegisterBackend("codex", newCodexBackend)
+	RegisterBackend("command", newCommandBackend)
+}
+
+// RegisterBackend makes a backend available under the given review.cli name.
+// Registering an existing name replaces it.
+func RegisterBackend(name string, factory BackendFactory) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	backendsMu.Lock()
+	backends[name] = factory
+	backendsMu.Unlock()
+
+	config.RegisterReviewBackend(name)
+}
+
+// NewBackend creates a backend by its registered name.
+func NewBackend(name string, cfg *config.Config) (Backend, error) {
+	backendsMu.RLock()
+	factory, ok := backends[strings.ToLower(strings.TrimSpace(name))]
+	backendsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown review backend %q (available: %s)", name, strings.Join(BackendNames(), ", "))
+	}
+	return factory(cfg, logger.Get())
+}
+
+// BackendNames returns the sorted names of all registered backends.
+func BackendNames() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// classifyByOutput is the shared ClassifyError logic: rate-limit markers in
+// any output stream become ErrRateLimited, everything else reports which
+// streams had content.
+func classifyByOutput(name string, run *BackendRun, err error) error {
+	stderrText := strings.TrimSpace(run.Stderr)
+	stdoutText := strings.TrimSpace(run.Stdout.String())
+
+	if isRateLimitedErrorText(stderrText) || isRateLimitedErrorText(stdoutText) || isRateLimitedErrorText(err.Error()) {
+		return fmt.Errorf("%w: %s execution failed: %v", ErrRateLimited, name, err)
+	}
+	if len(stderrText) > 0 {
+		return fmt.Errorf("%s execution failed: %w (stderr length: %d)", name, err, len(stderrText))
+	}
+	if len(stdoutText) > 0 {
+		return fmt.Errorf("%s execution failed: %w (stdout length: %d)", name, err, len(stdoutText))
+	}
+	return fmt.Errorf("%s execution failed: %w (no output)", name, err)
+}
@@ -0,0 +1,120 @@
+package reviewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
+)
+
+// claudeBackend runs the Claude CLI with stream-json output
+type claudeBackend struct {
+	cfg *config.Config
+	log *logger.Logger
+
+	assistantText strings.Builder
+	toolCallCount int
+	bashCallCount int
+}
+
+func newClaudeBackend(cfg *config.Config, log *logger.Logger) (Backend, error) {
+	return &claudeBackend{cfg: cfg, log: log}, nil
+}
+
+func (b *claudeBackend) Name() string          { return "claude" }
+func (b *claudeBackend) Executable() string    { return "claude" }
+func (b *claudeBackend) VersionArgs() []string { return []string{"--version"} }
+
+func (b *claudeBackend) BuildArgs(run *BackendRun) ([]string, error) {
+	return b.buildArgs(run.Prompt), nil
+}
+
+func (b *claudeBackend) buildArgs(prompt string) []string {
+	args := []string{
+		"-p", prompt,
+		"--output-format", "stream-json",
+		"--include-partial-messages",
+		"--verbose",
+	}
+
+	if b.cfg.Review.ClaudeSkipPermissionsCheck {
+		b.log.Warnf("Claude permission checks are disabled via --dangerously-skip-permissions")
+		args = append(args, "--dangerously-skip-permissions")
+	}
+
+	return args
+}
+
+func (b *claudeBackend) ParseStreamLine(run *BackendRun, line string) {
+	// Try to parse as JSON event
+	var event StreamEvent
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		// Not a JSON line, skip
+		return
+	}
+
+	// Only stream_event wrappers carry content
+	if event.Type != "stream_event" || len(event.Event) == 0 {
+		return
+	}
+
+	// Parse inner event
+	var innerEvent StreamEventInner
+	if err := json.Unmarshal(event.Event, &innerEvent); err != nil {
+		return
+	}
+
+	// Handle different event types
+	switch innerEvent.Type {
+	case "content_block_start":
+		// Check if it's a tool use
+		if innerEvent.ContentBlock.Type == "tool_use" {
+			b.toolCallCount++
+			if innerEvent.ContentBlock.Name == "Bash" {
+				b.bashCallCount++
+				// Try to parse input
+				var toolInput ToolInput
+				if err := json.Unmarshal(innerEvent.ContentBlock.Input, &toolInput); err == nil {
+					b.log.Debugf("[Tool #%d] Bash: %s", b.toolCallCount, truncate(toolInput.Command, 100))
+				}
+			} else {
+				b.log.Debugf("[Tool #%d] %s (ID: %s)", b.toolCallCount, innerEvent.ContentBlock.Name, innerEvent.ContentBlock.ID)
+			}
+		}
+
+	case "content_block_delta":
+		// Check if it's text delta
+		var deltaData struct {
+			Type string `json:"type"`
+			Text string `json:"text,omitempty"`
+		}
+		if err := json.Unmarshal(innerEvent.Delta, &deltaData); err == nil {
+			if deltaData.Type == "text_delta" {
+				b.assistantText.WriteString(deltaData.Text)
+			}
+		}
+
+	case "message_stop":
+		// Message completed
+		b.log.Debugf("Claude message completed")
+	}
+}
+
+func (b *claudeBackend) FinalMessage(run *BackendRun) (string, error) {
+	b.log.Infof("Claude execution completed: %d tool calls (%d Bash)", b.toolCallCount, b.bashCallCount)
+	return b.assistantText.String(), nil
+}
+
+func (b *claudeBackend) ClassifyError(run *BackendRun, err error) error {
+	// stdout is the model's own stream, so only stderr is inspected for rate limits.
+	stderrText := strings.TrimSpace(run.Stderr)
+	if isRateLimitedErrorText(stderrText) || isRateLimitedErrorText(err.Error()) {
+		return fmt.Errorf("%w: claude execution failed: %v", ErrRateLimited, err)
+	}
+	if len(stderrText) > 0 {
+		return fmt.Errorf("claude execution failed: %w (stderr length: %d)", err, len(stderrText))
+	}
+	return fmt.Errorf("claude execution failed: %w (no stderr output)", err)
+}
@@ -0,0 +1,146 @@
+package reviewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
+)
+
+// codexBackend runs `codex exec --json` and reads the final message from
+// the --output-last-message file
+type codexBackend struct {
+	cfg *config.Config
+	log *logger.Logger
+
+	outputPath    string
+	toolCallCount int
+	eventCount    int
+}
+
+func newCodexBackend(cfg *config.Config, log *logger.Logger) (Backend, error) {
+	return &codexBackend{cfg: cfg, log: log}, nil
+}
+
+func (b *codexBackend) Name() string          { return "codex" }
+func (b *codexBackend) Executable() string    { return "codex" }
+func (b *codexBackend) VersionArgs() []string { return []string{"--version"} }
+
+func (b *codexBackend) BuildArgs(run *BackendRun) ([]string, error) {
+	outputPath, err := run.TempFile("codex-review-*-last-message.txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create codex output file: %w", err)
+	}
+	b.outputPath = outputPath
+
+	return b.buildArgs(run.Prompt, outputPath), nil
+}
+
+func (b *codexBackend) buildArgs(prompt string, outputPath string) []string {
+	args := []string{
+		"exec",
+		"--json",
+		"--skip-git-repo-check",
+		"--color", "never",
+		"--output-last-message", outputPath,
+	}
+
+	if b.cfg.Review.ClaudeSkipPermissionsCheck {
+		b.log.Warnf("Codex approvals and sandbox are disabled via --dangerously-bypass-approvals-and-sandbox")
+		args = append(args, "--dangerously-bypass-approvals-and-sandbox")
+	} else {
+		args = append(args, "--full-auto")
+	}
+
+	args = append(args, prompt)
+
+	return args
+}
+
+func (b *codexBackend) ParseStreamLine(run *BackendRun, line string) {
+	eventType, command := parseCodexEventLine(line)
+	if eventType == "" {
+		return
+	}
+	b.eventCount++
+
+	if command != "" {
+		b.toolCallCount++
+		b.log.Debugf("[Tool #%d] Bash: %s", b.toolCallCount, truncate(command, 100))
+		return
+	}
+
+	if isCodexToolRelatedEvent(eventType) {
+		b.toolCallCount++
+		b.log.Debugf("[Tool #%d] Codex event: %s", b.toolCallCount, eventType)
+	}
+}
+
+func (b *codexBackend) FinalMessage(run *BackendRun) (string, error) {
+	b.log.Infof("Codex execution completed: %d events (%d tool-related)", b.eventCount, b.toolCallCount)
+
+	finalOutput, err := os.ReadFile(b.outputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read codex output file: %w", err)
+	}
+
+	text := strings.TrimSpace(string(finalOutput))
+	if text == "" {
+		text = strings.TrimSpace(run.Stdout.String())
+	}
+
+	return text, nil
+}
+
+func (b *codexBackend) ClassifyError(run *BackendRun, err error) error {
+	return classifyByOutput("codex", run, err)
+}
+
+func parseCodexEventLine(line string) (string, string) {
+	var payload any
+	if err := json.Unmarshal([]byte(line), &payload); err != nil {
+		return "", ""
+	}
+
+	m, ok := payload.(map[string]any)
+	if !ok {
+		return "", ""
+	}
+
+	eventType, _ := m["type"].(string)
+	return eventType, findFirstCommandString(payload)
+}
+
+func isCodexToolRelatedEvent(eventType string) bool {
+	eventType = strings.ToLower(eventType)
+	return strings.Contains(eventType, "exec") ||
+		strings.Contains(eventType, "tool") ||
+		strings.Contains(eventType, "command")
+}
+
+func findFirstCommandString(v any) string {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, val := range x {
+			key := strings.ToLower(k)
+			if key == "command" || key == "cmd" {
+				if s, ok := val.(string); ok {
+					return s
+				}
+			}
+			if nested := findFirstCommandString(val); nested != "" {
+				return nested
+			}
+		}
+	case []any:
+		for _, val := range x {
+			if nested := findFirstCommandString(val); nested != "" {
+				return nested
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package reviewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
+)
+
+// Placeholders expanded in review.command.argv
+const (
+	placeholderPrompt     = "{{prompt}}"
+	placeholderPromptFile = "{{prompt_file}}"
+	placeholderOutputFile = "{{output_file}}"
+	placeholderWorkDir    = "{{workdir}}"
+)
+
+// commandBackend runs an arbitrary CLI described entirely by review.command
+type commandBackend struct {
+	cfg config.CommandBackendConfig
+	log *logger.Logger
+
+	outputPath string
+	text       strings.Builder
+	lineCount  int
+}
+
+func newCommandBackend(cfg *config.Config, log *logger.Logger) (Backend, error) {
+	if len(cfg.Review.Command.Argv) == 0 {
+		return nil, fmt.Errorf("review.command.argv is required for the command backend")
+	}
+	return &commandBackend{cfg: cfg.Review.Command, log: log}, nil
+}
+
+func (b *commandBackend) Name() string          { return "command" }
+func (b *commandBackend) Executable() string    { return b.cfg.Argv[0] }
+func (b *commandBackend) VersionArgs() []string { return b.cfg.VersionArgs }
+
+func (b *commandBackend) BuildArgs(run *BackendRun) ([]string, error) {
+	// Old/new pairs for strings.NewReplacer
+	replacements := []string{
+		placeholderPrompt, run.Prompt,
+		placeholderWorkDir, run.WorkDir,
+	}
+
+	if b.usesPlaceholder(placeholderPromptFile) {
+		promptPath, err := run.TempFile("command-review-*-prompt.md")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create prompt file: %w", err)
+		}
+		if err := os.WriteFile(promptPath, []byte(run.Prompt), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write prompt file: %w", err)
+		}
+		replacements = append(replacements, placeholderPromptFile, promptPath)
+	}
+
+	if b.cfg.OutputFile || b.usesPlaceholder(placeholderOutputFile) {
+		outputPath, err := run.TempFile("command-review-*-output.txt")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output file: %w", err)
+		}
+		b.outputPath = outputPath
+		replacements = append(replacements, placeholderOutputFile, outputPath)
+	}
+
+	if b.cfg.Stdin == "prompt" {
+		run.Stdin = run.Prompt
+	}
+
+	// A single pass leaves placeholders inside substituted values, such as
+	// a prompt quoting "{{workdir}}", untouched
+	replacer := strings.NewReplacer(replacements...)
+	args := make([]string, 0, len(b.cfg.Argv)-1)
+	for _, arg := range b.cfg.Argv[1:] {
+		args = append(args, replacer.Replace(arg))
+	}
+
+	return args, nil
+}
+
+func (b *commandBackend) usesPlaceholder(placeholder string) bool {
+	for _, arg := range b.cfg.Argv {
+		if strings.Contains(arg, placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *commandBackend) ParseStreamLine(run *BackendRun, line string) {
+	b.lineCount++
+
+	if b.cfg.Stdout != "jsonl" {
+		return
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(line), &payload); err != nil {
+		return
+	}
+
+	field := b.cfg.TextField
+	if field == "" {
+		field = "text"
+	}
+	if text, ok := payload[field].(string); ok {
+		b.text.WriteString(text)
+	}
+	if command := findFirstCommandString(payload); command != "" {
+		b.log.Debugf("[Tool] %s: %s", b.Name(), truncate(command, 100))
+	}
+}
+
+func (b *commandBackend) FinalMessage(run *BackendRun) (string, error) {
+	b.log.Infof("%s execution completed: %d output lines", b.Name(), b.lineCount)
+
+	if b.outputPath != "" {
+		output, err := os.ReadFile(b.outputPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s output file: %w", b.Name(), err)
+		}
+		if text := strings.TrimSpace(string(output)); text != "" {
+			return text, nil
+		}
+	}
+
+	if b.cfg.Stdout == "jsonl" {
+		return strings.TrimSpace(b.text.String()), nil
+	}
+	return strings.TrimSpace(run.Stdout.String()), nil
+}
+
+func (b *commandBackend) ClassifyError(run *BackendRun, err error) error {
+	return classifyByOutput(b.Name(), run, err)
+}
@@ -0,0 +1,157 @@
+package reviewer
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+)
+
+func newCommandTestExecutor(t *testing.T, command config.CommandBackendConfig) *ReviewExecutor {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	return NewReviewExecutor(t.TempDir(), &config.Config{
+		Review: config.ReviewConfig{CLI: "command", Command: command},
+	})
+}
+
+func TestCommandBackend_ExpandsPlaceholders(t *testing.T) {
+	exec := NewReviewExecutor("/work", &config.Config{
+		Review: config.ReviewConfig{
+			CLI: "command",
+			Command: config.CommandBackendConfig{
+				Argv: []string{"local-llm", "--cwd={{workdir}}", "--prompt", "{{prompt}}", "--out", "{{output_file}}"},
+			},
+		},
+	})
+
+	backend := newTestBackend(t, exec)
+	run := &BackendRun{Prompt: "review this", WorkDir: "/work"}
+	defer run.cleanup()
+
+	args, err := backend.BuildArgs(run)
+	if err != nil {
+		t.Fatalf("BuildArgs failed: %v", err)
+	}
+
+	if backend.Executable() != "local-llm" {
+		t.Errorf("Expected executable local-llm, got %s", backend.Executable())
+	}
+	if args[0] != "--cwd=/work" || args[2] != "review this" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+	if args[4] == "{{output_file}}" || args[4] == "" {
+		t.Errorf("Expected output file placeholder to be expanded, got %q", args[4])
+	}
+	if run.Stdin != "" {
+		t.Errorf("Expected no stdin by default, got %q", run.Stdin)
+	}
+}
+
+func TestCommandBackend_DoesNotExpandPlaceholdersInValues(t *testing.T) {
+	exec := NewReviewExecutor("/work", &config.Config{
+		Review: config.ReviewConfig{
+			CLI: "command",
+			Command: config.CommandBackendConfig{
+				Argv: []string{"local-llm", "{{prompt}} in {{workdir}}"},
+			},
+		},
+	})
+
+	backend := newTestBackend(t, exec)
+	run := &BackendRun{Prompt: "explain {{workdir}} and {{output_file}}", WorkDir: "/work"}
+	defer run.cleanup()
+
+	args, err := backend.BuildArgs(run)
+	if err != nil {
+		t.Fatalf("BuildArgs failed: %v", err)
+	}
+
+	want := "explain {{workdir}} and {{output_file}} in /work"
+	if len(args) != 1 || args[0] != want {
+		t.Errorf("Expected args [%q], got %q", want, args)
+	}
+}
+
+func TestCommandBackend_StdinPromptTextStdout(t *testing.T) {
+	exec := newCommandTestExecutor(t, config.CommandBackendConfig{
+		Argv:  []string{"sh", "-c", `echo "reviewed: $(cat)"`},
+		Stdin: "prompt",
+	})
+
+	output, err := exec.ExecuteReview(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("ExecuteReview failed: %v", err)
+	}
+	if output != "reviewed: hello" {
+		t.Errorf("Expected 'reviewed: hello', got %q", output)
+	}
+}
+
+func TestCommandBackend_JSONLStdout(t *testing.T) {
+	exec := newCommandTestExecutor(t, config.CommandBackendConfig{
+		Argv:      []string{"sh", "-c", `printf '{"delta":"LG"}\nnot json\n{"delta":"TM"}\n'`},
+		Stdout:    "jsonl",
+		TextField: "delta",
+	})
+
+	output, err := exec.ExecuteReview(context.Background(), "ignored")
+	if err != nil {
+		t.Fatalf("ExecuteReview failed: %v", err)
+	}
+	if output != "LGTM" {
+		t.Errorf("Expected LGTM, got %q", output)
+	}
+}
+
+func TestCommandBackend_OutputFileAndPromptFile(t *testing.T) {
+	exec := newCommandTestExecutor(t, config.CommandBackendConfig{
+		Argv: []string{"sh", "-c", `echo progress; tr a-z A-Z < "$0" > "$1"`, "{{prompt_file}}", "{{output_file}}"},
+	})
+
+	output, err := exec.ExecuteReview(context.Background(), "final review")
+	if err != nil {
+		t.Fatalf("ExecuteReview failed: %v", err)
+	}
+	if output != "FINAL REVIEW" {
+		t.Errorf("Expected output file content, got %q", output)
+	}
+}
+
+func TestCommandBackend_RateLimitedFailure(t *testing.T) {
+	exec := newCommandTestExecutor(t, config.CommandBackendConfig{
+		Argv: []string{"sh", "-c", `echo "HTTP 429 Too Many Requests" >&2; exit 1`},
+	})
+
+	_, err := exec.ExecuteReview(context.Background(), "prompt")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestCommandBackend_Timeout(t *testing.T) {
+	exec := newCommandTestExecutor(t, config.CommandBackendConfig{
+		Argv: []string{"sh", "-c", "sleep 5"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := exec.ExecuteReview(ctx, "prompt")
+	if err == nil {
+		t.Fatal("expected error when context expires")
+	}
+	if !strings.Contains(err.Error(), "command") {
+		t.Errorf("Expected backend name in error, got %v", err)
+	}
+	if time.Since(start) > 4*time.Second {
+		t.Errorf("Expected command to be killed on context expiry")
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/git"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
 	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
 )
@@ -22,6 +25,9 @@ func configuredReviewCLI(cfg *config.Config) string {
 	return cli
 }
 
+// ErrGit marks failures to prepare the repository (clone, fetch, worktree)
+var ErrGit = errors.New("git operation failed")
+
 // Reviewer handles the complete code review workflow
 type Reviewer struct {
 	cfg *config.Config
@@ -31,8 +37,19 @@ type Reviewer struct {
 // ReviewRequest represents a request to review a patchset
 type ReviewRequest struct {
 	Project        string
+	Branch         string // Target branch; looked up when empty and projects entries are configured
 	ChangeNumber   int
 	PatchsetNumber int
+	WillRetry      bool // The caller retries on failure, so no failure notice is posted
+	Options        ReviewOptions
+}
+
+// ReviewOptions adjust the review prompt, e.g. for reviews requested with a
+// comment trigger
+type ReviewOptions struct {
+	RequestedBy string   // Account that asked for the review; empty for automatic reviews
+	Focus       []string // Areas to concentrate on, e.g. "security"
+	Incremental bool     // Review only what changed since the previous patchset
 }
 
 // NewReviewer creates a new Reviewer instance
@@ -48,6 +65,108 @@ func NewReviewer(cfg *config.Config) *Reviewer {
 func (r *Reviewer) ReviewChange(ctx context.Context, req ReviewRequest) error {
 	startTime := time.Now()
 
+	change, err := r.prepare(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer change.cleanup()
+
+	if change.empty() {
+		r.log.Info("No changes found, skipping review")
+		return nil
+	}
+	if r.skipForSize(ctx, req, change) {
+		return nil
+	}
+
+	cfg := change.cfg
+	executor := NewReviewExecutor(change.worktree, cfg)
+	prompt, err := executor.BuildPrompt(change.info)
+	if err != nil {
+		return fmt.Errorf("failed to build prompt: %w", err)
+	}
+
+	reviewCLI := configuredReviewCLI(cfg)
+	r.log.Debugf("Prompt length: %d characters", len(prompt))
+	r.log.Infof("Executing %s for review (timeout: %ds)...", reviewCLI, cfg.Review.ClaudeTimeout)
+
+	output, err := executor.ExecuteReview(ctx, prompt)
+	if ctx.Err() != nil {
+		// Superseded, abandoned or shutting down: whatever the CLI produced
+		// is stale, so nothing is posted.
+		r.log.Infof("Review of %s #%d/%d cancelled, not posting: %v",
+			req.Project, req.ChangeNumber, req.PatchsetNumber, context.Cause(ctx))
+		return fmt.Errorf("review cancelled: %w", context.Cause(ctx))
+	}
+	if err != nil {
+		if errors.Is(err, ErrRateLimited) && !req.WillRetry {
+			if postErr := r.postRateLimitFailure(ctx, req, cfg, reviewCLI, err); postErr != nil {
+				r.log.Warnf("failed to post rate-limit failure notice for %s #%d/%d: %v",
+					req.Project, req.ChangeNumber, req.PatchsetNumber, postErr)
+			}
+		}
+		return fmt.Errorf("%s execution failed: %w", reviewCLI, err)
+	}
+
+	r.log.Debugf("%s output length: %d characters", reviewCLI, len(output))
+
+	if cfg.StructuredOutput() {
+		if err := r.postStructuredReview(ctx, req, cfg, output); err != nil {
+			return err
+		}
+	}
+
+	r.log.Infof("Review completed: %s/c/%s/+/%d/%d",
+		r.cfg.Gerrit.HTTPUrl, req.Project, req.ChangeNumber, req.PatchsetNumber)
+
+	elapsed := time.Since(startTime)
+	r.log.Infof("Total time: %.1fs", elapsed.Seconds())
+
+	return nil
+}
+
+// RenderPrompt checks out the patchset like ReviewChange and returns the
+// prompt the review backend would receive, without running it. skipReason
+// is set when the size limits would skip an automatic review.
+func (r *Reviewer) RenderPrompt(ctx context.Context, req ReviewRequest) (prompt, skipReason string, err error) {
+	change, err := r.prepare(ctx, req)
+	if err != nil {
+		return "", "", err
+	}
+	defer change.cleanup()
+
+	if change.empty() {
+		skipReason = "no files changed"
+	} else if req.Options.RequestedBy == "" {
+		skipReason = change.size.reason(locale.For(locale.English))
+	}
+
+	prompt, err = NewReviewExecutor(change.worktree, change.cfg).BuildPrompt(change.info)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build prompt: %w", err)
+	}
+	return prompt, skipReason, nil
+}
+
+// preparedChange is a patchset checked out into a worktree, with the
+// configuration and metadata its review needs
+type preparedChange struct {
+	worktree string
+	cfg      *config.Config
+	info     ChangeInfo
+	size     sizeCheck
+	cleanup  func()
+}
+
+// empty reports whether the patchset changes no files at all
+func (c *preparedChange) empty() bool {
+	return len(c.info.Files) == 0 && len(c.info.IgnoredFiles) == 0
+}
+
+// prepare checks out the patchset into an isolated worktree and resolves
+// the configuration and metadata of the change. The caller must call
+// cleanup on the result.
+func (r *Reviewer) prepare(ctx context.Context, req ReviewRequest) (*preparedChange, error) {
 	// Setup git repository
 	gitURL := r.cfg.GetGitURL(req.Project)
 	repoPath := r.cfg.GetRepoPath(req.Project)
@@ -60,88 +179,189 @@ func (r *Reviewer) ReviewChange(ctx context.Context, req ReviewRequest) error {
 	// Clone or update
 	r.log.Debugf("Cloning/updating repository...")
 	if err := repoMgr.CloneOrUpdate(ctx); err != nil {
-		return fmt.Errorf("failed to clone/update: %w", err)
+		return nil, fmt.Errorf("%w: failed to clone/update: %w", ErrGit, err)
 	}
 
-	// Fetch patchset
+	// Fetch patchset into an isolated worktree so concurrent reviews of the
+	// same project never share a working tree.
 	ref := git.GetPatchsetRef(req.ChangeNumber, req.PatchsetNumber)
-	r.log.Debugf("Fetching patchset: %s", ref)
-	if err := repoMgr.FetchPatchset(ctx, ref); err != nil {
-		return fmt.Errorf("failed to fetch patchset: %w", err)
-	}
-
-	// Checkout
-	r.log.Debugf("Checking out patchset...")
-	branchName, err := repoMgr.CheckoutPatchset(ctx, req.ChangeNumber, req.PatchsetNumber)
+	r.log.Debugf("Creating worktree for patchset: %s", ref)
+	wt, err := repoMgr.AddWorktree(ctx, r.cfg.GetWorktreeBasePath(), ref, req.ChangeNumber, req.PatchsetNumber)
 	if err != nil {
-		return fmt.Errorf("failed to checkout: %w", err)
+		return nil, fmt.Errorf("%w: failed to create worktree: %w", ErrGit, err)
 	}
-
-	defer func() {
-		if err := repoMgr.Cleanup(ctx, branchName); err != nil {
-			r.log.Warnf("Cleanup failed: %v", err)
+	r.log.Debugf("Worktree path: %s", wt.Path)
+
+	cleanup := func() {
+		// Use a fresh context so cleanup still runs after cancellation.
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := repoMgr.RemoveWorktree(cleanupCtx, wt); err != nil {
+			r.log.Warnf("Worktree cleanup failed: %v", err)
 		}
-	}()
+	}
+
+	wtRepo := wt.Repo()
+	cfg, branch := r.changeConfig(ctx, req, wt.Path)
 
 	// Check if there are changes
 	r.log.Debugf("Checking for changes...")
-	changedFiles, _, err := repoMgr.GetDiffStats(ctx)
+	stats, err := wtRepo.GetNumstat(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get diff stats: %w", err)
+		cleanup()
+		return nil, fmt.Errorf("failed to get diff stats: %w", err)
 	}
+	r.log.Debugf("Changed files: %d", len(stats))
 
-	if changedFiles == 0 {
-		r.log.Info("No changes found, skipping review")
-		return nil
+	size, err := measureSize(stats, cfg.Review.Size)
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("invalid size limits: %w", err)
 	}
+	r.log.Debugf("Size: %d files, %d insertions, %d ignored files", size.Files, size.Insertions, len(size.IgnoredFiles))
 
-	r.log.Debugf("Changed files: %d", changedFiles)
+	var subject string
+	if message, err := wtRepo.GetCommitMessage(ctx); err == nil {
+		subject, _, _ = strings.Cut(strings.TrimSpace(message), "\n")
+	}
 
-	// Build prompt and execute configured review CLI
-	r.log.Debugf("Building review prompt...")
-	executor := NewReviewExecutor(repoPath, r.cfg)
-	changeInfo := ChangeInfo{
-		Project:        req.Project,
-		ChangeNumber:   req.ChangeNumber,
-		PatchsetNumber: req.PatchsetNumber,
+	return &preparedChange{
+		worktree: wt.Path,
+		cfg:      cfg,
+		info: ChangeInfo{
+			Project:        req.Project,
+			Branch:         branch,
+			ChangeNumber:   req.ChangeNumber,
+			PatchsetNumber: req.PatchsetNumber,
+			Subject:        subject,
+			Files:          size.Changed,
+			IgnoredFiles:   size.IgnoredFiles,
+			Options:        req.Options,
+		},
+		size:    size,
+		cleanup: cleanup,
+	}, nil
+}
+
+// changeConfig resolves the configuration for the change: the in-repo
+// config file of the patchset (when review.repo_config is set), then the
+// matching projects entries. An invalid in-repo file is ignored. It also
+// returns the target branch, which may be empty if it is unknown.
+func (r *Reviewer) changeConfig(ctx context.Context, req ReviewRequest, worktree string) (*config.Config, string) {
+	branch := req.Branch
+	if len(r.cfg.Projects) == 0 && !r.cfg.Review.RepoConfig {
+		return r.cfg, branch
 	}
 
-	prompt, err := executor.BuildPrompt(changeInfo)
-	if err != nil {
-		return fmt.Errorf("failed to build prompt: %w", err)
+	if branch == "" && len(r.cfg.Projects) > 0 {
+		client := r.cfg.Gerrit.NewClient()
+		change, err := client.GetChangeDetail(ctx, strconv.Itoa(req.ChangeNumber), nil)
+		if err != nil {
+			r.log.Warnf("Failed to look up branch of change %d, branch-specific overrides not applied: %v", req.ChangeNumber, err)
+		} else {
+			branch = change.Branch
+		}
 	}
 
-	reviewCLI := configuredReviewCLI(r.cfg)
-	r.log.Debugf("Prompt length: %d characters", len(prompt))
-	r.log.Infof("Executing %s for review (timeout: %ds)...", reviewCLI, r.cfg.Review.ClaudeTimeout)
+	var repo *config.ReviewOverride
+	if r.cfg.Review.RepoConfig {
+		override, err := config.LoadRepoConfig(worktree)
+		if err == nil && override != nil {
+			err = r.cfg.ValidateRepoConfig(override)
+		}
+		if err != nil {
+			r.log.Warnf("Ignoring %s of %s #%d/%d: %v", config.RepoConfigFile,
+				req.Project, req.ChangeNumber, req.PatchsetNumber, err)
+		} else if override != nil {
+			r.log.Debugf("Using %s from the patchset", config.RepoConfigFile)
+			repo = override
+		}
+	}
 
-	output, err := executor.ExecuteReview(ctx, prompt)
+	return r.cfg.ForChange(req.Project, branch, repo), branch
+}
+
+// reviewClient returns a Gerrit client that posts reviews in the language
+// and on the vote label of cfg, the configuration of the reviewed change
+func (r *Reviewer) reviewClient(cfg *config.Config) *gerrit.Client {
+	client := r.cfg.Gerrit.NewClient()
+	client.SetLanguage(cfg.Review.Language)
+	client.SetVoteLabel(cfg.Review.Label)
+	return client
+}
+
+// postStructuredReview validates the backend's structured result, applies
+// the vote policy of cfg and posts it.
+func (r *Reviewer) postStructuredReview(ctx context.Context, req ReviewRequest, cfg *config.Config, output string) error {
+	structured, err := ParseStructuredReview(output)
 	if err != nil {
-		if errors.Is(err, ErrRateLimited) {
-			if postErr := r.postRateLimitFailure(ctx, req, reviewCLI, err); postErr != nil {
-				r.log.Warnf("failed to post rate-limit failure notice for %s #%d/%d: %v",
-					req.Project, req.ChangeNumber, req.PatchsetNumber, postErr)
-			}
-		}
-		return fmt.Errorf("%s execution failed: %w", reviewCLI, err)
+		return fmt.Errorf("failed to parse structured review: %w", err)
 	}
 
-	r.log.Debugf("%s output length: %d characters", reviewCLI, len(output))
+	result := structured.ToReviewResult()
+	if robot := cfg.Review.RobotComments; robot.Enabled {
+		runID := fmt.Sprintf("%d-%d-%s", req.ChangeNumber, req.PatchsetNumber, time.Now().UTC().Format("20060102T150405Z"))
+		result = structured.ToRobotReviewResult(types.Robot{ID: robot.RobotID, RunID: runID})
+	}
+	client := r.reviewClient(cfg)
 
-	r.log.Infof("Review completed: %s/c/%s/+/%d/%d",
-		r.cfg.Gerrit.HTTPUrl, req.Project, req.ChangeNumber, req.PatchsetNumber)
+	// Drafts left by the backend are published with the review.
+	drafts, err := client.ListDrafts(ctx, strconv.Itoa(req.ChangeNumber), strconv.Itoa(req.PatchsetNumber))
+	if err != nil {
+		return fmt.Errorf("failed to list drafts: %w", err)
+	}
+	requested := formatLabels(client.ReviewLabels(result))
+	decision, err := cfg.Review.Vote.Policy().ApplyToReview(result, gerrit.CommentMessages(drafts), locale.For(cfg.Review.Language))
+	if err != nil {
+		return fmt.Errorf("structured review not posted: %w", err)
+	}
+	if decision.Adjusted() {
+		r.log.Infof("Vote policy: %s", decision.Explain(locale.For(locale.English)))
+	}
 
-	elapsed := time.Since(startTime)
-	r.log.Infof("Total time: %.1fs", elapsed.Seconds())
+	// Check every label against what the bot account may vote on the change
+	change, err := client.GetChangeDetail(ctx, strconv.Itoa(req.ChangeNumber), []string{"DETAILED_LABELS"})
+	if err != nil {
+		return fmt.Errorf("failed to get change labels: %w", err)
+	}
+	labels := client.ReviewLabels(result)
+	if err := change.CheckLabels(labels); err != nil {
+		return fmt.Errorf("structured review not posted: %w", err)
+	}
 
+	if err := client.PostReview(ctx, req.ChangeNumber, req.PatchsetNumber, result); err != nil {
+		return fmt.Errorf("failed to post structured review: %w", err)
+	}
+
+	posted := formatLabels(labels)
+	if posted != requested {
+		posted += " (requested " + requested + ")"
+	}
+	r.log.Infof("Posted structured review: %s #%d/%d (%s, %d comments)",
+		req.Project, req.ChangeNumber, req.PatchsetNumber, posted, len(result.Comments))
 	return nil
 }
 
-func (r *Reviewer) postRateLimitFailure(ctx context.Context, req ReviewRequest, reviewCLI string, cause error) error {
-	client := gerrit.NewClient(r.cfg.Gerrit.HTTPUrl, r.cfg.Gerrit.HTTPUser, r.cfg.Gerrit.HTTPPass)
+// formatLabels formats label votes as "Code-Review=-1, Verified=+1"
+func formatLabels(labels map[string]int) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	votes := make([]string, 0, len(names))
+	for _, name := range names {
+		votes = append(votes, fmt.Sprintf("%s=%+d", name, labels[name]))
+	}
+	return strings.Join(votes, ", ")
+}
+
+func (r *Reviewer) postRateLimitFailure(ctx context.Context, req ReviewRequest, cfg *config.Config, reviewCLI string, cause error) error {
+	client := r.reviewClient(cfg)
 
 	review := &types.ReviewResult{
-		Summary: buildRateLimitFailureSummary(reviewCLI, cause),
+		Summary: buildRateLimitFailureSummary(locale.For(cfg.Review.Language), reviewCLI, cause),
 		Vote:    0,
 	}
 
@@ -154,20 +374,12 @@ func (r *Reviewer) postRateLimitFailure(ctx context.Context, req ReviewRequest,
 	return nil
 }
 
-func buildRateLimitFailureSummary(reviewCLI string, cause error) string {
-	var sb strings.Builder
+func buildRateLimitFailureSummary(m *locale.Messages, reviewCLI string, cause error) string {
 	errMsg := "rate limit"
 	if cause != nil {
 		errMsg = truncateForReviewMessage(cause.Error(), 220)
 	}
-
-	sb.WriteString("Automated review started but could not finish because the AI backend hit a rate limit.\n\n")
-	sb.WriteString(fmt.Sprintf("Backend: %s\n", reviewCLI))
-	sb.WriteString("Result: no review comments were produced.\n")
-	sb.WriteString(fmt.Sprintf("Error: %s\n", errMsg))
-	sb.WriteString("\nPlease retry this patchset later.")
-
-	return sb.String()
+	return fmt.Sprintf(m.RateLimited, reviewCLI, errMsg)
 }
 
 func truncateForReviewMessage(text string, maxLen int) string {
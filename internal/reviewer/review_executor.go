@@ -13,8 +13,8 @@ import (
 	"time"
 
 	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
 	"github.com/gerrit-ai-review/gerrit-tools/internal/logger"
-	codereview "github.com/gerrit-ai-review/gerrit-tools/skills/code-review"
 )
 
 // ReviewExecutor handles execution of the configured AI CLI for code review
@@ -55,7 +55,10 @@ type ToolInput struct {
 	// Add other fields as needed
 }
 
-var ErrRateLimited = errors.New("review cli rate limited")
+var (
+	ErrRateLimited   = errors.New("review cli rate limited")
+	ErrReviewTimeout = errors.New("review cli timed out")
+)
 
 // NewReviewExecutor creates a new review executor.
 func NewReviewExecutor(workDir string, cfg *config.Config) *ReviewExecutor {
@@ -77,32 +80,40 @@ func (c *ReviewExecutor) ExecuteReview(ctx context.Context, prompt string) (stri
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	switch c.reviewCLI() {
-	case "codex":
-		return c.executeCodexReview(ctx, prompt, timeout)
-	default:
-		return c.executeClaudeReview(ctx, prompt, timeout)
+	backend, err := NewBackend(c.reviewCLI(), c.cfg)
+	if err != nil {
+		return "", err
 	}
+
+	return c.runBackend(ctx, backend, prompt, timeout)
 }
 
-func (c *ReviewExecutor) executeClaudeReview(ctx context.Context, prompt string, timeout time.Duration) (string, error) {
+// runBackend drives one backend invocation: it starts the CLI, streams its
+// stdout through the backend and returns the backend's final message.
+func (c *ReviewExecutor) runBackend(ctx context.Context, backend Backend, prompt string, timeout time.Duration) (string, error) {
+	name := backend.Name()
+	run := &BackendRun{Prompt: prompt, WorkDir: c.workDir}
+	defer run.cleanup()
+
 	// Stream log is opt-in only because raw stream output may contain sensitive data.
 	var streamLog *os.File
-	if os.Getenv("GERRIT_REVIEWER_SAVE_CLAUDE_STREAM") == "1" {
+	if os.Getenv("GERRIT_REVIEWER_SAVE_"+strings.ToUpper(name)+"_STREAM") == "1" {
 		var err error
-		streamLog, err = os.CreateTemp("", "claude-review-*-stream.jsonl")
+		streamLog, err = os.CreateTemp("", name+"-review-*-stream.jsonl")
 		if err != nil {
-			return "", fmt.Errorf("failed to create stream log file: %w", err)
+			return "", fmt.Errorf("failed to create %s stream log file: %w", name, err)
 		}
 		defer streamLog.Close()
-		c.log.Infof("Claude stream log enabled: %s", streamLog.Name())
+		c.log.Infof("%s stream log enabled: %s", name, streamLog.Name())
 	}
 
-	// Build command arguments with stream-json output
-	args := c.buildClaudeArgs(prompt)
-	c.log.Debugf("Bootstrapping claude CLI command: %s", formatCommandForLog("claude", args))
+	args, err := backend.BuildArgs(run)
+	if err != nil {
+		return "", err
+	}
+	c.log.Debugf("Bootstrapping %s CLI command: %s", name, formatCommandForLog(backend.Executable(), args))
 
-	cmd := exec.CommandContext(ctx, "claude", args...)
+	cmd := exec.CommandContext(ctx, backend.Executable(), args...)
 	cmd.Dir = c.workDir
 
 	// Inherit parent environment and add Gerrit-specific vars for gerrit-cli tool
@@ -110,38 +121,43 @@ func (c *ReviewExecutor) executeClaudeReview(ctx context.Context, prompt string,
 	env := filterEnv(os.Environ(), "CLAUDECODE")
 	cmd.Env = append(env, c.cfg.GerritEnvVars()...)
 
-	// Get stdout pipe for reading stream
+	if run.Stdin != "" {
+		cmd.Stdin = strings.NewReader(run.Stdin)
+	}
+
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return "", fmt.Errorf("failed to get stdout pipe: %w", err)
+		return "", fmt.Errorf("failed to get %s stdout pipe: %w", name, err)
 	}
 
-	// Get stderr pipe for error messages
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return "", fmt.Errorf("failed to get stderr pipe: %w", err)
+		return "", fmt.Errorf("failed to get %s stderr pipe: %w", name, err)
 	}
 
-	// Start the command
 	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("failed to start claude: %w", err)
+		return "", fmt.Errorf("failed to start %s: %w", name, err)
 	}
 
+	// Killing the CLI does not kill its children, which may keep the pipes
+	// open; close them on cancellation so the readers below return.
+	stopClosing := context.AfterFunc(ctx, func() {
+		stdout.Close()
+		stderr.Close()
+	})
+	defer stopClosing()
+
 	// Read stderr in background
 	var stderrOutput strings.Builder
+	stderrDone := make(chan struct{})
 	go func() {
+		defer close(stderrDone)
 		stderrScanner := bufio.NewScanner(stderr)
 		for stderrScanner.Scan() {
-			line := stderrScanner.Text()
-			stderrOutput.WriteString(line + "\n")
+			stderrOutput.WriteString(stderrScanner.Text() + "\n")
 		}
 	}()
 
-	// Process stream line by line
-	var assistantText strings.Builder
-	var toolCallCount int
-	var bashCallCount int
-
 	scanner := bufio.NewScanner(stdout)
 	// Increase buffer size for large JSON lines
 	const maxCapacity = 1024 * 1024 // 1MB
@@ -150,256 +166,36 @@ func (c *ReviewExecutor) executeClaudeReview(ctx context.Context, prompt string,
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		run.Stdout.WriteString(line + "\n")
 
 		// Write raw line only when explicitly enabled.
 		if streamLog != nil {
 			if _, err := streamLog.WriteString(line + "\n"); err != nil {
-				c.log.Warnf("Failed to write to stream log: %v", err)
+				c.log.Warnf("Failed to write to %s stream log: %v", name, err)
 			}
 		}
 
-		// Try to parse as JSON event
-		var event StreamEvent
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			// Not a JSON line, skip
-			continue
-		}
-
-		// Check if it's a stream_event wrapper
-		if event.Type == "stream_event" && len(event.Event) > 0 {
-			// Parse inner event
-			var innerEvent StreamEventInner
-			if err := json.Unmarshal(event.Event, &innerEvent); err != nil {
-				continue
-			}
-
-			// Handle different event types
-			switch innerEvent.Type {
-			case "content_block_start":
-				// Check if it's a tool use
-				if innerEvent.ContentBlock.Type == "tool_use" {
-					toolCallCount++
-					if innerEvent.ContentBlock.Name == "Bash" {
-						bashCallCount++
-						// Try to parse input
-						var toolInput ToolInput
-						if err := json.Unmarshal(innerEvent.ContentBlock.Input, &toolInput); err == nil {
-							c.log.Debugf("[Tool #%d] Bash: %s", toolCallCount, truncate(toolInput.Command, 100))
-						}
-					} else {
-						c.log.Debugf("[Tool #%d] %s (ID: %s)", toolCallCount, innerEvent.ContentBlock.Name, innerEvent.ContentBlock.ID)
-					}
-				}
-
-			case "content_block_delta":
-				// Check if it's text delta
-				var deltaData struct {
-					Type string `json:"type"`
-					Text string `json:"text,omitempty"`
-				}
-				if err := json.Unmarshal(innerEvent.Delta, &deltaData); err == nil {
-					if deltaData.Type == "text_delta" {
-						assistantText.WriteString(deltaData.Text)
-					}
-				}
-
-			case "message_stop":
-				// Message completed
-				c.log.Debugf("Claude message completed")
-			}
-		}
+		backend.ParseStreamLine(run, line)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("error reading claude output: %w", err)
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return "", fmt.Errorf("error reading %s output: %w", name, err)
 	}
 
-	// Wait for command to finish
-	if err := cmd.Wait(); err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("claude execution timed out after %v", timeout)
-		}
-		stderrText := strings.TrimSpace(stderrOutput.String())
-		if isRateLimitedErrorText(stderrText) || isRateLimitedErrorText(err.Error()) {
-			return "", fmt.Errorf("%w: claude execution failed: %v", ErrRateLimited, err)
-		}
-		stderrLen := len(stderrText)
-		if stderrLen > 0 {
-			return "", fmt.Errorf("claude execution failed: %w (stderr length: %d)", err, stderrLen)
-		}
-		return "", fmt.Errorf("claude execution failed: %w (no stderr output)", err)
-	}
-
-	c.log.Infof("Claude execution completed: %d tool calls (%d Bash)", toolCallCount, bashCallCount)
-
-	return assistantText.String(), nil
-}
-
-func (c *ReviewExecutor) executeCodexReview(ctx context.Context, prompt string, timeout time.Duration) (string, error) {
-	outputFile, err := os.CreateTemp("", "codex-review-*-last-message.txt")
-	if err != nil {
-		return "", fmt.Errorf("failed to create codex output file: %w", err)
-	}
-	outputPath := outputFile.Name()
-	if err := outputFile.Close(); err != nil {
-		return "", fmt.Errorf("failed to close codex output file: %w", err)
-	}
-	defer os.Remove(outputPath)
-
-	args := c.buildCodexArgs(prompt, outputPath)
-	c.log.Debugf("Bootstrapping codex CLI command: %s", formatCommandForLog("codex", args))
-	cmd := exec.CommandContext(ctx, "codex", args...)
-	cmd.Dir = c.workDir
-
-	// Remove CLAUDECODE to avoid nested-session issues if this process is called from Claude Code.
-	env := filterEnv(os.Environ(), "CLAUDECODE")
-	cmd.Env = append(env, c.cfg.GerritEnvVars()...)
-
-	// Stream log is opt-in only because raw stream output may contain sensitive data.
-	var streamLog *os.File
-	if os.Getenv("GERRIT_REVIEWER_SAVE_CODEX_STREAM") == "1" {
-		streamLog, err = os.CreateTemp("", "codex-review-*-stream.jsonl")
-		if err != nil {
-			return "", fmt.Errorf("failed to create codex stream log file: %w", err)
-		}
-		defer streamLog.Close()
-		c.log.Infof("Codex stream log enabled: %s", streamLog.Name())
-	}
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return "", fmt.Errorf("failed to get codex stdout pipe: %w", err)
-	}
-
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return "", fmt.Errorf("failed to get codex stderr pipe: %w", err)
-	}
-
-	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("failed to start codex: %w", err)
-	}
-
-	var stderrOutput strings.Builder
-	go func() {
-		stderrScanner := bufio.NewScanner(stderr)
-		for stderrScanner.Scan() {
-			line := stderrScanner.Text()
-			stderrOutput.WriteString(line + "\n")
-		}
-	}()
-
-	var stdoutOutput strings.Builder
-	var toolCallCount int
-	var eventCount int
-
-	scanner := bufio.NewScanner(stdout)
-	const maxCapacity = 1024 * 1024 // 1MB
-	buf := make([]byte, maxCapacity)
-	scanner.Buffer(buf, maxCapacity)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		stdoutOutput.WriteString(line + "\n")
-
-		if streamLog != nil {
-			if _, err := streamLog.WriteString(line + "\n"); err != nil {
-				c.log.Warnf("Failed to write to codex stream log: %v", err)
-			}
-		}
-
-		eventType, command := parseCodexEventLine(line)
-		if eventType == "" {
-			continue
-		}
-		eventCount++
-
-		if command != "" {
-			toolCallCount++
-			c.log.Debugf("[Tool #%d] Bash: %s", toolCallCount, truncate(command, 100))
-			continue
-		}
-
-		if isCodexToolRelatedEvent(eventType) {
-			toolCallCount++
-			c.log.Debugf("[Tool #%d] Codex event: %s", toolCallCount, eventType)
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("error reading codex output: %w", err)
-	}
+	<-stderrDone
+	run.Stderr = stderrOutput.String()
 
 	if err := cmd.Wait(); err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("codex execution timed out after %v", timeout)
-		}
-		stderrText := strings.TrimSpace(stderrOutput.String())
-		stdoutText := strings.TrimSpace(stdoutOutput.String())
-		if isRateLimitedErrorText(stderrText) || isRateLimitedErrorText(stdoutText) || isRateLimitedErrorText(err.Error()) {
-			return "", fmt.Errorf("%w: codex execution failed: %v", ErrRateLimited, err)
-		}
-		stderrLen := len(stderrText)
-		if stderrLen > 0 {
-			return "", fmt.Errorf("codex execution failed: %w (stderr length: %d)", err, stderrLen)
+			return "", fmt.Errorf("%w: %s execution timed out after %v", ErrReviewTimeout, name, timeout)
 		}
-		stdoutLen := len(stdoutText)
-		if stdoutLen > 0 {
-			return "", fmt.Errorf("codex execution failed: %w (stdout length: %d)", err, stdoutLen)
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%s execution cancelled: %w", name, context.Cause(ctx))
 		}
-		return "", fmt.Errorf("codex execution failed: %w (no output)", err)
-	}
-
-	c.log.Infof("Codex execution completed: %d events (%d tool-related)", eventCount, toolCallCount)
-
-	finalOutput, err := os.ReadFile(outputPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read codex output file: %w", err)
-	}
-
-	text := strings.TrimSpace(string(finalOutput))
-	if text == "" {
-		text = strings.TrimSpace(stdoutOutput.String())
-	}
-
-	return text, nil
-}
-
-func (c *ReviewExecutor) buildClaudeArgs(prompt string) []string {
-	args := []string{
-		"-p", prompt,
-		"--output-format", "stream-json",
-		"--include-partial-messages",
-		"--verbose",
-	}
-
-	if c.cfg.Review.ClaudeSkipPermissionsCheck {
-		c.log.Warnf("Claude permission checks are disabled via --dangerously-skip-permissions")
-		args = append(args, "--dangerously-skip-permissions")
-	}
-
-	return args
-}
-
-func (c *ReviewExecutor) buildCodexArgs(prompt string, outputPath string) []string {
-	args := []string{
-		"exec",
-		"--json",
-		"--skip-git-repo-check",
-		"--color", "never",
-		"--output-last-message", outputPath,
+		return "", backend.ClassifyError(run, err)
 	}
 
-	if c.cfg.Review.ClaudeSkipPermissionsCheck {
-		c.log.Warnf("Codex approvals and sandbox are disabled via --dangerously-bypass-approvals-and-sandbox")
-		args = append(args, "--dangerously-bypass-approvals-and-sandbox")
-	} else {
-		args = append(args, "--full-auto")
-	}
-
-	args = append(args, prompt)
-
-	return args
+	return backend.FinalMessage(run)
 }
 
 func (c *ReviewExecutor) reviewCLI() string {
@@ -410,28 +206,6 @@ func (c *ReviewExecutor) reviewCLI() string {
 	return cli
 }
 
-func parseCodexEventLine(line string) (string, string) {
-	var payload any
-	if err := json.Unmarshal([]byte(line), &payload); err != nil {
-		return "", ""
-	}
-
-	m, ok := payload.(map[string]any)
-	if !ok {
-		return "", ""
-	}
-
-	eventType, _ := m["type"].(string)
-	return eventType, findFirstCommandString(payload)
-}
-
-func isCodexToolRelatedEvent(eventType string) bool {
-	eventType = strings.ToLower(eventType)
-	return strings.Contains(eventType, "exec") ||
-		strings.Contains(eventType, "tool") ||
-		strings.Contains(eventType, "command")
-}
-
 func isRateLimitedErrorText(text string) bool {
 	text = strings.ToLower(strings.TrimSpace(text))
 	if text == "" {
@@ -460,33 +234,9 @@ func isRateLimitedErrorText(text string) bool {
 	return false
 }
 
-func findFirstCommandString(v any) string {
-	switch x := v.(type) {
-	case map[string]any:
-		for k, val := range x {
-			key := strings.ToLower(k)
-			if key == "command" || key == "cmd" {
-				if s, ok := val.(string); ok {
-					return s
-				}
-			}
-			if nested := findFirstCommandString(val); nested != "" {
-				return nested
-			}
-		}
-	case []any:
-		for _, val := range x {
-			if nested := findFirstCommandString(val); nested != "" {
-				return nested
-			}
-		}
-	}
-	return ""
-}
-
 // BuildPrompt constructs the review prompt with change information
 func (c *ReviewExecutor) BuildPrompt(changeInfo ChangeInfo) (string, error) {
-	skillContent, err := c.loadSkillContent()
+	skillContent, err := c.loadSkillContent(changeInfo)
 	if err != nil {
 		return "", err
 	}
@@ -504,6 +254,8 @@ Review Gerrit change **%d** (Patchset %d) in project **%s**.
 
 The `+"`gerrit-cli`"+` tool is available in PATH as: `+"`%s`"+`
 
+Write all draft comments and the review message in %s.
+
 Follow the review workflow described above. Start with Phase 1:
 
 `+"```bash"+`
@@ -515,27 +267,68 @@ Follow the review workflow described above. Start with Phase 1:
 		changeInfo.PatchsetNumber,
 		changeInfo.Project,
 		cliCmd,
+		locale.For(c.cfg.Review.Language).Name,
 		cliCmd,
 		changeInfo.ChangeNumber,
 	)
 
+	prompt += buildOptionsInstructions(changeInfo, cliCmd)
+	prompt += buildIgnoredFilesInstructions(changeInfo.IgnoredFiles)
+
+	if c.cfg.StructuredOutput() {
+		instructions, err := buildStructuredOutputInstructions()
+		if err != nil {
+			return "", err
+		}
+		prompt += "\n" + instructions
+	}
+
 	return prompt, nil
 }
 
-func (c *ReviewExecutor) loadSkillContent() (string, error) {
-	c.log.Debugf("Using embedded skill content")
-	skillContent, err := codereview.Content()
-	if err != nil {
-		return "", err
+// loadSkillContent composes the configured skill fragments for the change
+func (c *ReviewExecutor) loadSkillContent(changeInfo ChangeInfo) (string, error) {
+	skill := c.cfg.Review.Skill
+	if skill.Base == "" && skill.Dir == "" && len(skill.Fragments) == 0 && skill.RepoDir == "" {
+		c.log.Debugf("Using embedded skill content")
+	}
+	return composeSkill(skill, c.workDir, newSkillData(changeInfo, c.cfg.Review.Language))
+}
+
+// buildOptionsInstructions describes the requested review options, if any
+func buildOptionsInstructions(changeInfo ChangeInfo, cliCmd string) string {
+	opts := changeInfo.Options
+	if opts.RequestedBy == "" && len(opts.Focus) == 0 && !opts.Incremental {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n## Review Request\n\n")
+	if opts.RequestedBy != "" {
+		fmt.Fprintf(&b, "This review was explicitly requested by **%s** in a comment on the change.\n", opts.RequestedBy)
+	}
+	if len(opts.Focus) > 0 {
+		fmt.Fprintf(&b, "- Focus on: **%s**. Still report other serious issues, but keep the review centered on these areas.\n",
+			strings.Join(opts.Focus, ", "))
+	}
+	if opts.Incremental && changeInfo.PatchsetNumber > 1 {
+		fmt.Fprintf(&b, "- Incremental review: only review what changed since patchset %d. Use:\n\n", changeInfo.PatchsetNumber-1)
+		fmt.Fprintf(&b, "```bash\n%s patchset diff %d %d --base %d\n```\n",
+			cliCmd, changeInfo.ChangeNumber, changeInfo.PatchsetNumber, changeInfo.PatchsetNumber-1)
 	}
-	return skillContent, nil
+	return b.String()
 }
 
 // ChangeInfo contains information about the change being reviewed
 type ChangeInfo struct {
 	Project        string
+	Branch         string
 	ChangeNumber   int
 	PatchsetNumber int
+	Subject        string
+	Files          []string // Changed files, without the ignored ones
+	IgnoredFiles   []string // Changed files excluded by review.size.ignore
+	Options        ReviewOptions
 }
 
 // truncate truncates a string to maxLen characters
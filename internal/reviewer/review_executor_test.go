@@ -14,7 +14,7 @@ func TestBuildClaudeArgs_DefaultSecureMode(t *testing.T) {
 		},
 	})
 
-	args := exec.buildClaudeArgs("test prompt")
+	args := newTestBackend(t, exec).(*claudeBackend).buildArgs("test prompt")
 
 	for _, arg := range args {
 		if arg == "--dangerously-skip-permissions" {
@@ -30,7 +30,7 @@ func TestBuildClaudeArgs_SkipPermissionsEnabled(t *testing.T) {
 		},
 	})
 
-	args := exec.buildClaudeArgs("test prompt")
+	args := newTestBackend(t, exec).(*claudeBackend).buildArgs("test prompt")
 
 	found := false
 	for _, arg := range args {
@@ -53,7 +53,7 @@ func TestBuildCodexArgs_DefaultSafeMode(t *testing.T) {
 		},
 	})
 
-	args := exec.buildCodexArgs("test prompt", "/tmp/out.txt")
+	args := newTestBackend(t, exec).(*codexBackend).buildArgs("test prompt", "/tmp/out.txt")
 
 	requiredArgs := []string{
 		"exec",
@@ -84,7 +84,7 @@ func TestBuildCodexArgs_SkipPermissionsEnabled(t *testing.T) {
 		},
 	})
 
-	args := exec.buildCodexArgs("test prompt", "/tmp/out.txt")
+	args := newTestBackend(t, exec).(*codexBackend).buildArgs("test prompt", "/tmp/out.txt")
 
 	if !contains(args, "--dangerously-bypass-approvals-and-sandbox") {
 		t.Fatalf("expected dangerous bypass flag for codex when skip permissions enabled")
@@ -178,3 +178,41 @@ func TestIsRateLimitedErrorText(t *testing.T) {
 		}
 	}
 }
+
+func TestNewBackend_UnknownName(t *testing.T) {
+	_, err := NewBackend("does-not-exist", &config.Config{})
+	if err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+	if !strings.Contains(err.Error(), "claude") || !strings.Contains(err.Error(), "command") {
+		t.Errorf("expected error to list available backends, got: %v", err)
+	}
+}
+
+func TestRegisterBackend_AcceptedByConfigValidate(t *testing.T) {
+	RegisterBackend("test-local-llm", newClaudeBackend)
+
+	cfg := &config.Config{
+		Gerrit: config.GerritConfig{
+			SSHAlias: "gerrit",
+			HTTPUrl:  "https://gerrit.example.com",
+			HTTPUser: "bot",
+			HTTPPass: "secret",
+		},
+		Git:    config.GitConfig{RepoBasePath: "/tmp/repos"},
+		Review: config.ReviewConfig{CLI: "test-local-llm"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected registered backend to validate, got: %v", err)
+	}
+}
+
+// newTestBackend creates the backend selected by the executor's config
+func newTestBackend(t *testing.T, exec *ReviewExecutor) Backend {
+	t.Helper()
+	backend, err := NewBackend(exec.reviewCLI(), exec.cfg)
+	if err != nil {
+		t.Fatalf("NewBackend failed: %v", err)
+	}
+	return backend
+}
@@ -1,13 +1,20 @@
 package reviewer
 
 import (
+	"context"
 	"errors"
+	"net"
+	"net/http"
 	"strings"
 	"testing"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
 )
 
 func TestBuildRateLimitFailureSummary(t *testing.T) {
-	summary := buildRateLimitFailureSummary("codex", errors.New("rate limit exceeded"))
+	summary := buildRateLimitFailureSummary(locale.For(locale.English), "codex", errors.New("rate limit exceeded"))
 
 	if !strings.Contains(summary, "Backend: codex") {
 		t.Fatalf("expected backend in summary, got %q", summary)
@@ -18,6 +25,17 @@ func TestBuildRateLimitFailureSummary(t *testing.T) {
 	if !strings.Contains(summary, "Please retry this patchset later.") {
 		t.Fatalf("expected retry guidance in summary, got %q", summary)
 	}
+
+	summary = buildRateLimitFailureSummary(locale.For(locale.TraditionalChinese), "codex", errors.New("rate limit exceeded"))
+	if !strings.Contains(summary, "後端：codex") || !strings.Contains(summary, "rate limit exceeded") {
+		t.Fatalf("expected Traditional Chinese summary, got %q", summary)
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	if got := formatLabels(map[string]int{"Verified": 1, "Code-Review": -1, "AI-Review": 0}); got != "AI-Review=+0, Code-Review=-1, Verified=+1" {
+		t.Errorf("unexpected labels: %q", got)
+	}
 }
 
 func TestTruncateForReviewMessage(t *testing.T) {
@@ -26,3 +44,70 @@ func TestTruncateForReviewMessage(t *testing.T) {
 		t.Fatalf("unexpected truncate output: %q", got)
 	}
 }
+
+// newLabelsTestServer serves a change on which the caller may vote
+// permitted on Code-Review, and reports whether a review was posted
+func newLabelsTestServer(t *testing.T, permitted string) (string, *bool) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("skipping network-dependent test: %v", err)
+	}
+
+	posted := new(bool)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/drafts/"):
+			w.Write([]byte(")]}'\n{}"))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/review"):
+			*posted = true
+			w.Write([]byte(")]}'\n{}"))
+		default:
+			w.Write([]byte(`)]}'
+{"_number": 100, "labels": {"Code-Review": {}}, "permitted_labels": {"Code-Review": [` + permitted + `]}}`))
+		}
+	})}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() { _ = server.Close() })
+
+	return "http://" + listener.Addr().String(), posted
+}
+
+func TestPostStructuredReviewChecksLabels(t *testing.T) {
+	output := `{"schema_version": 1, "summary": "One issue.", "vote": -1,
+  "comments": [{"file": "main.go", "line": 1, "severity": "P1", "message": "Missing error check"}]}`
+	req := ReviewRequest{Project: "platform/app", ChangeNumber: 100, PatchsetNumber: 1}
+
+	tests := []struct {
+		name      string
+		permitted string
+		wantPost  bool
+	}{
+		{"permitted vote", `"-1", " 0", "+1"`, true},
+		{"vote not permitted", `" 0", "+1"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, posted := newLabelsTestServer(t, tt.permitted)
+			cfg := &config.Config{
+				Gerrit: config.GerritConfig{HTTPUrl: baseURL, HTTPUser: "ai-bot", HTTPPass: "secret"},
+				Review: config.ReviewConfig{Vote: config.VoteConfig{Max: 1, Min: -2}},
+			}
+
+			err := NewReviewer(cfg).postStructuredReview(context.Background(), req, cfg, output)
+			if tt.wantPost && err != nil {
+				t.Fatalf("postStructuredReview failed: %v", err)
+			}
+			if !tt.wantPost && !errors.Is(err, gerrit.ErrLabelNotPermitted) {
+				t.Fatalf("Expected ErrLabelNotPermitted, got %v", err)
+			}
+			if *posted != tt.wantPost {
+				t.Errorf("Expected posted=%v, got %v", tt.wantPost, *posted)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/git"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/pattern"
+	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
+)
+
+// maxListedIgnoredFiles bounds the ignored files named in the prompt
+const maxListedIgnoredFiles = 50
+
+// sizeLimit identifies why the size limits skip a patchset
+type sizeLimit int
+
+const (
+	withinLimits sizeLimit = iota
+	onlyIgnoredFiles
+	tooManyFiles
+	tooManyInsertions
+)
+
+// sizeCheck is the outcome of applying the size limits to a patchset
+type sizeCheck struct {
+	Files        int       // Changed files, not counting ignored ones
+	Changed      []string  // Paths of the counted files
+	Insertions   int       // Inserted lines, not counting ignored files
+	IgnoredFiles []string  // Changed files matching an ignore pattern
+	Exceeded     sizeLimit // Why the patchset is skipped; withinLimits if it is not
+	Limit        int       // Value of the exceeded limit
+}
+
+// skipped reports whether the patchset exceeds the size limits
+func (c sizeCheck) skipped() bool {
+	return c.Exceeded != withinLimits
+}
+
+// reason describes why the patchset is skipped, in the language of m
+func (c sizeCheck) reason(m *locale.Messages) string {
+	switch c.Exceeded {
+	case onlyIgnoredFiles:
+		return m.SizeOnlyIgnored
+	case tooManyFiles:
+		return fmt.Sprintf(m.SizeTooManyFiles, c.Files, c.Limit)
+	case tooManyInsertions:
+		return fmt.Sprintf(m.SizeTooManyInsertions, c.Insertions, c.Limit)
+	}
+	return ""
+}
+
+// measureSize applies limits to the per-file stats of a patchset
+func measureSize(stats []git.DiffStat, limits config.SizeConfig) (sizeCheck, error) {
+	ignore, err := pattern.Compile(limits.Ignore)
+	if err != nil {
+		return sizeCheck{}, err
+	}
+
+	var check sizeCheck
+	for _, stat := range stats {
+		if ignore.Match(stat.File) != "" {
+			check.IgnoredFiles = append(check.IgnoredFiles, stat.File)
+			continue
+		}
+		check.Files++
+		check.Changed = append(check.Changed, stat.File)
+		check.Insertions += stat.Additions
+	}
+
+	switch {
+	case check.Files == 0 && len(check.IgnoredFiles) > 0:
+		check.Exceeded = onlyIgnoredFiles
+	case limits.MaxFiles > 0 && check.Files > limits.MaxFiles:
+		check.Exceeded, check.Limit = tooManyFiles, limits.MaxFiles
+	case limits.MaxInsertions > 0 && check.Insertions > limits.MaxInsertions:
+		check.Exceeded, check.Limit = tooManyInsertions, limits.MaxInsertions
+	}
+	return check, nil
+}
+
+// sizeSkipMessage fills the placeholders of the configured skip message,
+// or of the default one of m when message is empty
+func sizeSkipMessage(message string, check sizeCheck, m *locale.Messages) string {
+	if message == "" {
+		message = m.SizeSkipped
+	}
+	return strings.NewReplacer(
+		"{{reason}}", check.reason(m),
+		"{{files}}", strconv.Itoa(check.Files),
+		"{{insertions}}", strconv.Itoa(check.Insertions),
+	).Replace(message)
+}
+
+// skipForSize reports whether the size limits skip the review of change,
+// posting the configured message if so. Reviews requested with a comment
+// trigger are never skipped.
+func (r *Reviewer) skipForSize(ctx context.Context, req ReviewRequest, change *preparedChange) bool {
+	check := change.size
+	if !check.skipped() {
+		return false
+	}
+	reason := check.reason(locale.For(locale.English))
+	if req.Options.RequestedBy != "" {
+		r.log.Infof("Reviewing %s #%d/%d on request despite size limits: %s",
+			req.Project, req.ChangeNumber, req.PatchsetNumber, reason)
+		return false
+	}
+
+	r.log.Infof("⏭️  Skipped %s #%d/%d: %s", req.Project, req.ChangeNumber, req.PatchsetNumber, reason)
+	if size := change.cfg.Review.Size; size.Notify {
+		message := sizeSkipMessage(size.Message, check, locale.For(change.cfg.Review.Language))
+		if err := r.postSizeSkip(ctx, req, change.cfg, message); err != nil {
+			r.log.Warnf("failed to post size skip notice for %s #%d/%d: %v",
+				req.Project, req.ChangeNumber, req.PatchsetNumber, err)
+		}
+	}
+	return true
+}
+
+func (r *Reviewer) postSizeSkip(ctx context.Context, req ReviewRequest, cfg *config.Config, message string) error {
+	client := r.reviewClient(cfg)
+	review := &types.ReviewResult{
+		Summary: message,
+		Vote:    0,
+	}
+	return client.PostReview(ctx, req.ChangeNumber, req.PatchsetNumber, review)
+}
+
+// buildIgnoredFilesInstructions tells the reviewer which changed files to skip
+func buildIgnoredFilesInstructions(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n## Ignored Files\n\n")
+	b.WriteString("These changed files are generated, vendored or lock files. Do not review or comment on them:\n\n")
+	for i, file := range files {
+		if i == maxListedIgnoredFiles {
+			fmt.Fprintf(&b, "- ...and %d more\n", len(files)-i)
+			break
+		}
+		fmt.Fprintf(&b, "- `%s`\n", file)
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package reviewer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/git"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
+)
+
+func TestMeasureSize(t *testing.T) {
+	stats := []git.DiffStat{
+		{File: "main.go", Additions: 40, Deletions: 5},
+		{File: "util.go", Additions: 30},
+		{File: "vendor/lib/lib.go", Additions: 20000},
+		{File: "go.sum", Additions: 120},
+	}
+	ignore := []string{"**/vendor/**", "**/go.sum"}
+
+	tests := []struct {
+		name   string
+		limits config.SizeConfig
+		reason string
+	}{
+		{"within limits", config.SizeConfig{MaxFiles: 2, MaxInsertions: 70, Ignore: ignore}, ""},
+		{"unlimited", config.SizeConfig{Ignore: ignore}, ""},
+		{"too many files", config.SizeConfig{MaxFiles: 1, Ignore: ignore}, "2 files changed, the limit is 1"},
+		{"too many insertions", config.SizeConfig{MaxInsertions: 50, Ignore: ignore}, "70 lines inserted, the limit is 50"},
+		{"ignored files count without ignore list", config.SizeConfig{MaxInsertions: 1000}, "20190 lines inserted, the limit is 1000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check, err := measureSize(stats, tt.limits)
+			if err != nil {
+				t.Fatalf("measureSize() failed: %v", err)
+			}
+			if got := check.reason(locale.For(locale.English)); got != tt.reason {
+				t.Errorf("Expected reason %q, got %q", tt.reason, got)
+			}
+		})
+	}
+
+	check, _ := measureSize(stats, config.SizeConfig{Ignore: ignore})
+	if check.Files != 2 || check.Insertions != 70 || len(check.IgnoredFiles) != 2 {
+		t.Errorf("Expected 2 files, 70 insertions and 2 ignored files, got %+v", check)
+	}
+}
+
+func TestMeasureSizeOnlyIgnoredFiles(t *testing.T) {
+	stats := []git.DiffStat{{File: "package-lock.json", Additions: 900}}
+
+	check, err := measureSize(stats, config.SizeConfig{Ignore: []string{"**/package-lock.json"}})
+	if err != nil {
+		t.Fatalf("measureSize() failed: %v", err)
+	}
+	if check.Exceeded != onlyIgnoredFiles {
+		t.Errorf("Expected only-ignored reason, got %+v", check)
+	}
+}
+
+func TestSizeSkipMessage(t *testing.T) {
+	check := sizeCheck{Files: 120, Insertions: 4000, Exceeded: tooManyFiles, Limit: 50}
+
+	got := sizeSkipMessage("Skipped: {{reason}} ({{files}} files, {{insertions}} lines).", check, locale.For(locale.English))
+	want := "Skipped: 120 files changed, the limit is 50 (120 files, 4000 lines)."
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	got = sizeSkipMessage("", check, locale.For(locale.TraditionalChinese))
+	want = "已略過自動審查：變更了 120 個檔案，上限為 50。"
+	if got != want {
+		t.Errorf("Expected default Traditional Chinese message %q, got %q", want, got)
+	}
+}
+
+func TestBuildIgnoredFilesInstructions(t *testing.T) {
+	if got := buildIgnoredFilesInstructions(nil); got != "" {
+		t.Errorf("Expected no instructions without ignored files, got %q", got)
+	}
+
+	files := make([]string, maxListedIgnoredFiles+3)
+	for i := range files {
+		files[i] = "vendor/f.go"
+	}
+	got := buildIgnoredFilesInstructions(files)
+	if !strings.Contains(got, "Do not review") || !strings.Contains(got, "...and 3 more") {
+		t.Errorf("Expected bounded ignored file list, got %q", got)
+	}
+}
@@ -0,0 +1,182 @@
+package reviewer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	codereview "github.com/gerrit-ai-review/gerrit-tools/skills/code-review"
+)
+
+// SkillData is the change metadata available to skill templates, e.g.
+// {{.Project}} or {{range .Languages}}{{.}}{{end}}
+type SkillData struct {
+	Project        string
+	Branch         string
+	ChangeNumber   int
+	PatchsetNumber int
+	Subject        string
+	Files          []string // Changed files, without the ignored ones
+	Languages      []string // Extensions of the changed files, e.g. "go"
+	RequestedBy    string   // Account that asked for the review; empty for automatic reviews
+	Focus          []string
+	Incremental    bool
+	Language       string // Review output language, e.g. "en" or "zh-TW"
+}
+
+// skillFragment is one piece of the composed skill
+type skillFragment struct {
+	name    string
+	content string
+}
+
+// newSkillData collects the template data of a change reviewed in language
+func newSkillData(changeInfo ChangeInfo, language string) SkillData {
+	return SkillData{
+		Project:        changeInfo.Project,
+		Branch:         changeInfo.Branch,
+		ChangeNumber:   changeInfo.ChangeNumber,
+		PatchsetNumber: changeInfo.PatchsetNumber,
+		Subject:        changeInfo.Subject,
+		Files:          changeInfo.Files,
+		Languages:      fileLanguages(changeInfo.Files),
+		RequestedBy:    changeInfo.Options.RequestedBy,
+		Focus:          changeInfo.Options.Focus,
+		Incremental:    changeInfo.Options.Incremental,
+		Language:       language,
+	}
+}
+
+// composeSkill loads the configured skill fragments and renders them with
+// the change metadata. repoRoot is the checked-out patchset.
+func composeSkill(cfg config.SkillConfig, repoRoot string, data SkillData) (string, error) {
+	fragments, err := loadSkillFragments(cfg, repoRoot, data)
+	if err != nil {
+		return "", err
+	}
+
+	parts := make([]string, 0, len(fragments))
+	for _, f := range fragments {
+		tmpl, err := template.New(f.name).Option("missingkey=error").Parse(f.content)
+		if err != nil {
+			return "", fmt.Errorf("invalid skill template %s: %w", f.name, err)
+		}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", fmt.Errorf("failed to render skill %s: %w", f.name, err)
+		}
+		parts = append(parts, strings.TrimSpace(b.String()))
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// loadSkillFragments reads the base skill (the embedded one in the review
+// language by default), the fragments for the languages of the changed
+// files, the configured fragments and the repository fragments, in that
+// order.
+func loadSkillFragments(cfg config.SkillConfig, repoRoot string, data SkillData) ([]skillFragment, error) {
+	var fragments []skillFragment
+
+	if cfg.Base == "" {
+		content, err := codereview.ContentFor(data.Language)
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, skillFragment{name: "embedded skill", content: content})
+	} else {
+		f, err := readSkillFile(resolveSkillPath(cfg.Dir, cfg.Base))
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, f)
+	}
+
+	if cfg.Dir != "" {
+		for _, lang := range data.Languages {
+			f, err := readSkillFile(filepath.Join(cfg.Dir, "lang", lang+".md"))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			fragments = append(fragments, f)
+		}
+	}
+
+	for _, name := range cfg.Fragments {
+		f, err := readSkillFile(resolveSkillPath(cfg.Dir, name))
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, f)
+	}
+
+	if cfg.RepoDir != "" {
+		dir, err := config.ResolveInRepo(repoRoot, cfg.RepoDir)
+		if errors.Is(err, os.ErrNotExist) {
+			return fragments, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("skill repo_dir: %w", err)
+		}
+		paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			name := filepath.Join(cfg.RepoDir, filepath.Base(path))
+			resolved, err := config.ResolveInRepo(repoRoot, name)
+			if err != nil {
+				return nil, fmt.Errorf("skill fragment: %w", err)
+			}
+			f, err := readSkillFile(resolved)
+			if err != nil {
+				return nil, err
+			}
+			f.name = name
+			fragments = append(fragments, f)
+		}
+	}
+
+	return fragments, nil
+}
+
+func readSkillFile(path string) (skillFragment, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return skillFragment{}, fmt.Errorf("failed to read skill: %w", err)
+	}
+	return skillFragment{name: path, content: string(b)}, nil
+}
+
+// resolveSkillPath resolves a skill path relative to dir
+func resolveSkillPath(dir, path string) string {
+	if dir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// fileLanguages returns the sorted, distinct extensions of files
+func fileLanguages(files []string) []string {
+	seen := make(map[string]bool)
+	var languages []string
+	for _, file := range files {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(file), "."))
+		if ext == "" || seen[ext] {
+			continue
+		}
+		seen[ext] = true
+		languages = append(languages, ext)
+	}
+	sort.Strings(languages)
+	return languages
+}
@@ -0,0 +1,173 @@
+package reviewer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+)
+
+func writeSkillFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestComposeSkill(t *testing.T) {
+	skillDir := t.TempDir()
+	repoRoot := t.TempDir()
+
+	writeSkillFile(t, filepath.Join(skillDir, "base.md"), "Review {{.Project}} change {{.ChangeNumber}} on {{.Branch}} in {{.Language}}.")
+	writeSkillFile(t, filepath.Join(skillDir, "lang", "go.md"), "Go: check error wrapping.")
+	writeSkillFile(t, filepath.Join(skillDir, "lang", "py.md"), "Python: check typing.")
+	writeSkillFile(t, filepath.Join(skillDir, "team.md"), "Team: {{len .Files}} files.")
+	writeSkillFile(t, filepath.Join(repoRoot, ".review", "b.md"), "Repo B.")
+	writeSkillFile(t, filepath.Join(repoRoot, ".review", "a.md"), "Repo A.")
+
+	cfg := config.SkillConfig{
+		Base:      "base.md",
+		Dir:       skillDir,
+		Fragments: []string{"team.md"},
+		RepoDir:   ".review",
+	}
+	data := newSkillData(ChangeInfo{
+		Project:      "platform/app",
+		Branch:       "main",
+		ChangeNumber: 42,
+		Files:        []string{"cmd/main.go", "internal/util.go", "Makefile"},
+	}, "en")
+
+	got, err := composeSkill(cfg, repoRoot, data)
+	if err != nil {
+		t.Fatalf("composeSkill failed: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"Review platform/app change 42 on main in en.",
+		"Go: check error wrapping.",
+		"Team: 3 files.",
+		"Repo A.",
+		"Repo B.",
+	}, "\n\n")
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestComposeSkillDefaultsToEmbedded(t *testing.T) {
+	got, err := composeSkill(config.SkillConfig{}, t.TempDir(), SkillData{})
+	if err != nil {
+		t.Fatalf("composeSkill failed: %v", err)
+	}
+	if !strings.Contains(got, "gerrit-cli") {
+		t.Errorf("Expected embedded skill content, got %q", got)
+	}
+
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{"en", "**in English**"},
+		{"zh-TW", "繁體中文"},
+		{"", "**in English**"},
+	}
+	for _, tt := range tests {
+		got, err := composeSkill(config.SkillConfig{}, t.TempDir(), SkillData{Language: tt.language})
+		if err != nil {
+			t.Fatalf("composeSkill failed: %v", err)
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("Expected %q skill to contain %q", tt.language, tt.want)
+		}
+	}
+}
+
+func TestComposeSkillErrors(t *testing.T) {
+	skillDir := t.TempDir()
+	writeSkillFile(t, filepath.Join(skillDir, "broken.md"), "{{.Project")
+	writeSkillFile(t, filepath.Join(skillDir, "unknown.md"), "{{.Nope}}")
+
+	tests := []struct {
+		name string
+		cfg  config.SkillConfig
+	}{
+		{"missing base", config.SkillConfig{Base: "missing.md", Dir: skillDir}},
+		{"missing fragment", config.SkillConfig{Dir: skillDir, Fragments: []string{"missing.md"}}},
+		{"invalid template", config.SkillConfig{Base: "broken.md", Dir: skillDir}},
+		{"unknown field", config.SkillConfig{Base: "unknown.md", Dir: skillDir}},
+		{"repo dir outside repository", config.SkillConfig{RepoDir: "../elsewhere"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := composeSkill(tt.cfg, t.TempDir(), SkillData{}); err == nil {
+				t.Errorf("expected composeSkill to fail")
+			}
+		})
+	}
+}
+
+func TestComposeSkillRejectsSymlinksOutsideRepo(t *testing.T) {
+	outside := t.TempDir()
+	writeSkillFile(t, filepath.Join(outside, "secret.md"), "host secret")
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, repoRoot string)
+	}{
+		{"symlinked fragment", func(t *testing.T, repoRoot string) {
+			writeSkillFile(t, filepath.Join(repoRoot, ".review", "a.md"), "Repo A.")
+			if err := os.Symlink(filepath.Join(outside, "secret.md"), filepath.Join(repoRoot, ".review", "b.md")); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"symlinked repo dir", func(t *testing.T, repoRoot string) {
+			if err := os.Symlink(outside, filepath.Join(repoRoot, ".review")); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := t.TempDir()
+			tt.setup(t, repoRoot)
+			got, err := composeSkill(config.SkillConfig{RepoDir: ".review"}, repoRoot, SkillData{})
+			if err == nil {
+				t.Fatalf("expected composeSkill to fail, got %q", got)
+			}
+		})
+	}
+}
+
+func TestComposeSkillFollowsSymlinksInsideRepo(t *testing.T) {
+	repoRoot := t.TempDir()
+	writeSkillFile(t, filepath.Join(repoRoot, "docs", "review.md"), "Shared.")
+	if err := os.MkdirAll(filepath.Join(repoRoot, ".review"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..", "docs", "review.md"), filepath.Join(repoRoot, ".review", "a.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := composeSkill(config.SkillConfig{RepoDir: ".review"}, repoRoot, SkillData{})
+	if err != nil {
+		t.Fatalf("composeSkill failed: %v", err)
+	}
+	if !strings.Contains(got, "Shared.") {
+		t.Errorf("Expected skill to contain the linked fragment, got %q", got)
+	}
+}
+
+func TestFileLanguages(t *testing.T) {
+	got := fileLanguages([]string{"a.go", "b/c.GO", "d.ts", "Makefile", "e.go"})
+	if strings.Join(got, ",") != "go,ts" {
+		t.Errorf("Expected [go ts], got %v", got)
+	}
+}
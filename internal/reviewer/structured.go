@@ -0,0 +1,231 @@
+package reviewer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/policy"
+	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
+	codereview "github.com/gerrit-ai-review/gerrit-tools/skills/code-review"
+)
+
+// StructuredReviewSchemaVersion is the schema version the reviewer accepts.
+// See skills/code-review/review-result.schema.json.
+const StructuredReviewSchemaVersion = 1
+
+var ErrInvalidStructuredReview = errors.New("invalid structured review result")
+
+// StructuredReview is the JSON document emitted by the AI backend in
+// structured output mode.
+type StructuredReview struct {
+	SchemaVersion int                   `json:"schema_version"`
+	Summary       string                `json:"summary"`
+	Vote          int                   `json:"vote"`
+	Comments      []StructuredComment   `json:"comments"`
+	Reviewers     []StructuredReviewer  `json:"reviewers,omitempty"`
+	Attention     []StructuredAttention `json:"attention,omitempty"`
+}
+
+// StructuredComment is a single finding in a StructuredReview
+type StructuredComment struct {
+	File         string       `json:"file"`
+	Line         int          `json:"line,omitempty"`
+	Range        *types.Range `json:"range,omitempty"`
+	Severity     string       `json:"severity"`
+	Message      string       `json:"message"`
+	SuggestedFix string       `json:"suggested_fix,omitempty"`
+}
+
+// StructuredReviewer is an account or group to add to the change
+type StructuredReviewer struct {
+	Account string `json:"account"`
+	CC      bool   `json:"cc,omitempty"`
+}
+
+// StructuredAttention is an account to add to the attention set
+type StructuredAttention struct {
+	Account string `json:"account"`
+	Reason  string `json:"reason"`
+}
+
+// ParseStructuredReview extracts and validates the structured review
+// document from the backend's final output. The document may be wrapped in
+// a ```json fence or preceded by free text; the last JSON object carrying a
+// schema_version wins.
+func ParseStructuredReview(output string) (*StructuredReview, error) {
+	raw := extractStructuredJSON(output)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: no JSON document with schema_version found in output", ErrInvalidStructuredReview)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.DisallowUnknownFields()
+
+	var review StructuredReview
+	if err := dec.Decode(&review); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStructuredReview, err)
+	}
+
+	if err := review.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// Validate checks the review against the schema constraints.
+func (s *StructuredReview) Validate() error {
+	if s.SchemaVersion != StructuredReviewSchemaVersion {
+		return fmt.Errorf("%w: unsupported schema_version %d (expected %d)",
+			ErrInvalidStructuredReview, s.SchemaVersion, StructuredReviewSchemaVersion)
+	}
+
+	if strings.TrimSpace(s.Summary) == "" {
+		return fmt.Errorf("%w: summary is required", ErrInvalidStructuredReview)
+	}
+
+	if s.Vote < -1 || s.Vote > 1 {
+		return fmt.Errorf("%w: vote must be -1, 0 or 1 (got %d)", ErrInvalidStructuredReview, s.Vote)
+	}
+
+	for i, c := range s.Comments {
+		if strings.TrimSpace(c.File) == "" {
+			return fmt.Errorf("%w: comments[%d].file is required", ErrInvalidStructuredReview, i)
+		}
+		if strings.TrimSpace(c.Message) == "" {
+			return fmt.Errorf("%w: comments[%d].message is required", ErrInvalidStructuredReview, i)
+		}
+		switch c.Severity {
+		case "P0", "P1", "P2", "P3":
+		default:
+			return fmt.Errorf("%w: comments[%d].severity must be one of P0, P1, P2, P3 (got %q)", ErrInvalidStructuredReview, i, c.Severity)
+		}
+		if c.Range != nil {
+			if !c.Range.Valid() {
+				return fmt.Errorf("%w: comments[%d].range is invalid", ErrInvalidStructuredReview, i)
+			}
+		} else if c.Line < 1 {
+			return fmt.Errorf("%w: comments[%d] needs a line >= 1 or a range", ErrInvalidStructuredReview, i)
+		}
+	}
+
+	for i, r := range s.Reviewers {
+		if strings.TrimSpace(r.Account) == "" {
+			return fmt.Errorf("%w: reviewers[%d].account is required", ErrInvalidStructuredReview, i)
+		}
+	}
+
+	for i, a := range s.Attention {
+		if strings.TrimSpace(a.Account) == "" {
+			return fmt.Errorf("%w: attention[%d].account is required", ErrInvalidStructuredReview, i)
+		}
+		if strings.TrimSpace(a.Reason) == "" {
+			return fmt.Errorf("%w: attention[%d].reason is required", ErrInvalidStructuredReview, i)
+		}
+	}
+
+	return nil
+}
+
+// ToReviewResult converts the structured review into the ReviewResult posted
+// through gerrit.Client.PostReview. Severity becomes the "[Px]" message prefix
+// used throughout the review workflow; P2/P3 findings are posted resolved.
+func (s *StructuredReview) ToReviewResult() *types.ReviewResult {
+	return s.toReviewResult(nil)
+}
+
+// ToRobotReviewResult converts the structured review like ToReviewResult but
+// posts the findings as robot comments of robot, with each suggested fix
+// attached as a fix suggestion instead of quoted in the message.
+func (s *StructuredReview) ToRobotReviewResult(robot types.Robot) *types.ReviewResult {
+	return s.toReviewResult(&robot)
+}
+
+func (s *StructuredReview) toReviewResult(robot *types.Robot) *types.ReviewResult {
+	result := &types.ReviewResult{
+		Summary: s.Summary,
+		Vote:    s.Vote,
+		Robot:   robot,
+	}
+
+	for _, c := range s.Comments {
+		var msg strings.Builder
+		msg.WriteString(fmt.Sprintf("[%s] %s", c.Severity, strings.TrimSpace(c.Message)))
+
+		var fix string
+		if strings.TrimSpace(c.SuggestedFix) != "" {
+			if robot != nil {
+				fix = c.SuggestedFix
+			} else {
+				msg.WriteString("\n\nSuggested fix:\n```\n")
+				msg.WriteString(strings.TrimRight(c.SuggestedFix, "\n"))
+				msg.WriteString("\n```")
+			}
+		}
+
+		unresolved := policy.Severity(c.Severity).Blocking()
+		result.Comments = append(result.Comments, types.Comment{
+			File:       c.File,
+			Line:       c.Line,
+			Range:      c.Range,
+			Message:    msg.String(),
+			Unresolved: &unresolved,
+			Fix:        fix,
+		})
+	}
+
+	for _, r := range s.Reviewers {
+		result.Reviewers = append(result.Reviewers, types.Reviewer{Account: strings.TrimSpace(r.Account), CC: r.CC})
+	}
+	for _, a := range s.Attention {
+		result.Attention = append(result.Attention, types.Attention{Account: strings.TrimSpace(a.Account), Reason: strings.TrimSpace(a.Reason)})
+	}
+
+	return result
+}
+
+// extractStructuredJSON returns the last top-level JSON object in text that
+// contains a schema_version key.
+func extractStructuredJSON(text string) string {
+	for end := len(text); end > 0; {
+		start := strings.LastIndex(text[:end], "{")
+		if start < 0 {
+			return ""
+		}
+
+		dec := json.NewDecoder(strings.NewReader(text[start:]))
+		var probe map[string]json.RawMessage
+		if err := dec.Decode(&probe); err == nil {
+			if _, ok := probe["schema_version"]; ok {
+				return text[start : start+int(dec.InputOffset())]
+			}
+		}
+		end = start
+	}
+	return ""
+}
+
+// buildStructuredOutputInstructions returns the prompt section that replaces
+// the "post via gerrit-cli" steps when the reviewer posts the result itself.
+func buildStructuredOutputInstructions() (string, error) {
+	schema, err := codereview.ResultSchema()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`## Output Contract (structured mode)
+
+The reviewer posts your review to Gerrit itself. Therefore:
+
+- Do NOT run `+"`gerrit-cli draft create`"+` or `+"`gerrit-cli review post`"+`.
+- Use `+"`gerrit-cli`"+` only to read change data.
+- Your FINAL message must be a single JSON document matching this schema
+  (schema_version %d), optionally inside a `+"```json"+` fence, and nothing else:
+
+`+"```json"+`
+%s
+`+"```"+`
+`, StructuredReviewSchemaVersion, strings.TrimSpace(schema)), nil
+}
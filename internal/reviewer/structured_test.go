@@ -0,0 +1,182 @@
+package reviewer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/config"
+	"github.com/gerrit-ai-review/gerrit-tools/pkg/types"
+)
+
+func TestParseStructuredReview_FencedWithPreamble(t *testing.T) {
+	output := `I reviewed the change. Final result:
+
+` + "```json" + `
+{
+  "schema_version": 1,
+  "summary": "Review of PS2. Found 1 high issue.",
+  "vote": -1,
+  "comments": [
+    {"file": "main.go", "line": 42, "severity": "P1", "message": "Missing error check", "suggested_fix": "if err != nil {\n\treturn err\n}"},
+    {"file": "util.go", "range": {"start_line": 3, "start_character": 0, "end_line": 5, "end_character": 10}, "severity": "P3", "message": "Nice helper"}
+  ]
+}
+` + "```"
+
+	review, err := ParseStructuredReview(output)
+	if err != nil {
+		t.Fatalf("ParseStructuredReview() failed: %v", err)
+	}
+	if review.Vote != -1 || len(review.Comments) != 2 {
+		t.Fatalf("unexpected review: %+v", review)
+	}
+
+	result := review.ToReviewResult()
+	if result.Vote != -1 {
+		t.Fatalf("expected vote -1, got %d", result.Vote)
+	}
+
+	first := result.Comments[0]
+	if !strings.HasPrefix(first.Message, "[P1] Missing error check") {
+		t.Fatalf("expected severity prefix, got %q", first.Message)
+	}
+	if !strings.Contains(first.Message, "Suggested fix:") {
+		t.Fatalf("expected suggested fix in message, got %q", first.Message)
+	}
+	if first.Unresolved == nil || !*first.Unresolved {
+		t.Fatalf("expected P1 comment to be unresolved")
+	}
+
+	second := result.Comments[1]
+	if second.Range == nil || second.Range.EndLine != 5 {
+		t.Fatalf("expected range to be carried over, got %+v", second.Range)
+	}
+	if second.Unresolved == nil || *second.Unresolved {
+		t.Fatalf("expected P3 comment to be resolved")
+	}
+}
+
+func TestStructuredReview_ToRobotReviewResult(t *testing.T) {
+	review, err := ParseStructuredReview(`{"schema_version": 1, "summary": "s", "vote": -1, "comments": [
+		{"file": "main.go", "line": 42, "severity": "P1", "message": "Missing error check", "suggested_fix": "if err != nil {\n\treturn err\n}"},
+		{"file": "util.go", "line": 3, "severity": "P3", "message": "Nice helper"}]}`)
+	if err != nil {
+		t.Fatalf("ParseStructuredReview() failed: %v", err)
+	}
+
+	result := review.ToRobotReviewResult(types.Robot{ID: "gerrit-ai-review", RunID: "run-1"})
+	if result.Robot == nil || result.Robot.ID != "gerrit-ai-review" || result.Robot.RunID != "run-1" {
+		t.Fatalf("expected robot to be set, got %+v", result.Robot)
+	}
+
+	first := result.Comments[0]
+	if first.Message != "[P1] Missing error check" {
+		t.Errorf("expected the fix to be left out of the message, got %q", first.Message)
+	}
+	if first.Fix != "if err != nil {\n\treturn err\n}" {
+		t.Errorf("expected the suggested fix as Fix, got %q", first.Fix)
+	}
+	if result.Comments[1].Fix != "" {
+		t.Errorf("expected no fix, got %q", result.Comments[1].Fix)
+	}
+}
+
+func TestStructuredReview_ReviewersAndAttention(t *testing.T) {
+	review, err := ParseStructuredReview(`{"schema_version": 1, "summary": "s", "vote": 0, "comments": [],
+		"reviewers": [{"account": "alice@example.com"}, {"account": "security-team", "cc": true}],
+		"attention": [{"account": "bob", "reason": "Please check the locking"}]}`)
+	if err != nil {
+		t.Fatalf("ParseStructuredReview() failed: %v", err)
+	}
+
+	result := review.ToReviewResult()
+	wantReviewers := []types.Reviewer{{Account: "alice@example.com"}, {Account: "security-team", CC: true}}
+	if !reflect.DeepEqual(result.Reviewers, wantReviewers) {
+		t.Errorf("Expected reviewers %+v, got %+v", wantReviewers, result.Reviewers)
+	}
+	wantAttention := []types.Attention{{Account: "bob", Reason: "Please check the locking"}}
+	if !reflect.DeepEqual(result.Attention, wantAttention) {
+		t.Errorf("Expected attention %+v, got %+v", wantAttention, result.Attention)
+	}
+}
+
+func TestParseStructuredReview_Rejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"no json", "LGTM"},
+		{"wrong version", `{"schema_version": 2, "summary": "x", "vote": 0, "comments": []}`},
+		{"vote out of range", `{"schema_version": 1, "summary": "x", "vote": 2, "comments": []}`},
+		{"empty summary", `{"schema_version": 1, "summary": " ", "vote": 0, "comments": []}`},
+		{"bad severity", `{"schema_version": 1, "summary": "x", "vote": 0, "comments": [{"file": "a.go", "line": 1, "severity": "P9", "message": "m"}]}`},
+		{"missing line", `{"schema_version": 1, "summary": "x", "vote": 0, "comments": [{"file": "a.go", "severity": "P2", "message": "m"}]}`},
+		{"unknown field", `{"schema_version": 1, "summary": "x", "vote": 0, "comments": [], "labels": {}}`},
+		{"reviewer without account", `{"schema_version": 1, "summary": "x", "vote": 0, "comments": [], "reviewers": [{"account": " "}]}`},
+		{"attention without reason", `{"schema_version": 1, "summary": "x", "vote": 0, "comments": [], "attention": [{"account": "alice"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStructuredReview(tt.output)
+			if !errors.Is(err, ErrInvalidStructuredReview) {
+				t.Fatalf("expected ErrInvalidStructuredReview, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildPrompt_StructuredModeAddsContract(t *testing.T) {
+	info := ChangeInfo{Project: "proj", ChangeNumber: 1, PatchsetNumber: 1}
+
+	agent := NewReviewExecutor(".", &config.Config{})
+	prompt, err := agent.BuildPrompt(info)
+	if err != nil {
+		t.Fatalf("BuildPrompt() failed: %v", err)
+	}
+	if strings.Contains(prompt, "Output Contract") {
+		t.Fatalf("did not expect structured contract in agent mode")
+	}
+
+	structured := NewReviewExecutor(".", &config.Config{Review: config.ReviewConfig{OutputMode: "structured"}})
+	prompt, err = structured.BuildPrompt(info)
+	if err != nil {
+		t.Fatalf("BuildPrompt() failed: %v", err)
+	}
+	if !strings.Contains(prompt, "Output Contract") || !strings.Contains(prompt, `"schema_version"`) {
+		t.Fatalf("expected structured contract with schema in prompt")
+	}
+}
+
+func TestBuildPrompt_ReviewOptions(t *testing.T) {
+	executor := NewReviewExecutor(".", &config.Config{})
+
+	prompt, err := executor.BuildPrompt(ChangeInfo{Project: "proj", ChangeNumber: 7, PatchsetNumber: 3})
+	if err != nil {
+		t.Fatalf("BuildPrompt() failed: %v", err)
+	}
+	if strings.Contains(prompt, "## Review Request") {
+		t.Fatalf("did not expect review request section without options")
+	}
+
+	prompt, err = executor.BuildPrompt(ChangeInfo{
+		Project:        "proj",
+		ChangeNumber:   7,
+		PatchsetNumber: 3,
+		Options: ReviewOptions{
+			RequestedBy: "alice",
+			Focus:       []string{"security", "performance"},
+			Incremental: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildPrompt() failed: %v", err)
+	}
+	for _, want := range []string{"**alice**", "Focus on: **security, performance**", "gerrit-cli patchset diff 7 3 --base 2"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected prompt to contain %q", want)
+		}
+	}
+}
@@ -10,21 +10,29 @@ import (
 	"github.com/gerrit-ai-review/gerrit-tools/internal/reviewer"
 )
 
+// PoolConfig configures failure handling of the worker pool.
+type PoolConfig struct {
+	Retry       map[string]RetryPolicy // Retry policy per error class; missing classes are not retried
+	DeadLetters *queue.DeadLetterStore // Receives tasks that failed every attempt; nil drops them
+}
+
 // Pool manages a pool of workers that process review tasks
 type Pool struct {
 	workers  int
 	queue    *queue.Queue
 	reviewer *reviewer.Reviewer
+	cfg      PoolConfig
 	wg       sync.WaitGroup
 	log      *logger.Logger
 }
 
 // NewPool creates a new worker pool
-func NewPool(workers int, q *queue.Queue, rev *reviewer.Reviewer) *Pool {
+func NewPool(workers int, q *queue.Queue, rev *reviewer.Reviewer, cfg PoolConfig) *Pool {
 	return &Pool{
 		workers:  workers,
 		queue:    q,
 		reviewer: rev,
+		cfg:      cfg,
 		log:      logger.Get(),
 	}
 }
@@ -60,12 +68,33 @@ func (p *Pool) worker(ctx context.Context, id int) {
 
 		req := reviewer.ReviewRequest{
 			Project:        task.Project,
+			Branch:         task.Branch,
 			ChangeNumber:   task.ChangeNumber,
 			PatchsetNumber: task.PatchsetNumber,
+			WillRetry:      task.Attempt+1 < p.cfg.Retry[ErrorClassRateLimited].MaxAttempts,
+			Options: reviewer.ReviewOptions{
+				RequestedBy: task.RequestedBy,
+				Focus:       task.Focus,
+				Incremental: task.Incremental,
+			},
 		}
 
-		if err := p.reviewer.ReviewChange(ctx, req); err != nil {
-			p.log.Errorf("Worker %d failed: %v", id, err)
+		taskCtx := p.queue.Start(ctx, task)
+
+		if err := p.reviewer.ReviewChange(taskCtx, req); err != nil {
+			if ctx.Err() != nil {
+				// Interrupted by shutdown: leave the task pending so a
+				// durable queue re-enqueues it on the next start.
+				p.log.Warnf("Worker %d interrupted: %s #%d/%d",
+					id, task.Project, task.ChangeNumber, task.PatchsetNumber)
+				return
+			}
+			if taskCtx.Err() != nil {
+				p.log.Infof("Worker %d skipped %s #%d/%d: %v",
+					id, task.Project, task.ChangeNumber, task.PatchsetNumber, context.Cause(taskCtx))
+			} else if p.retry(id, task, err) {
+				continue
+			}
 		} else {
 			duration := time.Since(start)
 			p.log.Infof("Worker %d completed: %s #%d/%d (%.1fs)",
@@ -77,6 +106,42 @@ func (p *Pool) worker(ctx context.Context, id int) {
 	}
 }
 
+// retry schedules another attempt of a failed task according to the retry
+// policy of its error class. It returns false when the task is out of
+// attempts; the task is then dead-lettered and must be marked done.
+func (p *Pool) retry(id int, task queue.Task, err error) bool {
+	class := ClassifyError(err)
+	policy := p.cfg.Retry[class]
+
+	task.Attempt++
+	task.Errors = append(task.Errors, err.Error())
+
+	if task.Attempt < policy.MaxAttempts {
+		delay := policy.Backoff(task.Attempt)
+		p.log.Warnf("Worker %d failed (%s, attempt %d/%d), retrying in %v: %v",
+			id, class, task.Attempt, policy.MaxAttempts, delay.Round(time.Second), err)
+
+		retryErr := p.queue.Retry(task, delay)
+		if retryErr == nil {
+			return true
+		}
+		p.log.Errorf("Failed to schedule retry of %s: %v", task.ID, retryErr)
+	}
+
+	p.log.Errorf("Worker %d failed (%s, attempt %d): %v", id, class, task.Attempt, err)
+
+	if p.cfg.DeadLetters != nil {
+		entry := queue.DeadLetter{Task: task, ErrorClass: class, FailedAt: time.Now()}
+		if dlErr := p.cfg.DeadLetters.Add(entry); dlErr != nil {
+			p.log.Errorf("Failed to dead-letter %s: %v", task.ID, dlErr)
+		} else {
+			p.log.Warnf("Dead-lettered %s after %d attempt(s)", task.ID, task.Attempt)
+		}
+	}
+
+	return false
+}
+
 // Stop stops the worker pool gracefully
 func (p *Pool) Stop(ctx context.Context) error {
 	p.log.Info("Stopping worker pool...")
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/backoff"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/reviewer"
+)
+
+// Error classes used to pick a retry policy
+const (
+	ErrorClassRateLimited = "rate_limited"
+	ErrorClassTimeout     = "timeout"
+	ErrorClassGit         = "git"
+	ErrorClassOther       = "other"
+)
+
+// ErrorClasses lists every error class in a stable order
+var ErrorClasses = []string{ErrorClassRateLimited, ErrorClassTimeout, ErrorClassGit, ErrorClassOther}
+
+// RetryPolicy controls how often a task failing with one error class is retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first; <= 1 disables retries
+	BaseDelay   time.Duration // Delay before the first retry
+	MaxDelay    time.Duration // Upper bound for the exponential delay
+}
+
+// ClassifyError maps a ReviewChange error to an error class
+func ClassifyError(err error) string {
+	switch {
+	case errors.Is(err, reviewer.ErrRateLimited), errors.Is(err, gerrit.ErrRateLimited):
+		return ErrorClassRateLimited
+	case errors.Is(err, reviewer.ErrReviewTimeout):
+		return ErrorClassTimeout
+	case errors.Is(err, reviewer.ErrGit):
+		return ErrorClassGit
+	default:
+		return ErrorClassOther
+	}
+}
+
+// Backoff returns the delay before retrying after the given number of failed
+// attempts: BaseDelay doubled per attempt, capped at MaxDelay, with the upper
+// half randomized so that tasks failing together do not retry together.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	return backoff.Exponential(attempt, p.BaseDelay, p.MaxDelay)
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/gerrit"
+	"github.com/gerrit-ai-review/gerrit-tools/internal/reviewer"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("claude execution failed: %w", reviewer.ErrRateLimited), ErrorClassRateLimited},
+		{fmt.Errorf("failed to post structured review: %w", gerrit.ErrRateLimited), ErrorClassRateLimited},
+		{fmt.Errorf("codex execution failed: %w", reviewer.ErrReviewTimeout), ErrorClassTimeout},
+		{fmt.Errorf("%w: failed to clone/update: exit status 128", reviewer.ErrGit), ErrorClassGit},
+		{errors.New("failed to parse structured review"), ErrorClassOther},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 10 * time.Second, MaxDelay: 30 * time.Second}
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 30 * time.Second},
+		{10, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			delay := policy.Backoff(tt.attempt)
+			if delay < tt.max/2 || delay > tt.max {
+				t.Fatalf("Backoff(%d) = %v, expected within [%v, %v]", tt.attempt, delay, tt.max/2, tt.max)
+			}
+		}
+	}
+
+	if delay := (RetryPolicy{}).Backoff(3); delay != 0 {
+		t.Errorf("Expected zero delay without BaseDelay, got %v", delay)
+	}
+}
@@ -2,21 +2,70 @@ package types
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
 // ReviewResult represents the parsed output from Claude's code review
 type ReviewResult struct {
-	Summary  string    // Overall summary of the review
-	Vote     int       // Code-Review vote: -1, 0, or 1
-	Comments []Comment // Inline comments for specific files/lines
+	Summary  string         // Overall summary of the review
+	Vote     int            // Vote on the reviewer's label (Code-Review by default): -1, 0, or 1
+	Labels   map[string]int // Votes on other labels, e.g. "Verified"
+	Comments []Comment      // Inline comments for specific files/lines
+	Robot    *Robot         // When set, Comments are posted as robot comments
+
+	Reviewers []Reviewer  // Accounts added as reviewers or CCs with the review
+	Attention []Attention // Accounts added to the attention set with the review
+}
+
+// Reviewer is an account, identified by account ID, email or username, or a
+// group added to a change with a review
+type Reviewer struct {
+	Account string
+	CC      bool // Add as CC instead of reviewer
+}
+
+// Attention asks an account, identified by account ID, email or username,
+// to act on a change
+type Attention struct {
+	Account string
+	Reason  string // Shown in Gerrit next to the account
+}
+
+// Robot identifies the automated reviewer robot comments are attributed to
+type Robot struct {
+	ID    string // robot_id, the same for every run of the reviewer
+	RunID string // robot_run_id, unique per review run
 }
 
 // Comment represents a single inline comment on a specific file and line
 type Comment struct {
-	File    string // File path relative to repo root
-	Line    int    // Line number (1-indexed)
-	Message string // Comment text
+	File       string // File path relative to repo root
+	Line       int    // Line number (1-indexed); 0 without Range comments on the whole file
+	Range      *Range // Optional character range; takes precedence over Line
+	Side       string // SideParent for the base of the revision (e.g. deleted lines); empty = the revision
+	Message    string // Comment text
+	Unresolved *bool  // nil means unresolved (default for AI comments)
+	Fix        string // Optional replacement for Range (or the whole Line), posted as a fix suggestion on robot comments
+}
+
+// SideParent places a comment on the parent of the revision, where deleted
+// lines are shown
+const SideParent = "PARENT"
+
+// Range represents a character range within a file
+type Range struct {
+	StartLine      int `json:"start_line"`
+	StartCharacter int `json:"start_character"`
+	EndLine        int `json:"end_line"`
+	EndCharacter   int `json:"end_character"`
+}
+
+// Valid reports whether r starts at line 1 or later and does not end before
+// it starts
+func (r *Range) Valid() bool {
+	return r.StartLine >= 1 && r.EndLine >= r.StartLine && r.StartCharacter >= 0 && r.EndCharacter >= 0 &&
+		(r.StartLine != r.EndLine || r.EndCharacter >= r.StartCharacter)
 }
 
 // String returns a human-readable representation of the review result
@@ -24,6 +73,14 @@ func (r *ReviewResult) String() string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("Vote: %+d\n", r.Vote))
+	labels := make([]string, 0, len(r.Labels))
+	for name := range r.Labels {
+		labels = append(labels, name)
+	}
+	sort.Strings(labels)
+	for _, name := range labels {
+		sb.WriteString(fmt.Sprintf("%s: %+d\n", name, r.Labels[name]))
+	}
 	sb.WriteString(fmt.Sprintf("Summary: %s\n", r.Summary))
 
 	if len(r.Comments) > 0 {
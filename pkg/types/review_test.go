@@ -98,3 +98,23 @@ func TestComment(t *testing.T) {
 		t.Errorf("Expected Message 'This is a test comment', got '%s'", comment.Message)
 	}
 }
+
+func TestRange_Valid(t *testing.T) {
+	tests := []struct {
+		r        Range
+		expected bool
+	}{
+		{Range{StartLine: 3, StartCharacter: 0, EndLine: 5, EndCharacter: 10}, true},
+		{Range{StartLine: 3, StartCharacter: 4, EndLine: 3, EndCharacter: 4}, true},
+		{Range{StartLine: 0, EndLine: 1}, false},
+		{Range{StartLine: 5, EndLine: 3}, false},
+		{Range{StartLine: 3, StartCharacter: 8, EndLine: 3, EndCharacter: 2}, false},
+		{Range{StartLine: 3, StartCharacter: -1, EndLine: 4}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.r.Valid(); got != tt.expected {
+			t.Errorf("%+v.Valid() = %v, want %v", tt.r, got, tt.expected)
+		}
+	}
+}
@@ -3,18 +3,49 @@ package codereview
 import (
 	"embed"
 	"fmt"
+
+	"github.com/gerrit-ai-review/gerrit-tools/internal/locale"
+)
+
+const (
+	defaultSkillFile = "SKILL.md"
+	resultSchemaFile = "review-result.schema.json"
 )
 
-const defaultSkillFile = "SKILL.md"
+// skillFiles maps each review language to its variant of the skill;
+// SKILL.md is the Traditional Chinese original.
+var skillFiles = map[string]string{
+	locale.TraditionalChinese: defaultSkillFile,
+	locale.English:            "SKILL.en.md",
+}
 
-//go:embed SKILL.md
+//go:embed SKILL.md SKILL.en.md review-result.schema.json
 var files embed.FS
 
 // Content returns embedded default code review skill content.
 func Content() (string, error) {
-	b, err := files.ReadFile(defaultSkillFile)
+	return ContentFor(locale.Default)
+}
+
+// ContentFor returns the embedded code review skill written for language,
+// falling back to English for a language without a variant.
+func ContentFor(language string) (string, error) {
+	name := skillFiles[locale.English]
+	if lang, ok := locale.Normalize(language); ok && skillFiles[lang] != "" {
+		name = skillFiles[lang]
+	}
+	b, err := files.ReadFile(name)
 	if err != nil {
 		return "", fmt.Errorf("failed to read embedded skill content: %w", err)
 	}
 	return string(b), nil
 }
+
+// ResultSchema returns the JSON schema of the structured review result.
+func ResultSchema() (string, error) {
+	b, err := files.ReadFile(resultSchemaFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read embedded result schema: %w", err)
+	}
+	return string(b), nil
+}